@@ -15,29 +15,9 @@
 package main
 
 import (
-	"log"
-
-	"github.com/spf13/cobra"
-	_ "k8s.io/client-go/plugin/pkg/client/auth"
-
-	"github.com/operator-framework/operator-sdk/internal/cmd/ansible-operator/run"
-	"github.com/operator-framework/operator-sdk/internal/cmd/ansible-operator/version"
+	"github.com/operator-framework/operator-sdk/pkg/ansibleoperator"
 )
 
 func main() {
-	root := cobra.Command{
-		Short: "Reconcile an Ansible operator project using ansible-runner",
-		Long: `This binary runs an Ansible operator that reconciles Kubernetes resources
-managed by the ansible-runner program. It can be run either directly or from an Ansible
-operator project's image entrypoint
-`,
-		Use: "ansible-operator",
-	}
-
-	root.AddCommand(run.NewCmd())
-	root.AddCommand(version.NewCmd())
-
-	if err := root.Execute(); err != nil {
-		log.Fatal(err)
-	}
+	ansibleoperator.Main()
 }
@@ -15,13 +15,16 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
 	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 	"github.com/operator-framework/operator-sdk/internal/scorecard"
@@ -54,22 +57,11 @@ func main() {
 		log.Fatal(err.Error())
 	}
 
-	var result scapiv1alpha3.TestStatus
-
-	switch entrypoint[0] {
-	case tests.OLMBundleValidationTest:
-		result = tests.BundleValidationTest(scorecard.PodBundleRoot, metadata)
-	case tests.OLMCRDsHaveValidationTest:
-		result = tests.CRDsHaveValidationTest(bundle)
-	case tests.OLMCRDsHaveResourcesTest:
-		result = tests.CRDsHaveResourcesTest(bundle)
-	case tests.OLMSpecDescriptorsTest:
-		result = tests.SpecDescriptorsTest(bundle)
-	case tests.OLMStatusDescriptorsTest:
-		result = tests.StatusDescriptorsTest(bundle)
-	case tests.BasicCheckSpecTest:
-		result = tests.CheckSpecTest(bundle)
-	default:
+	result, ok := tests.Run(entrypoint[0], scorecard.PodBundleRoot, bundle, metadata)
+	if !ok && tests.IsFunctional(entrypoint[0]) {
+		result, ok = runFunctional(entrypoint[0], bundle)
+	}
+	if !ok {
 		result = printValidTests()
 	}
 
@@ -81,6 +73,20 @@ func main() {
 
 }
 
+// runFunctional runs a functional test against the cluster the pod runs in,
+// creating resources in the scorecard namespace.
+func runFunctional(name string, bundle *apimanifests.Bundle) (scapiv1alpha3.TestStatus, bool) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	cluster, err := tests.NewCluster(cfg, os.Getenv("SCORECARD_NAMESPACE"))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	return tests.RunFunctional(context.Background(), name, cluster, bundle)
+}
+
 // printValidTests will print out full list of test names to give a hint to the end user on what the valid tests are
 func printValidTests() scapiv1alpha3.TestStatus {
 	result := scapiv1alpha3.TestResult{}
@@ -88,13 +94,7 @@ func printValidTests() scapiv1alpha3.TestStatus {
 	result.Errors = make([]string, 0)
 	result.Suggestions = make([]string, 0)
 
-	str := fmt.Sprintf("Valid tests for this image include: %s, %s, %s, %s, %s, %s",
-		tests.OLMBundleValidationTest,
-		tests.OLMCRDsHaveValidationTest,
-		tests.OLMCRDsHaveResourcesTest,
-		tests.OLMSpecDescriptorsTest,
-		tests.OLMStatusDescriptorsTest,
-		tests.BasicCheckSpecTest)
+	str := fmt.Sprintf("Valid tests for this image include: %s", strings.Join(tests.Names(), ", "))
 	result.Errors = append(result.Errors, str)
 	return scapiv1alpha3.TestStatus{
 		Results: []scapiv1alpha3.TestResult{result},
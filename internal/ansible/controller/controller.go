@@ -52,6 +52,8 @@ type Options struct {
 	WatchClusterScopedResources bool
 	MaxConcurrentReconciles     int
 	Selector                    metav1.LabelSelector
+	PreRunHooks                 []PreRunHook
+	PostRunHooks                []PostRunHook
 }
 
 // Add - Creates a new ansible operator controller and adds it to the manager
@@ -72,6 +74,8 @@ func Add(mgr manager.Manager, options Options) *controller.Controller {
 		ManageStatus:     options.ManageStatus,
 		AnsibleDebugLogs: options.AnsibleDebugLogs,
 		APIReader:        mgr.GetAPIReader(),
+		PreRunHooks:      append(registeredPreRunHooks(options.GVK), options.PreRunHooks...),
+		PostRunHooks:     append(registeredPostRunHooks(options.GVK), options.PostRunHooks...),
 	}
 
 	scheme := mgr.GetScheme()
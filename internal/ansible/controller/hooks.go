@@ -0,0 +1,164 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-sdk/internal/ansible/runner/eventapi"
+)
+
+// PreRunResult - returned by a PreRunHook to change how ansible-runner is invoked.
+type PreRunResult struct {
+	// ExtraVars are added to the extra vars passed to the playbook or role. They
+	// take precedence over the CR spec and the watch vars.
+	ExtraVars map[string]interface{}
+	// Skip prevents ansible-runner from running in this reconcile. It is ignored
+	// for a CR being deleted, so that its finalizer always runs.
+	Skip bool
+	// RequeueAfter, if set, overrides the reconcile period when the run is skipped.
+	RequeueAfter time.Duration
+}
+
+// PreRunHook - Go code run before ansible-runner for a CR. Returning an error
+// marks the CR as failed and requeues it.
+type PreRunHook interface {
+	PreRun(ctx context.Context, c client.Client, u *unstructured.Unstructured) (PreRunResult, error)
+}
+
+// RunResult - the outcome of a completed ansible-runner run, passed to a PostRunHook.
+type RunResult struct {
+	// StatusEvent holds the final playbook stats.
+	StatusEvent eventapi.StatusJobEvent
+	// FailureMessages holds the messages of the failed tasks. It is empty if the
+	// run was successful.
+	FailureMessages eventapi.FailureMessages
+}
+
+// Successful - returns true if no task of the run failed.
+func (r RunResult) Successful() bool {
+	return len(r.FailureMessages) == 0
+}
+
+// PostRunHook - Go code run after every completed ansible-runner run for a CR,
+// successful or not, with the result of the run and the CR as read from the API
+// server after the run. Hooks that only apply to successful runs must check
+// result.Successful(). Returning an error requeues the CR, except for a CR being
+// deleted, whose finalizer is removed regardless after a successful run.
+type PostRunHook interface {
+	PostRun(ctx context.Context, c client.Client, u *unstructured.Unstructured, result RunResult) error
+}
+
+// PreRunHookFunc - adapts a function to a PreRunHook.
+type PreRunHookFunc func(ctx context.Context, c client.Client, u *unstructured.Unstructured) (PreRunResult, error)
+
+// PreRun - calls f.
+func (f PreRunHookFunc) PreRun(ctx context.Context, c client.Client, u *unstructured.Unstructured) (PreRunResult,
+	error) {
+	return f(ctx, c, u)
+}
+
+// PostRunHookFunc - adapts a function to a PostRunHook.
+type PostRunHookFunc func(ctx context.Context, c client.Client, u *unstructured.Unstructured, result RunResult) error
+
+// PostRun - calls f.
+func (f PostRunHookFunc) PostRun(ctx context.Context, c client.Client, u *unstructured.Unstructured,
+	result RunResult) error {
+	return f(ctx, c, u, result)
+}
+
+// hookRegistry - hooks registered by a custom ansible-operator binary before the
+// controllers are created. The empty GVK holds hooks run for every GVK.
+var hookRegistry = struct {
+	mu      sync.RWMutex
+	preRun  map[schema.GroupVersionKind][]PreRunHook
+	postRun map[schema.GroupVersionKind][]PostRunHook
+}{
+	preRun:  map[schema.GroupVersionKind][]PreRunHook{},
+	postRun: map[schema.GroupVersionKind][]PostRunHook{},
+}
+
+// RegisterPreRunHook - registers a hook run before ansible-runner for CRs of gvk,
+// or of every GVK if gvk is empty. Hooks run in the order they are registered.
+func RegisterPreRunHook(gvk schema.GroupVersionKind, hook PreRunHook) {
+	hookRegistry.mu.Lock()
+	defer hookRegistry.mu.Unlock()
+	hookRegistry.preRun[gvk] = append(hookRegistry.preRun[gvk], hook)
+}
+
+// RegisterPostRunHook - registers a hook run after ansible-runner for CRs of gvk,
+// or of every GVK if gvk is empty. Hooks run in the order they are registered.
+func RegisterPostRunHook(gvk schema.GroupVersionKind, hook PostRunHook) {
+	hookRegistry.mu.Lock()
+	defer hookRegistry.mu.Unlock()
+	hookRegistry.postRun[gvk] = append(hookRegistry.postRun[gvk], hook)
+}
+
+func registeredPreRunHooks(gvk schema.GroupVersionKind) []PreRunHook {
+	hookRegistry.mu.RLock()
+	defer hookRegistry.mu.RUnlock()
+	hooks := append([]PreRunHook{}, hookRegistry.preRun[schema.GroupVersionKind{}]...)
+	return append(hooks, hookRegistry.preRun[gvk]...)
+}
+
+func registeredPostRunHooks(gvk schema.GroupVersionKind) []PostRunHook {
+	hookRegistry.mu.RLock()
+	defer hookRegistry.mu.RUnlock()
+	hooks := append([]PostRunHook{}, hookRegistry.postRun[schema.GroupVersionKind{}]...)
+	return append(hooks, hookRegistry.postRun[gvk]...)
+}
+
+// runPreRunHooks - runs the pre-run hooks of the reconciler in order, merging
+// their extra vars. The run is skipped if any hook asks for it.
+func (r *AnsibleOperatorReconciler) runPreRunHooks(ctx context.Context, u *unstructured.Unstructured) (PreRunResult,
+	error) {
+	result := PreRunResult{}
+	for _, hook := range r.PreRunHooks {
+		hr, err := hook.PreRun(ctx, r.Client, u)
+		if err != nil {
+			return result, err
+		}
+		for k, v := range hr.ExtraVars {
+			if result.ExtraVars == nil {
+				result.ExtraVars = map[string]interface{}{}
+			}
+			result.ExtraVars[k] = v
+		}
+		if hr.Skip {
+			result.Skip = true
+			result.RequeueAfter = hr.RequeueAfter
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// runPostRunHooks - runs the post-run hooks of the reconciler in order, stopping
+// at the first error.
+func (r *AnsibleOperatorReconciler) runPostRunHooks(ctx context.Context, u *unstructured.Unstructured,
+	result RunResult) error {
+	for _, hook := range r.PostRunHooks {
+		if err := hook.PostRun(ctx, r.Client, u, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
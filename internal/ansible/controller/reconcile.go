@@ -61,6 +61,8 @@ type AnsibleOperatorReconciler struct {
 	ReconcilePeriod  time.Duration
 	ManageStatus     bool
 	AnsibleDebugLogs bool
+	PreRunHooks      []PreRunHook
+	PostRunHooks     []PostRunHook
 }
 
 // Reconcile - handle the event.
@@ -128,6 +130,28 @@ func (r *AnsibleOperatorReconciler) Reconcile(ctx context.Context, request recon
 		u.Object["spec"] = map[string]interface{}{}
 	}
 
+	preRun, err := r.runPreRunHooks(ctx, u)
+	if err != nil {
+		if r.ManageStatus {
+			errmark := r.markError(ctx, request.NamespacedName, u, fmt.Sprintf("Pre-run hook failed: %v", err))
+			if errmark != nil {
+				logger.Error(errmark, "Unable to mark error to run reconciliation")
+			}
+		}
+		logger.Error(err, "Pre-run hook failed")
+		return reconcileResult, err
+	}
+	// The finalizer must run for a deleted resource, so it cannot be skipped.
+	if preRun.Skip && deleted {
+		logger.Info("Ignoring pre-run hook skip, resource is being deleted")
+	} else if preRun.Skip {
+		logger.Info("Skipping ansible run as requested by pre-run hook")
+		if preRun.RequeueAfter > 0 {
+			reconcileResult.RequeueAfter = preRun.RequeueAfter
+		}
+		return reconcileResult, nil
+	}
+
 	if r.ManageStatus {
 		errmark := r.markRunning(ctx, request.NamespacedName, u)
 		if errmark != nil {
@@ -157,7 +181,7 @@ func (r *AnsibleOperatorReconciler) Reconcile(ctx context.Context, request recon
 			logger.Error(err, "Failed to remove generated kubeconfig file")
 		}
 	}()
-	result, err := r.Runner.Run(ident, u, kc.Name())
+	result, err := r.Runner.Run(ident, u, kc.Name(), preRun.ExtraVars)
 	if err != nil {
 		errmark := r.markError(ctx, request.NamespacedName, u, "Unable to run reconciliation")
 		if errmark != nil {
@@ -244,8 +268,25 @@ func (r *AnsibleOperatorReconciler) Reconcile(ctx context.Context, request recon
 	// and do it at the end
 	runSuccessful := len(failureMessages) == 0
 
-	// The finalizer has run successfully, time to remove it
+	// Post-run hooks run after every completed run and are told whether it was
+	// successful. Their errors do not block the removal of the finalizer of a
+	// deleted resource.
 	deleted = u.GetDeletionTimestamp() != nil
+	runResult := RunResult{StatusEvent: statusEvent, FailureMessages: failureMessages}
+	if err := r.runPostRunHooks(ctx, u, runResult); err != nil && deleted {
+		logger.Error(err, "Post-run hook failed, removing the finalizer of the deleted resource anyway")
+	} else if err != nil {
+		if r.ManageStatus {
+			errmark := r.markError(ctx, request.NamespacedName, u, fmt.Sprintf("Post-run hook failed: %v", err))
+			if errmark != nil {
+				logger.Error(errmark, "Unable to mark error after reconciliation")
+			}
+		}
+		logger.Error(err, "Post-run hook failed")
+		return reconcileResult, err
+	}
+
+	// The finalizer has run successfully, time to remove it
 	if deleted && finalizerExists && runSuccessful {
 		controllerutil.RemoveFinalizer(u, finalizer)
 		err := r.Client.Update(ctx, u)
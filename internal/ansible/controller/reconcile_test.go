@@ -16,6 +16,7 @@ package controller_test
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -600,3 +601,176 @@ func TestReconcile(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileHooks(t *testing.T) {
+	gvk := schema.GroupVersionKind{
+		Kind:    "Testing",
+		Group:   "operator-sdk",
+		Version: "v1beta1",
+	}
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "reconcile",
+			Namespace: "default",
+		},
+	}
+	newClient := func() client.Client {
+		return fakeclient.NewClientBuilder().WithObjects(&unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":      "reconcile",
+					"namespace": "default",
+				},
+				"apiVersion": "operator-sdk/v1beta1",
+				"kind":       "Testing",
+			},
+		}).Build()
+	}
+	jobEvents := []eventapi.JobEvent{
+		{
+			Event:   eventapi.EventPlaybookOnStats,
+			Created: eventapi.EventTime{Time: time.Now()},
+		},
+	}
+
+	t.Run("pre-run hook adds extra vars and post-run hook gets stats", func(t *testing.T) {
+		r := &fake.Runner{JobEvents: jobEvents}
+		var postRunResult *controller.RunResult
+		cl := newClient()
+		aor := &controller.AnsibleOperatorReconciler{
+			GVK:             gvk,
+			Runner:          r,
+			Client:          cl,
+			APIReader:       cl,
+			ReconcilePeriod: 5 * time.Second,
+			PreRunHooks: []controller.PreRunHook{
+				controller.PreRunHookFunc(func(context.Context, client.Client,
+					*unstructured.Unstructured) (controller.PreRunResult, error) {
+					return controller.PreRunResult{ExtraVars: map[string]interface{}{"derived": "value"}}, nil
+				}),
+			},
+			PostRunHooks: []controller.PostRunHook{
+				controller.PostRunHookFunc(func(_ context.Context, _ client.Client, _ *unstructured.Unstructured,
+					result controller.RunResult) error {
+					postRunResult = &result
+					return nil
+				}),
+			},
+		}
+		if _, err := aor.Reconcile(context.TODO(), request); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(r.ExtraVars, map[string]interface{}{"derived": "value"}) {
+			t.Fatalf("Unexpected extra vars: %v", r.ExtraVars)
+		}
+		if postRunResult == nil || postRunResult.StatusEvent.Event != eventapi.EventPlaybookOnStats {
+			t.Fatalf("Post-run hook did not receive the stats event: %v", postRunResult)
+		}
+		if !postRunResult.Successful() {
+			t.Fatalf("Post-run hook received a failed result: %v", postRunResult.FailureMessages)
+		}
+	})
+
+	t.Run("pre-run hook skips the run", func(t *testing.T) {
+		r := &fake.Runner{JobEvents: jobEvents, Error: errors.New("runner should not run")}
+		cl := newClient()
+		aor := &controller.AnsibleOperatorReconciler{
+			GVK:             gvk,
+			Runner:          r,
+			Client:          cl,
+			APIReader:       cl,
+			ReconcilePeriod: 5 * time.Second,
+			PreRunHooks: []controller.PreRunHook{
+				controller.PreRunHookFunc(func(context.Context, client.Client,
+					*unstructured.Unstructured) (controller.PreRunResult, error) {
+					return controller.PreRunResult{Skip: true, RequeueAfter: time.Second}, nil
+				}),
+			},
+		}
+		result, err := aor.Reconcile(context.TODO(), request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.RequeueAfter != time.Second {
+			t.Fatalf("Unexpected requeue after: %v", result.RequeueAfter)
+		}
+	})
+
+	t.Run("post-run hooks run after a failed run", func(t *testing.T) {
+		r := &fake.Runner{JobEvents: append([]eventapi.JobEvent{{
+			Event:   eventapi.EventRunnerOnFailed,
+			Created: eventapi.EventTime{Time: time.Now()},
+		}}, jobEvents...)}
+		var postRunResult *controller.RunResult
+		cl := newClient()
+		aor := &controller.AnsibleOperatorReconciler{
+			GVK:             gvk,
+			Runner:          r,
+			Client:          cl,
+			APIReader:       cl,
+			ReconcilePeriod: 5 * time.Second,
+			PostRunHooks: []controller.PostRunHook{
+				controller.PostRunHookFunc(func(_ context.Context, _ client.Client, _ *unstructured.Unstructured,
+					result controller.RunResult) error {
+					postRunResult = &result
+					return nil
+				}),
+			},
+		}
+		if _, err := aor.Reconcile(context.TODO(), request); err == nil {
+			t.Fatalf("Expected the failed run to return an error")
+		}
+		if postRunResult == nil {
+			t.Fatalf("Post-run hook did not run")
+		}
+		if postRunResult.Successful() {
+			t.Fatalf("Post-run hook received a successful result for a failed run")
+		}
+	})
+
+	t.Run("deleted resource runs the finalizer despite hooks", func(t *testing.T) {
+		r := &fake.Runner{JobEvents: jobEvents, Finalizer: "testing.io/finalizer"}
+		cl := fakeclient.NewClientBuilder().WithObjects(&unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":              "reconcile",
+					"namespace":         "default",
+					"finalizers":        []interface{}{"testing.io/finalizer"},
+					"deletionTimestamp": time.Now().Format(time.RFC3339),
+				},
+				"apiVersion": "operator-sdk/v1beta1",
+				"kind":       "Testing",
+			},
+		}).Build()
+		aor := &controller.AnsibleOperatorReconciler{
+			GVK:             gvk,
+			Runner:          r,
+			Client:          cl,
+			APIReader:       cl,
+			ReconcilePeriod: 5 * time.Second,
+			PreRunHooks: []controller.PreRunHook{
+				controller.PreRunHookFunc(func(context.Context, client.Client,
+					*unstructured.Unstructured) (controller.PreRunResult, error) {
+					return controller.PreRunResult{Skip: true}, nil
+				}),
+			},
+			PostRunHooks: []controller.PostRunHook{
+				controller.PostRunHookFunc(func(context.Context, client.Client, *unstructured.Unstructured,
+					controller.RunResult) error {
+					return errors.New("post-run hook failed")
+				}),
+			},
+		}
+		if _, err := aor.Reconcile(context.TODO(), request); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		if err := cl.Get(context.TODO(), request.NamespacedName, u); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(u.GetFinalizers()) != 0 {
+			t.Fatalf("Finalizer was not removed: %v", u.GetFinalizers())
+		}
+	})
+}
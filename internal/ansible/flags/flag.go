@@ -37,11 +37,18 @@ type Flags struct {
 	LeaderElectionNamespace string
 	GracefulShutdownTimeout time.Duration
 	AnsibleArgs             string
+	ProxySkipCachePaths     string
+	ProxyCacheSizeLimit     string
+	ProxyAuditLog           string
+	ProxyAuditVerbs         []string
 }
 
 const AnsibleRolesPathEnvVar = "ANSIBLE_ROLES_PATH"
 const AnsibleCollectionsPathEnvVar = "ANSIBLE_COLLECTIONS_PATH"
 
+// DefaultProxyAuditVerbs are the API verbs written to the proxy audit log if none are configured.
+var DefaultProxyAuditVerbs = []string{"create", "update", "patch", "delete", "deletecollection"}
+
 // AddTo - Add the ansible operator flags to the the flagset
 func (f *Flags) AddTo(flagSet *pflag.FlagSet) {
 	flagSet.DurationVar(&f.ReconcilePeriod,
@@ -136,4 +143,28 @@ func (f *Flags) AddTo(flagSet *pflag.FlagSet) {
 		"",
 		"Ansible args. Allows user to specify arbitrary arguments for ansible-based operators.",
 	)
+	flagSet.StringVar(&f.ProxySkipCachePaths,
+		"proxy-skip-cache-paths",
+		"",
+		"Comma separated list of regular expressions matching request paths that the proxy"+
+			" always sends to the API server instead of reading from the cache.",
+	)
+	flagSet.StringVar(&f.ProxyCacheSizeLimit,
+		"proxy-cache-size-limit",
+		"",
+		"Approximate memory budget of objects served by the proxy from the cache, e.g. 256Mi."+
+			" Once reached, resource types that are not cached yet are read from the API server."+
+			" If unset, the cache is not limited.",
+	)
+	flagSet.StringVar(&f.ProxyAuditLog,
+		"proxy-audit-log",
+		"",
+		"File to which the proxy writes one JSON record per Kubernetes API request matching"+
+			" --proxy-audit-verbs. Use \"-\" to write to stdout. If unset, requests are not audited.",
+	)
+	flagSet.StringSliceVar(&f.ProxyAuditVerbs,
+		"proxy-audit-verbs",
+		DefaultProxyAuditVerbs,
+		"Comma separated list of API verbs written to the proxy audit log.",
+	)
 }
@@ -0,0 +1,214 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/operator-sdk/internal/ansible/flags"
+	k8sRequest "github.com/operator-framework/operator-sdk/internal/ansible/proxy/requestfactory"
+)
+
+const redactedValue = "REDACTED"
+
+// AuditOwner identifies the custom resource on behalf of which a request was made.
+type AuditOwner struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	UID        string `json:"uid,omitempty"`
+}
+
+// AuditEvent is a single record of the audit log. One event is written, as a
+// line of JSON, for each mutating request that matches the configured verbs.
+type AuditEvent struct {
+	Timestamp  time.Time       `json:"timestamp"`
+	Owner      *AuditOwner     `json:"owner,omitempty"`
+	Verb       string          `json:"verb"`
+	Group      string          `json:"group"`
+	Version    string          `json:"version"`
+	Kind       string          `json:"kind,omitempty"`
+	Resource   string          `json:"resource"`
+	Namespace  string          `json:"namespace,omitempty"`
+	Name       string          `json:"name,omitempty"`
+	RequestURI string          `json:"requestURI"`
+	Code       int             `json:"code"`
+	LatencyMS  int64           `json:"latencyMs"`
+	Body       json.RawMessage `json:"body,omitempty"`
+}
+
+// auditHandler writes an AuditEvent for each request matching verbs. It must be
+// run before the authorization header is removed, since the owner of a request
+// is encoded in it.
+type auditHandler struct {
+	next       http.Handler
+	restMapper meta.RESTMapper
+	verbs      sets.String
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newAuditHandler(next http.Handler, out io.Writer, verbs []string, restMapper meta.RESTMapper) *auditHandler {
+	if len(verbs) == 0 {
+		verbs = flags.DefaultProxyAuditVerbs
+	}
+	return &auditHandler{
+		next:       next,
+		restMapper: restMapper,
+		verbs:      sets.NewString(verbs...),
+		enc:        json.NewEncoder(out),
+	}
+}
+
+func (a *auditHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	rf := k8sRequest.RequestInfoFactory{APIPrefixes: sets.NewString("api", "apis"),
+		GrouplessAPIPrefixes: sets.NewString("api")}
+	r, err := rf.NewRequestInfo(req)
+	if err != nil || !r.IsResourceRequest || !a.verbs.Has(r.Verb) {
+		a.next.ServeHTTP(w, req)
+		return
+	}
+
+	event := AuditEvent{
+		Timestamp:  time.Now().UTC(),
+		Verb:       r.Verb,
+		Group:      r.APIGroup,
+		Version:    r.APIVersion,
+		Resource:   r.Resource,
+		Namespace:  r.Namespace,
+		Name:       r.Name,
+		RequestURI: req.RequestURI,
+	}
+	if a.restMapper != nil {
+		if k, err := getGVKFromRequestInfo(r, a.restMapper); err == nil {
+			event.Kind = k.Kind
+		}
+	}
+	if owner, err := getRequestOwnerRef(req); err == nil && owner != nil {
+		event.Owner = &AuditOwner{
+			APIVersion: owner.APIVersion,
+			Kind:       owner.Kind,
+			Namespace:  owner.Namespace,
+			Name:       owner.Name,
+			UID:        string(owner.UID),
+		}
+	}
+
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			log.Error(err, "Could not read request body")
+		}
+		req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+		if event.Name == "" {
+			event.Name = nameFromBody(body)
+		}
+		if r.Subresource == "" || r.Subresource == "status" {
+			event.Body = redactBody(isSecretRequest(r), body)
+		}
+	}
+
+	rw := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+	start := time.Now()
+	a.next.ServeHTTP(rw, req)
+	event.LatencyMS = time.Since(start).Milliseconds()
+	event.Code = rw.code
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.enc.Encode(event); err != nil {
+		log.Error(err, "Failed to write audit event")
+	}
+}
+
+// statusRecorder records the status code written by the next handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.code = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// nameFromBody returns metadata.name of a serialized object, if any.
+func nameFromBody(body []byte) string {
+	obj := struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}{}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return ""
+	}
+	return obj.Metadata.Name
+}
+
+// isSecretRequest returns true if r is a request for core Secrets.
+func isSecretRequest(r *k8sRequest.RequestInfo) bool {
+	return r.IsResourceRequest && r.APIGroup == "" && r.Resource == "secrets"
+}
+
+// redactBody returns body as JSON with the values of a Secret replaced. Bodies
+// that are not JSON objects are replaced entirely for Secrets, since they may be
+// patches carrying secret data in an arbitrary shape.
+func redactBody(isSecret bool, body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 || !json.Valid(body) {
+		return nil
+	}
+	if !isSecret {
+		return json.RawMessage(body)
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		b, _ := json.Marshal(redactedValue)
+		return json.RawMessage(b)
+	}
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := obj[field].(map[string]interface{})
+		if !ok {
+			if _, present := obj[field]; present {
+				obj[field] = redactedValue
+			}
+			continue
+		}
+		for k := range values {
+			values[k] = redactedValue
+		}
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	return json.RawMessage(b)
+}
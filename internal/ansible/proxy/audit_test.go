@@ -0,0 +1,134 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/operator-framework/operator-sdk/internal/ansible/proxy/kubeconfig"
+)
+
+func TestAuditHandler(t *testing.T) {
+	restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Version: "v1"}})
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, meta.RESTScopeNamespace)
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	out := &bytes.Buffer{}
+	h := newAuditHandler(next, out, []string{"create", "delete"}, restMapper)
+
+	owner := kubeconfig.NamespacedOwnerReference{
+		OwnerReference: metav1.OwnerReference{APIVersion: "app.example.com/v1", Kind: "App", Name: "app"},
+		Namespace:      "default",
+	}
+	ownerJSON, err := json.Marshal(owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/default/secrets",
+			strings.NewReader(`{"metadata":{"name":"creds"},"data":{"password":"aHVudGVyMg=="}}`)),
+		httptest.NewRequest(http.MethodGet, "/api/v1/namespaces/default/configmaps/cm", nil),
+		httptest.NewRequest(http.MethodPatch, "/api/v1/namespaces/default/configmaps/cm", strings.NewReader(`{}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/namespaces/default/configmaps/cm", nil),
+	}
+	for _, req := range requests {
+		req.SetBasicAuth(base64.StdEncoding.EncodeToString(ownerJSON), "unused")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	events := []AuditEvent{}
+	dec := json.NewDecoder(out)
+	for dec.More() {
+		e := AuditEvent{}
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("Failed to decode audit event: %v", err)
+		}
+		events = append(events, e)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Unexpected number of audit events: got %d, expected 2", len(events))
+	}
+
+	create := events[0]
+	if create.Verb != "create" || create.Kind != "Secret" || create.Name != "creds" || create.Code != http.StatusOK {
+		t.Fatalf("Unexpected create event: %+v", create)
+	}
+	if create.Owner == nil || create.Owner.Kind != "App" || create.Owner.Name != "app" {
+		t.Fatalf("Unexpected create event owner: %+v", create.Owner)
+	}
+	if strings.Contains(string(create.Body), "aHVudGVyMg==") || !strings.Contains(string(create.Body), redactedValue) {
+		t.Fatalf("Secret data was not redacted: %s", create.Body)
+	}
+
+	del := events[1]
+	if del.Verb != "delete" || del.Kind != "ConfigMap" || del.Name != "cm" || del.Code != http.StatusNotFound {
+		t.Fatalf("Unexpected delete event: %+v", del)
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	testCases := []struct {
+		name     string
+		isSecret bool
+		body     string
+		expected string
+	}{
+		{
+			name:     "non secret body is unchanged",
+			body:     `{"data":{"key":"value"}}`,
+			expected: `{"data":{"key":"value"}}`,
+		},
+		{
+			name:     "secret data and stringData values are redacted",
+			isSecret: true,
+			body:     `{"data":{"key":"dmFsdWU="},"stringData":{"other":"value"}}`,
+			expected: `{"data":{"key":"REDACTED"},"stringData":{"other":"REDACTED"}}`,
+		},
+		{
+			name:     "secret json patch is redacted entirely",
+			isSecret: true,
+			body:     `[{"op":"add","path":"/data/key","value":"dmFsdWU="}]`,
+			expected: `"REDACTED"`,
+		},
+		{
+			name: "empty body is dropped",
+			body: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := string(redactBody(tc.isSecret, []byte(tc.body)))
+			if got != tc.expected {
+				t.Fatalf("Unexpected body: got %s, expected %s", got, tc.expected)
+			}
+		})
+	}
+}
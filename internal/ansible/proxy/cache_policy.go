@@ -0,0 +1,101 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// cacheBudget - approximates the memory used by the informer cache per GVK and
+// refuses to start caching new GVKs once the limit has been reached. The size of
+// a GVK is estimated from the largest response served from the cache for it.
+type cacheBudget struct {
+	mu    sync.Mutex
+	limit int64
+	used  int64
+	sizes map[schema.GroupVersionKind]int64
+	// reached is set once the limit has been reached and logged.
+	reached bool
+}
+
+func newCacheBudget(limit int64) *cacheBudget {
+	return &cacheBudget{
+		limit: limit,
+		sizes: map[schema.GroupVersionKind]int64{},
+	}
+}
+
+// admit returns true if reads of gvk may be served from the cache. GVKs that
+// are already cached are always admitted, so that the budget never causes a
+// cached GVK to flip back to live reads.
+func (b *cacheBudget) admit(gvk schema.GroupVersionKind) bool {
+	if b == nil || b.limit <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.sizes[gvk]; ok {
+		return true
+	}
+	return b.used < b.limit
+}
+
+// record updates the estimated size of gvk with the size of a cached response.
+func (b *cacheBudget) record(gvk schema.GroupVersionKind, size int) {
+	if b == nil || b.limit <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if prev, ok := b.sizes[gvk]; ok && prev >= int64(size) {
+		return
+	}
+	b.used += int64(size) - b.sizes[gvk]
+	b.sizes[gvk] = int64(size)
+	if b.used >= b.limit && !b.reached {
+		b.reached = true
+		log.Info("Cache memory budget reached, new resource types will be read from the API server",
+			"limit", b.limit, "used", b.used)
+	}
+}
+
+// writeTracker - remembers when each GVK was last modified through the proxy.
+type writeTracker struct {
+	mu        sync.RWMutex
+	lastWrite map[schema.GroupVersionKind]time.Time
+}
+
+func newWriteTracker() *writeTracker {
+	return &writeTracker{
+		lastWrite: map[schema.GroupVersionKind]time.Time{},
+	}
+}
+
+func (w *writeTracker) recordWrite(gvk schema.GroupVersionKind) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastWrite[gvk] = time.Now()
+}
+
+// writtenWithin returns true if gvk was modified through the proxy within d.
+func (w *writeTracker) writtenWithin(gvk schema.GroupVersionKind, d time.Duration) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	t, ok := w.lastWrite[gvk]
+	return ok && time.Since(t) < d
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCacheBudget(t *testing.T) {
+	secrets := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+	configMaps := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	pods := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+
+	var unlimited *cacheBudget
+	if !unlimited.admit(secrets) {
+		t.Fatalf("nil budget should admit every GVK")
+	}
+
+	b := newCacheBudget(100)
+	if !b.admit(secrets) {
+		t.Fatalf("empty budget should admit %v", secrets)
+	}
+	b.record(secrets, 60)
+	b.record(secrets, 20)
+	if b.used != 60 {
+		t.Fatalf("unexpected used bytes: got %d, expected 60", b.used)
+	}
+	b.record(configMaps, 50)
+	if !b.reached {
+		t.Fatalf("budget should have been reached")
+	}
+	if b.admit(pods) {
+		t.Fatalf("exhausted budget should not admit %v", pods)
+	}
+	if !b.admit(configMaps) {
+		t.Fatalf("exhausted budget should still admit cached %v", configMaps)
+	}
+}
+
+func TestWriteTracker(t *testing.T) {
+	secrets := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+	w := newWriteTracker()
+	if w.writtenWithin(secrets, time.Minute) {
+		t.Fatalf("%v should not have been written", secrets)
+	}
+	w.recordWrite(secrets)
+	if !w.writtenWithin(secrets, time.Minute) {
+		t.Fatalf("%v should have been written within a minute", secrets)
+	}
+	if w.writtenWithin(secrets, 0) {
+		t.Fatalf("%v should not have been written within a zero duration", secrets)
+	}
+}
@@ -37,6 +37,7 @@ import (
 
 	"github.com/operator-framework/operator-sdk/internal/ansible/proxy/controllermap"
 	k8sRequest "github.com/operator-framework/operator-sdk/internal/ansible/proxy/requestfactory"
+	"github.com/operator-framework/operator-sdk/internal/ansible/watches"
 )
 
 type marshaler interface {
@@ -52,10 +53,16 @@ type cacheResponseHandler struct {
 	injectOwnerRef    bool
 	apiResources      *apiResources
 	skipPathRegexp    []*regexp.Regexp
+	budget            *cacheBudget
+	writes            *writeTracker
 }
 
 func (c *cacheResponseHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		// Remember mutations so that reads bounded by a max staleness can bypass the cache
+		// until the informer has had a chance to observe them.
+		c.recordWrite(req)
 	case http.MethodGet:
 		// GET request means we need to check the cache
 		rf := k8sRequest.RequestInfoFactory{APIPrefixes: sets.NewString("api", "apis"),
@@ -103,6 +110,11 @@ func (c *cacheResponseHandler) ServeHTTP(w http.ResponseWriter, req *http.Reques
 			break
 		}
 
+		if !c.budget.admit(k) {
+			log.V(2).Info("Cache memory budget exceeded, must ask the cluster API", "gvk", k)
+			break
+		}
+
 		var m marshaler
 
 		log.V(2).Info("Get resource in our cache", "r", r)
@@ -126,6 +138,7 @@ func (c *cacheResponseHandler) ServeHTTP(w http.ResponseWriter, req *http.Reques
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		c.budget.record(k, len(resp))
 
 		// Set Content-Type header
 		w.Header().Set("Content-Type", "application/json")
@@ -184,6 +197,20 @@ func (c *cacheResponseHandler) skipCacheLookup(r *k8sRequest.RequestInfo, gvk sc
 			log.Info("Skipping, because gvk is blacklisted", "GVK", gvk)
 			return true
 		}
+		if matchesRegexp(req.URL.String(), relatedController.SkipCachePathRegexp) {
+			return true
+		}
+		if policy, ok := relatedController.CachePolicies[gvk]; ok {
+			switch {
+			case policy.Policy == watches.CachePolicyLive:
+				log.V(2).Info("Skipping, because gvk cache policy is live", "GVK", gvk)
+				return true
+			case policy.MaxStaleness > 0 && c.writes.writtenWithin(gvk, policy.MaxStaleness):
+				log.V(2).Info("Skipping, because gvk was recently modified", "GVK", gvk,
+					"maxStaleness", policy.MaxStaleness)
+				return true
+			}
+		}
 	}
 	// check if resource doesn't exist in watched namespaces
 	// if watchedNamespaces[""] exists then we are watching all namespaces
@@ -203,6 +230,24 @@ func (c *cacheResponseHandler) skipCacheLookup(r *k8sRequest.RequestInfo, gvk sc
 	return false
 }
 
+// recordWrite - record the GVK of a mutating request, if it can be determined.
+func (c *cacheResponseHandler) recordWrite(req *http.Request) {
+	if c.writes == nil || c.restMapper == nil {
+		return
+	}
+	rf := k8sRequest.RequestInfoFactory{APIPrefixes: sets.NewString("api", "apis"),
+		GrouplessAPIPrefixes: sets.NewString("api")}
+	r, err := rf.NewRequestInfo(req)
+	if err != nil || !r.IsResourceRequest {
+		return
+	}
+	k, err := getGVKFromRequestInfo(r, c.restMapper)
+	if err != nil {
+		return
+	}
+	c.writes.recordWrite(k)
+}
+
 func (c *cacheResponseHandler) recoverDependentWatches(req *http.Request, un *unstructured.Unstructured) {
 	ownerRef, err := getRequestOwnerRef(req)
 	if err != nil {
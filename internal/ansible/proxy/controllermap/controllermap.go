@@ -15,10 +15,13 @@
 package controllermap
 
 import (
+	"regexp"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	"github.com/operator-framework/operator-sdk/internal/ansible/watches"
 )
 
 // ControllerMap - map of GVK to ControllerMapContents
@@ -41,6 +44,9 @@ type Contents struct {
 	OwnerWatchMap               *WatchMap
 	AnnotationWatchMap          *WatchMap
 	Blacklist                   map[schema.GroupVersionKind]bool
+	CachePolicies               map[schema.GroupVersionKind]watches.CachePolicy
+	SkipCachePathRegexp         []*regexp.Regexp
+	DependentWatches            map[schema.GroupVersionKind]watches.DependentWatch
 }
 
 // NewControllerMap returns a new object that contains a mapping between GVK
@@ -68,6 +74,17 @@ func (cm *ControllerMap) Get(key schema.GroupVersionKind) (value *Contents, ok b
 	return value, ok
 }
 
+// Keys - Returns the GVKs of all controllers in the ControllerMap
+func (cm *ControllerMap) Keys() []schema.GroupVersionKind {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+	keys := make([]schema.GroupVersionKind, 0, len(cm.internal))
+	for k := range cm.internal {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
 // Delete - Deletes associated GVK to controller mapping from the ControllerMap
 func (cm *ControllerMap) Delete(key schema.GroupVersionKind) {
 	cm.mutex.Lock()
@@ -96,6 +113,17 @@ func (wm *WatchMap) Get(key schema.GroupVersionKind) (value interface{}, ok bool
 	return value, ok
 }
 
+// Keys - Returns the GVKs that are being watched
+func (wm *WatchMap) Keys() []schema.GroupVersionKind {
+	wm.mutex.RLock()
+	defer wm.mutex.RUnlock()
+	keys := make([]schema.GroupVersionKind, 0, len(wm.internal))
+	for k := range wm.internal {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
 // Delete - Deletes associated watches for a specific GVK
 func (wm *WatchMap) Delete(key schema.GroupVersionKind) {
 	wm.mutex.Lock()
@@ -0,0 +1,232 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	libhandler "github.com/operator-framework/operator-lib/handler"
+	libpredicate "github.com/operator-framework/operator-lib/predicate"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlpredicate "sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/operator-framework/operator-sdk/internal/ansible/predicate"
+	"github.com/operator-framework/operator-sdk/internal/ansible/proxy/controllermap"
+	"github.com/operator-framework/operator-sdk/internal/ansible/watches"
+)
+
+// DependentWatchesPath is the path of the debug endpoint listing active dependent watches.
+const DependentWatchesPath = "/debug/dependent-watches"
+
+// AddDependentWatches starts the dependent watches declared in watches.yaml for the
+// controller of ownerGVK, so that they exist before the role first runs. Nothing is
+// done if the controller does not watch dependent resources.
+func AddDependentWatches(cMap *controllermap.ControllerMap, ownerGVK schema.GroupVersionKind,
+	restMapper meta.RESTMapper) error {
+	contents, ok := cMap.Get(ownerGVK)
+	if !ok {
+		return errors.New("failed to find controller in map")
+	}
+	if !contents.WatchDependentResources {
+		if len(contents.DependentWatches) != 0 {
+			log.Info("Ignoring declared dependent watches, watchDependentResources is false", "GVK", ownerGVK)
+		}
+		return nil
+	}
+	for gvk, dw := range contents.DependentWatches {
+		enqueueBy, clusterScoped, err := dependentEnqueueBy(restMapper, ownerGVK, dw)
+		if err != nil {
+			return err
+		}
+		// As for dependents watched once the role creates them, cluster-scoped dependents
+		// are only watched by annotation if the controller watches cluster-scoped resources.
+		if enqueueBy == watches.EnqueueByAnnotation && clusterScoped && !contents.WatchClusterScopedResources {
+			log.Info("Ignoring declared cluster-scoped dependent watch, watchClusterScopedResources is false",
+				"GVK", ownerGVK, "dependent", gvk)
+			continue
+		}
+		if err := watchDependent(contents, ownerGVK, gvk, enqueueBy == watches.EnqueueByOwnerReference); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependentEnqueueBy returns how the proxy marks dependents of dw for owners of ownerGVK, and
+// whether the dependent is cluster-scoped. If dw.EnqueueBy is unset, dependents are marked by
+// owner reference, unless the dependent is cluster-scoped and the owner is not, in which case
+// by annotation. An error is returned if dw.EnqueueBy is OwnerReference for such a dependent,
+// since it cannot have an owner reference to a namespaced owner.
+func dependentEnqueueBy(restMapper meta.RESTMapper, ownerGVK schema.GroupVersionKind,
+	dw watches.DependentWatch) (watches.EnqueueByType, bool, error) {
+	ownerMapping, err := restMapper.RESTMapping(ownerGVK.GroupKind(), ownerGVK.Version)
+	if err != nil {
+		return "", false, fmt.Errorf("error getting REST mapping of %s: %w", ownerGVK, err)
+	}
+	depGVK := dw.GroupVersionKind
+	depMapping, err := restMapper.RESTMapping(depGVK.GroupKind(), depGVK.Version)
+	if err != nil {
+		return "", false, fmt.Errorf("error getting REST mapping of dependent watch %s: %w", depGVK, err)
+	}
+
+	clusterScoped := depMapping.Scope.Name() == meta.RESTScopeNameRoot
+	ownerRefSupported := ownerMapping.Scope.Name() == meta.RESTScopeNameRoot || !clusterScoped
+	switch dw.EnqueueBy {
+	case "":
+		if ownerRefSupported {
+			return watches.EnqueueByOwnerReference, clusterScoped, nil
+		}
+		return watches.EnqueueByAnnotation, clusterScoped, nil
+	case watches.EnqueueByOwnerReference:
+		if !ownerRefSupported {
+			return "", false, fmt.Errorf("dependent watch %s: cluster-scoped %s cannot have owner references to "+
+				"namespaced %s, set enqueueBy to %s or leave it unset", depGVK, depGVK.Kind, ownerGVK.Kind,
+				watches.EnqueueByAnnotation)
+		}
+	}
+	return dw.EnqueueBy, clusterScoped, nil
+}
+
+// enqueuesByAnnotation returns true if the controller of ownerGVK declares a dependent watch
+// of dependentGVK with enqueueBy Annotation, in which case the proxy sets owner annotations
+// instead of owner references on dependents.
+func enqueuesByAnnotation(cMap *controllermap.ControllerMap, ownerGVK, dependentGVK schema.GroupVersionKind) bool {
+	contents, ok := cMap.Get(ownerGVK)
+	if !ok {
+		return false
+	}
+	dw, ok := contents.DependentWatches[dependentGVK]
+	return ok && dw.EnqueueBy == watches.EnqueueByAnnotation
+}
+
+// watchDependent adds a watch of dependentGVK to the controller in contents, enqueuing
+// ownerGVK either by owner reference or by annotation. Nothing is done if the
+// dependent is already watched.
+func watchDependent(contents *controllermap.Contents, ownerGVK, dependentGVK schema.GroupVersionKind,
+	useOwnerRef bool) error {
+	predicates := []ctrlpredicate.Predicate{libpredicate.DependentPredicate{}}
+	if dw, ok := contents.DependentWatches[dependentGVK]; ok {
+		var err error
+		if predicates, err = dependentPredicates(dw); err != nil {
+			return err
+		}
+	}
+
+	dependent := &unstructured.Unstructured{}
+	dependent.SetGroupVersionKind(dependentGVK)
+
+	var eventHandler handler.EventHandler
+	if useOwnerRef {
+		if _, exists := contents.OwnerWatchMap.Get(dependentGVK); exists {
+			return nil
+		}
+		contents.OwnerWatchMap.Store(dependentGVK)
+		owner := &unstructured.Unstructured{}
+		owner.SetGroupVersionKind(ownerGVK)
+		eventHandler = &handler.EnqueueRequestForOwner{OwnerType: owner}
+		log.Info("Watching child resource", "kind", dependentGVK, "enqueue_kind", ownerGVK)
+	} else {
+		if _, exists := contents.AnnotationWatchMap.Get(dependentGVK); exists {
+			return nil
+		}
+		contents.AnnotationWatchMap.Store(dependentGVK)
+		ownerGK := ownerGVK.GroupKind()
+		eventHandler = &libhandler.EnqueueRequestForAnnotation{Type: ownerGK}
+		log.Info("Watching child resource", "kind", dependentGVK, "enqueue_annotation_type", ownerGK.String())
+	}
+
+	if err := contents.Controller.Watch(&source.Kind{Type: dependent}, eventHandler, predicates...); err != nil {
+		log.Error(err, "Failed to watch child resource", "kind", dependentGVK, "enqueue_kind", ownerGVK)
+		return err
+	}
+	return nil
+}
+
+// dependentPredicates returns the predicates filtering the events of a declared dependent watch.
+func dependentPredicates(dw watches.DependentWatch) ([]ctrlpredicate.Predicate, error) {
+	predicates := []ctrlpredicate.Predicate{}
+	if dw.IgnoreStatusUpdates {
+		predicates = append(predicates, libpredicate.DependentPredicate{})
+	} else {
+		// Same as DependentPredicate, except that any update causes a reconcile.
+		predicates = append(predicates, ctrlpredicate.ResourceVersionChangedPredicate{}, ctrlpredicate.Funcs{
+			CreateFunc:  func(event.CreateEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		})
+	}
+	filterPredicate, err := predicate.NewResourceFilterPredicate(dw.Selector)
+	if err != nil {
+		return nil, err
+	}
+	return append(predicates, filterPredicate), nil
+}
+
+// dependentWatchesStatus is the representation of a controller's dependent watches
+// served by DependentWatchesHandler.
+type dependentWatchesStatus struct {
+	Owner                 string   `json:"owner"`
+	Declared              []string `json:"declared,omitempty"`
+	OwnerReferenceWatches []string `json:"ownerReferenceWatches"`
+	AnnotationWatches     []string `json:"annotationWatches"`
+}
+
+// DependentWatchesHandler serves the active dependent watches of each controller as JSON.
+func DependentWatchesHandler(cMap *controllermap.ControllerMap) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		statuses := []dependentWatchesStatus{}
+		for _, ownerGVK := range cMap.Keys() {
+			contents, ok := cMap.Get(ownerGVK)
+			if !ok {
+				continue
+			}
+			status := dependentWatchesStatus{
+				Owner:                 ownerGVK.String(),
+				OwnerReferenceWatches: gvkStrings(contents.OwnerWatchMap.Keys()),
+				AnnotationWatches:     gvkStrings(contents.AnnotationWatchMap.Keys()),
+			}
+			for gvk := range contents.DependentWatches {
+				status.Declared = append(status.Declared, gvk.String())
+			}
+			sort.Strings(status.Declared)
+			statuses = append(statuses, status)
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].Owner < statuses[j].Owner })
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(statuses); err != nil {
+			log.Error(err, "Failed to write dependent watches")
+		}
+	})
+}
+
+func gvkStrings(gvks []schema.GroupVersionKind) []string {
+	strs := make([]string, 0, len(gvks))
+	for _, gvk := range gvks {
+		strs = append(strs, gvk.String())
+	}
+	sort.Strings(strs)
+	return strs
+}
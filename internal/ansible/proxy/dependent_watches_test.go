@@ -0,0 +1,229 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/operator-framework/operator-sdk/internal/ansible/proxy/controllermap"
+	"github.com/operator-framework/operator-sdk/internal/ansible/watches"
+)
+
+func TestDependentWatchesHandler(t *testing.T) {
+	ownerGVK := schema.GroupVersionKind{Group: "app.example.com", Version: "v1alpha1", Kind: "App"}
+	deployments := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	configMaps := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+	cMap := controllermap.NewControllerMap()
+	contents := &controllermap.Contents{
+		OwnerWatchMap:      controllermap.NewWatchMap(),
+		AnnotationWatchMap: controllermap.NewWatchMap(),
+		DependentWatches: map[schema.GroupVersionKind]watches.DependentWatch{
+			deployments: {GroupVersionKind: deployments, EnqueueBy: watches.EnqueueByOwnerReference},
+		},
+	}
+	contents.OwnerWatchMap.Store(deployments)
+	contents.AnnotationWatchMap.Store(configMaps)
+	cMap.Store(ownerGVK, contents, nil)
+
+	rec := httptest.NewRecorder()
+	DependentWatchesHandler(cMap).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DependentWatchesPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code: %d", rec.Code)
+	}
+
+	got := []dependentWatchesStatus{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	expected := []dependentWatchesStatus{
+		{
+			Owner:                 ownerGVK.String(),
+			Declared:              []string{deployments.String()},
+			OwnerReferenceWatches: []string{deployments.String()},
+			AnnotationWatches:     []string{configMaps.String()},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Unexpected dependent watches:\n\tgot %+v\n\texpected %+v", got, expected)
+	}
+}
+
+func TestDependentEnqueueBy(t *testing.T) {
+	namespacedOwner := schema.GroupVersionKind{Group: "app.example.com", Version: "v1alpha1", Kind: "App"}
+	clusterOwner := schema.GroupVersionKind{Group: "app.example.com", Version: "v1alpha1", Kind: "ClusterApp"}
+	deployments := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	clusterRoles := schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}
+
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(namespacedOwner, meta.RESTScopeNamespace)
+	restMapper.Add(deployments, meta.RESTScopeNamespace)
+	restMapper.Add(clusterOwner, meta.RESTScopeRoot)
+	restMapper.Add(clusterRoles, meta.RESTScopeRoot)
+
+	testCases := []struct {
+		name      string
+		owner     schema.GroupVersionKind
+		dw        watches.DependentWatch
+		expected  watches.EnqueueByType
+		expectErr bool
+	}{
+		{
+			name:     "namespaced dependent of a namespaced owner",
+			owner:    namespacedOwner,
+			dw:       watches.DependentWatch{GroupVersionKind: deployments},
+			expected: watches.EnqueueByOwnerReference,
+		},
+		{
+			name:     "cluster-scoped dependent of a namespaced owner",
+			owner:    namespacedOwner,
+			dw:       watches.DependentWatch{GroupVersionKind: clusterRoles},
+			expected: watches.EnqueueByAnnotation,
+		},
+		{
+			name:     "cluster-scoped dependent of a cluster-scoped owner",
+			owner:    clusterOwner,
+			dw:       watches.DependentWatch{GroupVersionKind: clusterRoles, EnqueueBy: watches.EnqueueByOwnerReference},
+			expected: watches.EnqueueByOwnerReference,
+		},
+		{
+			name:     "annotation on a namespaced dependent of a namespaced owner",
+			owner:    namespacedOwner,
+			dw:       watches.DependentWatch{GroupVersionKind: deployments, EnqueueBy: watches.EnqueueByAnnotation},
+			expected: watches.EnqueueByAnnotation,
+		},
+		{
+			name:     "annotation on a namespaced dependent of a cluster-scoped owner",
+			owner:    clusterOwner,
+			dw:       watches.DependentWatch{GroupVersionKind: deployments, EnqueueBy: watches.EnqueueByAnnotation},
+			expected: watches.EnqueueByAnnotation,
+		},
+		{
+			name:      "owner reference on a cluster-scoped dependent of a namespaced owner",
+			owner:     namespacedOwner,
+			dw:        watches.DependentWatch{GroupVersionKind: clusterRoles, EnqueueBy: watches.EnqueueByOwnerReference},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enqueueBy, _, err := dependentEnqueueBy(restMapper, tc.owner, tc.dw)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got enqueueBy %q", enqueueBy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if enqueueBy != tc.expected {
+				t.Fatalf("Unexpected enqueueBy: got %q, expected %q", enqueueBy, tc.expected)
+			}
+		})
+	}
+}
+
+func TestAddDependentWatchesDisabled(t *testing.T) {
+	ownerGVK := schema.GroupVersionKind{Group: "app.example.com", Version: "v1alpha1", Kind: "App"}
+	deployments := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+	cMap := controllermap.NewControllerMap()
+	contents := &controllermap.Contents{
+		WatchDependentResources: false,
+		OwnerWatchMap:           controllermap.NewWatchMap(),
+		AnnotationWatchMap:      controllermap.NewWatchMap(),
+		DependentWatches: map[schema.GroupVersionKind]watches.DependentWatch{
+			deployments: {GroupVersionKind: deployments},
+		},
+	}
+	cMap.Store(ownerGVK, contents, nil)
+
+	if err := AddDependentWatches(cMap, ownerGVK, meta.NewDefaultRESTMapper(nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if keys := contents.OwnerWatchMap.Keys(); len(keys) != 0 {
+		t.Fatalf("Unexpected dependent watches: %v", keys)
+	}
+}
+
+func TestAddDependentWatchesClusterScoped(t *testing.T) {
+	ownerGVK := schema.GroupVersionKind{Group: "app.example.com", Version: "v1alpha1", Kind: "App"}
+	clusterRoles := schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}
+
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(ownerGVK, meta.RESTScopeNamespace)
+	restMapper.Add(clusterRoles, meta.RESTScopeRoot)
+
+	cMap := controllermap.NewControllerMap()
+	contents := &controllermap.Contents{
+		WatchDependentResources:     true,
+		WatchClusterScopedResources: false,
+		OwnerWatchMap:               controllermap.NewWatchMap(),
+		AnnotationWatchMap:          controllermap.NewWatchMap(),
+		DependentWatches: map[schema.GroupVersionKind]watches.DependentWatch{
+			clusterRoles: {GroupVersionKind: clusterRoles},
+		},
+	}
+	cMap.Store(ownerGVK, contents, nil)
+
+	if err := AddDependentWatches(cMap, ownerGVK, restMapper); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if keys := contents.AnnotationWatchMap.Keys(); len(keys) != 0 {
+		t.Fatalf("Unexpected dependent watches: %v", keys)
+	}
+}
+
+func TestEnqueuesByAnnotation(t *testing.T) {
+	ownerGVK := schema.GroupVersionKind{Group: "app.example.com", Version: "v1alpha1", Kind: "App"}
+	deployments := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	configMaps := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	secrets := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+
+	cMap := controllermap.NewControllerMap()
+	cMap.Store(ownerGVK, &controllermap.Contents{
+		DependentWatches: map[schema.GroupVersionKind]watches.DependentWatch{
+			deployments: {GroupVersionKind: deployments, EnqueueBy: watches.EnqueueByAnnotation},
+			configMaps:  {GroupVersionKind: configMaps},
+		},
+	}, nil)
+
+	testCases := []struct {
+		name      string
+		owner     schema.GroupVersionKind
+		dependent schema.GroupVersionKind
+		expected  bool
+	}{
+		{name: "declared by annotation", owner: ownerGVK, dependent: deployments, expected: true},
+		{name: "declared without enqueueBy", owner: ownerGVK, dependent: configMaps},
+		{name: "not declared", owner: ownerGVK, dependent: secrets},
+		{name: "unknown owner", owner: schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, dependent: deployments},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := enqueuesByAnnotation(cMap, tc.owner, tc.dependent); got != tc.expected {
+				t.Fatalf("Unexpected result: got %v, expected %v", got, tc.expected)
+			}
+		})
+	}
+}
@@ -143,6 +143,10 @@ func (i *injectOwnerReferenceHandler) ServeHTTP(w http.ResponseWriter, req *http
 				http.Error(w, m, http.StatusBadRequest)
 				return
 			}
+			// A declared dependent watch may map dependents to their owner by annotation instead.
+			if addOwnerRef && enqueuesByAnnotation(i.cMap, ownerGVK, data.GroupVersionKind()) {
+				addOwnerRef = false
+			}
 			if addOwnerRef {
 				data.SetOwnerReferences(append(data.GetOwnerReferences(), owner.OwnerReference))
 			} else {
@@ -21,23 +21,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
-	libhandler "github.com/operator-framework/operator-lib/handler"
-	"github.com/operator-framework/operator-lib/predicate"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
-	"sigs.k8s.io/controller-runtime/pkg/handler"
-	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/operator-framework/operator-sdk/internal/ansible/proxy/controllermap"
 	"github.com/operator-framework/operator-sdk/internal/ansible/proxy/kubeconfig"
@@ -59,6 +58,12 @@ func RequestLogHandler(h http.Handler) http.Handler {
 		}
 		// fix body
 		req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+		// Never log the contents of Secrets
+		rf := k8sRequest.RequestInfoFactory{APIPrefixes: sets.NewString("api", "apis"),
+			GrouplessAPIPrefixes: sets.NewString("api")}
+		if r, err := rf.NewRequestInfo(req); err == nil && isSecretRequest(r) {
+			body = redactBody(true, body)
+		}
 		log.Info("Request Info", "method", req.Method, "uri", req.RequestURI, "body", string(body))
 		// Removing the authorization so that the proxy can set the correct authorization.
 		req.Header.Del("Authorization")
@@ -85,12 +90,28 @@ type Options struct {
 	DisableCache      bool
 	OwnerInjection    bool
 	LogRequests       bool
+	// SkipCachePaths is a comma separated list of regular expressions matching
+	// request paths that are always sent to the API server, in addition to
+	// AutoSkipCacheREList.
+	SkipCachePaths string
+	// CacheSizeLimit is the approximate number of bytes of objects the proxy
+	// serves from the informer cache. Once reached, resource types that are not
+	// cached yet are read from the API server. Zero means no limit.
+	CacheSizeLimit int64
+	// AuditLog, if set, receives one JSON AuditEvent per request matching
+	// AuditVerbs. If AuditVerbs is empty, flags.DefaultProxyAuditVerbs are audited.
+	AuditLog   io.Writer
+	AuditVerbs []string
 }
 
 // Run will start a proxy server in a go routine that returns on the error
 // channel if something is not correct on startup. Run will not return until
 // the network socket is listening.
 func Run(done chan error, o Options) error {
+	skipCacheRegexps, err := skipCachePathRegexps(o.SkipCachePaths)
+	if err != nil {
+		return err
+	}
 	server, err := newServer("/", o.KubeConfig)
 	if err != nil {
 		return err
@@ -163,10 +184,6 @@ func Run(done chan error, o Options) error {
 		server.Handler = RequestLogHandler(server.Handler)
 	}
 	if !o.DisableCache {
-		autoSkipCacheRegexp, err := MakeRegexpArray(AutoSkipCacheREList)
-		if err != nil {
-			log.Error(err, "Failed to parse cache skip regular expression")
-		}
 		server.Handler = &cacheResponseHandler{
 			next:              server.Handler,
 			informerCache:     o.Cache,
@@ -175,9 +192,15 @@ func Run(done chan error, o Options) error {
 			cMap:              o.ControllerMap,
 			injectOwnerRef:    o.OwnerInjection,
 			apiResources:      resources,
-			skipPathRegexp:    autoSkipCacheRegexp,
+			skipPathRegexp:    skipCacheRegexps,
+			budget:            newCacheBudget(o.CacheSizeLimit),
+			writes:            newWriteTracker(),
 		}
 	}
+	// Audit outermost so that requests served from the cache are audited too.
+	if o.AuditLog != nil {
+		server.Handler = newAuditHandler(server.Handler, o.AuditLog, o.AuditVerbs, o.RESTMapper)
+	}
 
 	l, err := server.Listen(o.Address, o.Port)
 	if err != nil {
@@ -190,6 +213,23 @@ func Run(done chan error, o Options) error {
 	return nil
 }
 
+// skipCachePathRegexps returns the regular expressions of AutoSkipCacheREList
+// and of paths, a comma separated list of user regular expressions.
+func skipCachePathRegexps(paths string) ([]*regexp.Regexp, error) {
+	regexps := MakeRegexpArrayOrDie(AutoSkipCacheREList)
+	for _, path := range strings.Split(paths, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		re, err := regexp.Compile(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid skip cache path %q: %v", path, err)
+		}
+		regexps = append(regexps, re)
+	}
+	return regexps, nil
+}
+
 // Helper function used by cache response and owner injection
 func addWatchToController(owner kubeconfig.NamespacedOwnerReference, cMap *controllermap.ControllerMap,
 	resource *unstructured.Unstructured, restMapper meta.RESTMapper, useOwnerRef bool) error {
@@ -220,54 +260,22 @@ func addWatchToController(owner kubeconfig.NamespacedOwnerReference, cMap *contr
 	if !ok {
 		return errors.New("failed to find controller in map")
 	}
-	owMap := contents.OwnerWatchMap
-	awMap := contents.AnnotationWatchMap
-	u := &unstructured.Unstructured{}
-	u.SetGroupVersionKind(ownerMapping.GroupVersionKind)
+
+	// Declared dependent watches are established when the operator starts. Namespaced
+	// dependents in another namespace than their owner are still watched by annotation.
+	if _, declared := contents.DependentWatches[resource.GroupVersionKind()]; declared &&
+		(useOwnerRef || !dataNamespaceScoped) {
+		return nil
+	}
 
 	// Add a watch to controller
 	if contents.WatchDependentResources && !contents.Blacklist[resource.GroupVersionKind()] {
-		// Store watch in map
 		// Use EnqueueRequestForOwner unless user has configured watching cluster scoped resources and we have to
 		switch {
 		case useOwnerRef:
-			_, exists := owMap.Get(resource.GroupVersionKind())
-			// If already watching resource no need to add a new watch
-			if exists {
-				return nil
-			}
-
-			owMap.Store(resource.GroupVersionKind())
-			log.Info("Watching child resource", "kind", resource.GroupVersionKind(),
-				"enqueue_kind", u.GroupVersionKind())
-			err := contents.Controller.Watch(&source.Kind{Type: resource},
-				&handler.EnqueueRequestForOwner{OwnerType: u}, predicate.DependentPredicate{})
-			// Store watch in map
-			if err != nil {
-				log.Error(err, "Failed to watch child resource",
-					"kind", resource.GroupVersionKind(), "enqueue_kind", u.GroupVersionKind())
-				return err
-			}
+			return watchDependent(contents, ownerMapping.GroupVersionKind, resource.GroupVersionKind(), true)
 		case (!useOwnerRef && dataNamespaceScoped) || contents.WatchClusterScopedResources:
-			_, exists := awMap.Get(resource.GroupVersionKind())
-			// If already watching resource no need to add a new watch
-			if exists {
-				return nil
-			}
-			awMap.Store(resource.GroupVersionKind())
-			ownerGK := schema.GroupKind{
-				Kind:  owner.Kind,
-				Group: ownerGV.Group,
-			}
-			log.Info("Watching child resource", "kind", resource.GroupVersionKind(),
-				"enqueue_annotation_type", ownerGK.String())
-			err = contents.Controller.Watch(&source.Kind{Type: resource},
-				&libhandler.EnqueueRequestForAnnotation{Type: ownerGK}, predicate.DependentPredicate{})
-			if err != nil {
-				log.Error(err, "Failed to watch child resource",
-					"kind", resource.GroupVersionKind(), "enqueue_kind", u.GroupVersionKind())
-				return err
-			}
+			return watchDependent(contents, ownerMapping.GroupVersionKind, resource.GroupVersionKind(), false)
 		}
 	} else {
 		log.Info("Resource will not be watched/cached.", "GVK", resource.GroupVersionKind())
@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
 
 	kcorev1 "k8s.io/api/core/v1"
@@ -32,6 +33,36 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/ansible/proxy/controllermap"
 )
 
+func TestRunInvalidSkipCachePaths(t *testing.T) {
+	err := Run(make(chan error), Options{SkipCachePaths: "^/apis/metrics.k8s.io/.*,("})
+	if err == nil || !strings.Contains(err.Error(), `invalid skip cache path "("`) {
+		t.Fatalf("Expected an invalid skip cache path error, got: %v", err)
+	}
+}
+
+func TestSkipCachePathRegexps(t *testing.T) {
+	regexps, err := skipCachePathRegexps("^/apis/metrics.k8s.io/.*, ")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The built-in pods exec and attach paths are kept, and empty paths ignored.
+	if len(regexps) != 3 {
+		t.Fatalf("Expected 3 skip cache path regexps, got %d", len(regexps))
+	}
+	for _, path := range []string{"/api/v1/namespaces/default/pods/test/exec", "/apis/metrics.k8s.io/v1beta1/pods"} {
+		matched := false
+		for _, re := range regexps {
+			matched = matched || re.MatchString(path)
+		}
+		if !matched {
+			t.Errorf("Expected path %s to skip the cache", path)
+		}
+	}
+	if regexps[0].MatchString("/api/v1/namespaces/default/configmaps") {
+		t.Errorf("Expected configmaps not to skip the cache")
+	}
+}
+
 func TestHandler(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping ansible proxy testing in short mode")
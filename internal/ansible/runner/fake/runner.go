@@ -37,6 +37,8 @@ type Runner struct {
 	JobEvents []eventapi.JobEvent
 	//Stdout standard out to reply if failure occurs.
 	Stdout string
+	// ExtraVars records the extra vars passed to the last run.
+	ExtraVars map[string]interface{}
 }
 
 type runResult struct {
@@ -56,7 +58,9 @@ func (r *runResult) Stdout() (string, error) {
 }
 
 // Run - runs the fake runner.
-func (r *Runner) Run(_ string, u *unstructured.Unstructured, _ string) (runner.RunResult, error) {
+func (r *Runner) Run(_ string, u *unstructured.Unstructured, _ string,
+	extraVars map[string]interface{}) (runner.RunResult, error) {
+	r.ExtraVars = extraVars
 	if r.Error != nil {
 		return nil, r.Error
 	}
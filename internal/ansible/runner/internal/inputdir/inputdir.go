@@ -22,6 +22,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/afero"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -29,6 +31,14 @@ import (
 
 var log = logf.Log.WithName("inputdir")
 
+const (
+	secretVarsFile = "env/secretvars"
+	// vaultPasswordFile - named pipe from which Ansible reads the vault password.
+	vaultPasswordFile = "env/vault-password"
+	// vaultPasswordPollInterval - how often the vault password pipe is checked for a reader.
+	vaultPasswordPollInterval = 50 * time.Millisecond
+)
+
 // InputDir represents an input directory for ansible-runner.
 type InputDir struct {
 	Path         string
@@ -37,6 +47,12 @@ type InputDir struct {
 	EnvVars      map[string]string
 	Settings     map[string]string
 	CmdLine      string
+	// SecretParameters are written encrypted with VaultPassword instead of to
+	// env/extravars, and passed to ansible-playbook on the command line.
+	SecretParameters map[string]interface{}
+	VaultPassword    string
+
+	stopVaultPassword chan struct{}
 }
 
 // makeDirs creates the required directory structure.
@@ -53,9 +69,13 @@ func (i *InputDir) makeDirs() error {
 }
 
 // addFile adds a file to the given relative path within the input directory.
-func (i *InputDir) addFile(path string, content []byte) error {
+func (i *InputDir) addFile(path string, content []byte, perm os.FileMode) error {
 	fullPath := filepath.Join(i.Path, path)
-	err := ioutil.WriteFile(fullPath, content, 0644)
+	err := ioutil.WriteFile(fullPath, content, perm)
+	if err == nil {
+		// WriteFile does not change the mode of existing files.
+		err = os.Chmod(fullPath, perm)
+	}
 	if err != nil {
 		log.Error(err, "Unable to write file", "Path", fullPath)
 	}
@@ -119,15 +139,15 @@ func (i *InputDir) Write() error {
 		return err
 	}
 
-	err = i.addFile("env/envvars", envVarBytes)
+	err = i.addFile("env/envvars", envVarBytes, 0600)
 	if err != nil {
 		return err
 	}
-	err = i.addFile("env/extravars", paramBytes)
+	err = i.addFile("env/extravars", paramBytes, 0600)
 	if err != nil {
 		return err
 	}
-	err = i.addFile("env/settings", settingsBytes)
+	err = i.addFile("env/settings", settingsBytes, 0600)
 	if err != nil {
 		return err
 	}
@@ -137,9 +157,18 @@ func (i *InputDir) Write() error {
 		i.CmdLine = i.CmdLine[1 : len(i.CmdLine)-1]
 	}
 
-	cmdLineBytes := []byte(i.CmdLine)
+	cmdLine := i.CmdLine
+	if len(i.SecretParameters) > 0 {
+		secretArgs, err := i.writeSecretParameters()
+		if err != nil {
+			return err
+		}
+		cmdLine = strings.TrimSpace(cmdLine + " " + secretArgs)
+	}
+
+	cmdLineBytes := []byte(cmdLine)
 	if len(cmdLineBytes) > 0 {
-		err = i.addFile("env/cmdline", cmdLineBytes)
+		err = i.addFile("env/cmdline", cmdLineBytes, 0600)
 		if err != nil {
 			return err
 		}
@@ -159,7 +188,7 @@ func (i *InputDir) Write() error {
 		} else {
 			hosts = fmt.Sprintf("%s ansible_python_interpreter=%s", hosts, "{{ansible_playbook_python}}")
 		}
-		err = i.addFile("inventory/hosts", []byte(hosts))
+		err = i.addFile("inventory/hosts", []byte(hosts), 0644)
 		if err != nil {
 			return err
 		}
@@ -199,10 +228,80 @@ func (i *InputDir) Write() error {
 			return err
 		}
 
-		err = i.addFile("project/playbook.yaml", playbookBytes)
+		err = i.addFile("project/playbook.yaml", playbookBytes, 0644)
 		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// writeSecretParameters writes the secret parameters encrypted with the vault
+// password, which Ansible reads from a named pipe fed from memory, so that the
+// password is never written to disk. It returns the ansible-playbook arguments
+// that load them.
+func (i *InputDir) writeSecretParameters() (string, error) {
+	if i.VaultPassword == "" {
+		return "", errors.New("a vault password is required to write secret parameters")
+	}
+	paramBytes, err := json.Marshal(i.SecretParameters)
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := vaultEncrypt(paramBytes, []byte(i.VaultPassword))
+	if err != nil {
+		return "", err
+	}
+	if err := i.addFile(secretVarsFile, encrypted, 0600); err != nil {
+		return "", err
+	}
+	passwordPath := filepath.Join(i.Path, vaultPasswordFile)
+	if err := syscall.Mkfifo(passwordPath, 0600); err != nil {
+		return "", fmt.Errorf("error creating vault password pipe: %w", err)
+	}
+	i.stopVaultPassword = make(chan struct{})
+	go serveVaultPassword(passwordPath, []byte(i.VaultPassword), i.stopVaultPassword)
+	return fmt.Sprintf("--vault-password-file %s -e @%s", passwordPath, filepath.Join(i.Path, secretVarsFile)), nil
+}
+
+// serveVaultPassword writes password once to the named pipe at path as soon as
+// it is opened for reading, unless stop is closed first.
+func serveVaultPassword(path string, password []byte, stop <-chan struct{}) {
+	for {
+		// Opening a pipe for writing without blocking fails until it has a reader.
+		f, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+		if err == nil {
+			if _, err := f.Write(password); err != nil {
+				log.Error(err, "Failed to write the vault password")
+			}
+			if err := f.Close(); err != nil {
+				log.Error(err, "Failed to close the vault password pipe")
+			}
+			return
+		}
+		if !errors.Is(err, syscall.ENXIO) {
+			log.Error(err, "Failed to open the vault password pipe")
+			return
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(vaultPasswordPollInterval):
+		}
+	}
+}
+
+// RemoveInputs deletes everything written by Write, leaving only the artifacts
+// of previous runs in the input directory.
+func (i *InputDir) RemoveInputs() error {
+	if i.stopVaultPassword != nil {
+		close(i.stopVaultPassword)
+		i.stopVaultPassword = nil
+	}
+	for _, path := range []string{"env", "project", "inventory"} {
+		if err := os.RemoveAll(filepath.Join(i.Path, path)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
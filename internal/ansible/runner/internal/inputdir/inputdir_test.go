@@ -0,0 +1,216 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputdir
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// vaultDecrypt decrypts data encrypted in the Ansible Vault 1.1 format.
+func vaultDecrypt(t *testing.T, data, password []byte) []byte {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if lines[0] != vaultHeader {
+		t.Fatalf("Unexpected vault header %q", lines[0])
+	}
+	for _, l := range lines[1:] {
+		if len(l) > vaultLineWidth {
+			t.Fatalf("Vault line is longer than %d characters: %q", vaultLineWidth, l)
+		}
+	}
+	inner, err := hex.DecodeString(strings.Join(lines[1:], ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := bytes.Split(inner, []byte("\n"))
+	if len(parts) != 3 {
+		t.Fatalf("Unexpected number of vault parts %d", len(parts))
+	}
+	decoded := make([][]byte, 3)
+	for i, p := range parts {
+		if decoded[i], err = hex.DecodeString(string(p)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	salt, sum, ciphertext := decoded[0], decoded[1], decoded[2]
+
+	cipherKey, hmacKey, iv := vaultKeys(password, salt)
+	mac := hmac.New(sha256.New, hmacKey)
+	mac.Write(ciphertext)
+	if !hmac.Equal(sum, mac.Sum(nil)) {
+		t.Fatal("Vault HMAC does not match")
+	}
+	block, err := aes.NewCipher(cipherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded := make([]byte, len(ciphertext))
+	cipher.NewCTR(block, iv).XORKeyStream(padded, ciphertext)
+	return padded[:len(padded)-int(padded[len(padded)-1])]
+}
+
+func TestVaultEncrypt(t *testing.T) {
+	for _, plaintext := range []string{"", "short", strings.Repeat("0123456789abcdef", 10)} {
+		encrypted, err := vaultEncrypt([]byte(plaintext), []byte("password"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(vaultDecrypt(t, encrypted, []byte("password"))); got != plaintext {
+			t.Errorf("Unexpected plaintext %q, expected %q", got, plaintext)
+		}
+	}
+}
+
+func TestWriteSecretParameters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inputdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	i := InputDir{
+		Path:             dir,
+		Parameters:       map[string]interface{}{"size": 3},
+		CmdLine:          "'--tags foo'",
+		SecretParameters: map[string]interface{}{"admin_password": "hunter2"},
+		VaultPassword:    "password",
+	}
+	if err := i.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	extraVars, err := ioutil.ReadFile(filepath.Join(dir, "env", "extravars"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(extraVars), "hunter2") {
+		t.Errorf("Secret parameter found in extravars: %s", extraVars)
+	}
+	for _, f := range []string{"extravars", "envvars", "settings", "cmdline", "secretvars", "vault-password"} {
+		fi, err := os.Stat(filepath.Join(dir, "env", f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != 0600 {
+			t.Errorf("Unexpected mode %v of env/%s", fi.Mode().Perm(), f)
+		}
+	}
+
+	secretVars, err := ioutil.ReadFile(filepath.Join(dir, secretVarsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]interface{}{}
+	if err := json.Unmarshal(vaultDecrypt(t, secretVars, []byte("password")), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, i.SecretParameters) {
+		t.Errorf("Unexpected secret parameters %v", got)
+	}
+
+	cmdLine, err := ioutil.ReadFile(filepath.Join(dir, "env", "cmdline"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "--tags foo --vault-password-file " + filepath.Join(dir, vaultPasswordFile) +
+		" -e @" + filepath.Join(dir, secretVarsFile)
+	if string(cmdLine) != expected {
+		t.Errorf("Unexpected cmdline %q, expected %q", cmdLine, expected)
+	}
+
+	fi, err := os.Stat(filepath.Join(dir, vaultPasswordFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&os.ModeNamedPipe == 0 {
+		t.Errorf("Expected the vault password file to be a named pipe, got mode %v", fi.Mode())
+	}
+	password, err := ioutil.ReadFile(filepath.Join(dir, vaultPasswordFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(password) != "password" {
+		t.Errorf("Unexpected vault password %q", password)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "artifacts", "test"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.RemoveInputs(); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []string{"env", "project", "inventory"} {
+		if _, err := os.Stat(filepath.Join(dir, d)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, got %v", d, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "artifacts", "test")); err != nil {
+		t.Errorf("Expected artifacts to be kept, got %v", err)
+	}
+}
+
+func TestWriteSecretParametersWithoutPassword(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inputdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	i := InputDir{
+		Path:             dir,
+		SecretParameters: map[string]interface{}{"admin_password": "hunter2"},
+	}
+	if err := i.Write(); err == nil {
+		t.Fatal("Expected an error without a vault password")
+	}
+}
+
+func TestRemoveInputsStopsVaultPassword(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inputdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "vault-password")
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		serveVaultPassword(path, []byte("password"), stop)
+		close(done)
+	}()
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Vault password was still served after stop")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputdir
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// vaultHeader - envelope of the Ansible Vault 1.1 format, the only one
+	// understood by every Ansible version the operator supports.
+	vaultHeader = "$ANSIBLE_VAULT;1.1;AES256"
+
+	vaultSaltLen    = 32
+	vaultKeyLen     = 32
+	vaultIterations = 10000
+	vaultLineWidth  = 80
+)
+
+// vaultKeys derives the cipher key, HMAC key and counter IV from the password
+// the same way ansible-vault does.
+func vaultKeys(password, salt []byte) (cipherKey, hmacKey, iv []byte) {
+	derived := pbkdf2.Key(password, salt, vaultIterations, 2*vaultKeyLen+aes.BlockSize, sha256.New)
+	return derived[:vaultKeyLen], derived[vaultKeyLen : 2*vaultKeyLen], derived[2*vaultKeyLen:]
+}
+
+// vaultEncrypt returns plaintext encrypted with password in the Ansible Vault
+// format, so that ansible-playbook can decrypt it in memory when it is passed
+// with "-e @file" and a vault password.
+func vaultEncrypt(plaintext, password []byte) ([]byte, error) {
+	salt := make([]byte, vaultSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	cipherKey, hmacKey, iv := vaultKeys(password, salt)
+
+	block, err := aes.NewCipher(cipherKey)
+	if err != nil {
+		return nil, err
+	}
+	// ansible-vault pads with PKCS#7 even though CTR mode does not require it.
+	padLen := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := append(append([]byte{}, plaintext...), bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCTR(block, iv).XORKeyStream(ciphertext, padded)
+
+	mac := hmac.New(sha256.New, hmacKey)
+	mac.Write(ciphertext)
+
+	inner := bytes.Join([][]byte{
+		[]byte(hex.EncodeToString(salt)),
+		[]byte(hex.EncodeToString(mac.Sum(nil))),
+		[]byte(hex.EncodeToString(ciphertext)),
+	}, []byte("\n"))
+	body := hex.EncodeToString(inner)
+
+	out := bytes.NewBufferString(vaultHeader + "\n")
+	for len(body) > vaultLineWidth {
+		out.WriteString(body[:vaultLineWidth] + "\n")
+		body = body[vaultLineWidth:]
+	}
+	out.WriteString(body + "\n")
+	return out.Bytes(), nil
+}
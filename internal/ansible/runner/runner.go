@@ -15,6 +15,8 @@
 package runner
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
@@ -51,9 +53,10 @@ const (
 )
 
 // Runner - a runnable that should take the parameters and name and namespace
-// and run the correct code.
+// and run the correct code. Extra vars, if any, are added to the parameters
+// derived from the CR and the watch.
 type Runner interface {
-	Run(string, *unstructured.Unstructured, string) (RunResult, error)
+	Run(ident string, u *unstructured.Unstructured, kubeconfig string, extraVars map[string]interface{}) (RunResult, error)
 	GetFinalizer() (string, bool)
 }
 
@@ -149,6 +152,15 @@ func New(watch watches.Watch, runnerArgs string) (Runner, error) {
 		finalizerCmdFunc = cmdFunc
 	}
 
+	excludeFields := make([][]string, 0, len(watch.ExcludeFields))
+	for _, p := range watch.ExcludeFields {
+		fields, err := watches.ParseFieldPath(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid excludeFields path %q: %w", p, err)
+		}
+		excludeFields = append(excludeFields, fields)
+	}
+
 	return &runner{
 		Path:                path,
 		cmdFunc:             cmdFunc,
@@ -161,6 +173,8 @@ func New(watch watches.Watch, runnerArgs string) (Runner, error) {
 		ansibleArgs:         runnerArgs,
 		snakeCaseParameters: watch.SnakeCaseParameters,
 		markUnsafe:          watch.MarkUnsafe,
+		secretVars:          watch.SecretVars,
+		excludeFields:       excludeFields,
 	}, nil
 }
 
@@ -177,9 +191,12 @@ type runner struct {
 	snakeCaseParameters bool
 	markUnsafe          bool
 	ansibleArgs         string
+	secretVars          []string   // names of parameters passed vault-encrypted instead of in extravars
+	excludeFields       [][]string // fields removed from the copies of the CR in the parameters
 }
 
-func (r *runner) Run(ident string, u *unstructured.Unstructured, kubeconfig string) (RunResult, error) {
+func (r *runner) Run(ident string, u *unstructured.Unstructured, kubeconfig string,
+	extraVars map[string]interface{}) (RunResult, error) {
 	timer := metrics.ReconcileTimer(r.GVK.String())
 	defer timer.ObserveDuration()
 
@@ -199,10 +216,21 @@ func (r *runner) Run(ident string, u *unstructured.Unstructured, kubeconfig stri
 	if err != nil {
 		return nil, err
 	}
+	parameters := r.makeParameters(u)
+	for k, v := range extraVars {
+		parameters[k] = v
+	}
+	secretParameters := r.takeSecretParameters(parameters)
+	vaultPassword := ""
+	if len(secretParameters) > 0 {
+		if vaultPassword, err = newVaultPassword(); err != nil {
+			return nil, err
+		}
+	}
 	inputDir := inputdir.InputDir{
 		Path: filepath.Join("/tmp/ansible-operator/runner/", r.GVK.Group, r.GVK.Version, r.GVK.Kind,
 			u.GetNamespace(), u.GetName()),
-		Parameters: r.makeParameters(u),
+		Parameters: parameters,
 		EnvVars: map[string]string{
 			"K8S_AUTH_KUBECONFIG": kubeconfig,
 			"KUBECONFIG":          kubeconfig,
@@ -211,7 +239,9 @@ func (r *runner) Run(ident string, u *unstructured.Unstructured, kubeconfig stri
 			"runner_http_url":  receiver.SocketPath,
 			"runner_http_path": receiver.URLPath,
 		},
-		CmdLine: r.ansibleArgs,
+		CmdLine:          r.ansibleArgs,
+		SecretParameters: secretParameters,
+		VaultPassword:    vaultPassword,
 	}
 	// If Path is a dir, assume it is a role path. Otherwise assume it's a
 	// playbook path
@@ -224,6 +254,9 @@ func (r *runner) Run(ident string, u *unstructured.Unstructured, kubeconfig stri
 	}
 	err = inputDir.Write()
 	if err != nil {
+		if rmErr := inputDir.RemoveInputs(); rmErr != nil {
+			logger.Error(rmErr, "Error removing ansible-runner inputs")
+		}
 		return nil, err
 	}
 	maxArtifacts := r.maxRunnerArtifacts
@@ -267,6 +300,12 @@ func (r *runner) Run(ident string, u *unstructured.Unstructured, kubeconfig stri
 			logger.Info("Ansible-runner exited successfully")
 		}
 
+		// The inputs hold the CR and the parameters of the run, so they are not
+		// kept once ansible-runner has read them. Artifacts are kept.
+		if err := inputDir.RemoveInputs(); err != nil {
+			logger.Error(err, "Error removing ansible-runner inputs")
+		}
+
 		receiver.Close()
 		err = <-errChan
 		// http.Server returns this in the case of being closed cleanly
@@ -324,6 +363,8 @@ func (r *runner) isFinalizerRun(u *unstructured.Unstructured) bool {
 //       <cr_object.spec> as is
 //   }
 // }
+// The fields listed in the excludeFields of the watch, and the spec fields
+// passed as secretVars, are removed from both copies of the CR object.
 func (r *runner) makeParameters(u *unstructured.Unstructured) map[string]interface{} {
 	s := u.Object["spec"]
 	spec, ok := s.(map[string]interface{})
@@ -351,8 +392,21 @@ func (r *runner) makeParameters(u *unstructured.Unstructured) map[string]interfa
 
 	parameters["ansible_operator_meta"] = map[string]string{"namespace": u.GetNamespace(), "name": u.GetName()}
 
+	obj := u.Object
+	if removed := r.removedFields(u); len(removed) > 0 {
+		obj = u.DeepCopy().Object
+		for _, fields := range removed {
+			unstructured.RemoveNestedField(obj, fields...)
+		}
+		if s, ok := obj["spec"].(map[string]interface{}); ok {
+			spec = s
+		} else {
+			spec = map[string]interface{}{}
+		}
+	}
+
 	objKey := escapeAnsibleKey(fmt.Sprintf("_%v_%v", r.GVK.Group, strings.ToLower(r.GVK.Kind)))
-	parameters[objKey] = u.Object
+	parameters[objKey] = obj
 
 	specKey := fmt.Sprintf("%s_spec", objKey)
 	parameters[specKey] = spec
@@ -368,6 +422,49 @@ func (r *runner) makeParameters(u *unstructured.Unstructured) map[string]interfa
 	return parameters
 }
 
+// removedFields returns the paths of the fields removed from the copies of u
+// passed to Ansible: the excludeFields of the watch, and the spec fields whose
+// parameter is one of its secretVars.
+func (r *runner) removedFields(u *unstructured.Unstructured) [][]string {
+	removed := append([][]string{}, r.excludeFields...)
+	spec, ok := u.Object["spec"].(map[string]interface{})
+	if !ok || len(r.secretVars) == 0 {
+		return removed
+	}
+	secretVars := map[string]bool{}
+	for _, name := range r.secretVars {
+		secretVars[name] = true
+	}
+	for k := range spec {
+		if secretVars[k] || secretVars[paramconv.ToSnake(k)] {
+			removed = append(removed, []string{"spec", k})
+		}
+	}
+	return removed
+}
+
+// takeSecretParameters removes the parameters named by the secretVars of the
+// watch from parameters and returns them.
+func (r *runner) takeSecretParameters(parameters map[string]interface{}) map[string]interface{} {
+	secrets := map[string]interface{}{}
+	for _, name := range r.secretVars {
+		if v, ok := parameters[name]; ok {
+			secrets[name] = v
+			delete(parameters, name)
+		}
+	}
+	return secrets
+}
+
+// newVaultPassword returns a random password for the secret parameters of a run.
+func newVaultPassword() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // markUnsafe recursively checks for string values and marks them unsafe.
 // for eg:
 //		spec:
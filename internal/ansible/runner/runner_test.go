@@ -371,3 +371,102 @@ func TestMakeParameters(t *testing.T) {
 		}
 	}
 }
+
+func TestMakeParametersExcludeFields(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "app.example.com/v1alpha1",
+		"kind":       "Database",
+		"metadata": map[string]interface{}{
+			"name":        "example",
+			"annotations": map[string]interface{}{"example.com/token": "abc", "other": "value"},
+		},
+		"spec": map[string]interface{}{
+			"adminPassword": "secret",
+			"size":          int64(3),
+		},
+	}}
+	original := u.DeepCopy()
+
+	testRunner := runner{
+		GVK:                 schema.GroupVersionKind{Group: "app.example.com", Version: "v1alpha1", Kind: "Database"},
+		snakeCaseParameters: true,
+		excludeFields: [][]string{
+			{"spec", "adminPassword"},
+			{"metadata", "annotations", "example.com/token"},
+			{"status", "missing"},
+		},
+	}
+	parameters := testRunner.makeParameters(u)
+
+	obj := parameters["_app_example_com_database"].(map[string]interface{})
+	if _, found, _ := unstructured.NestedFieldNoCopy(obj, "spec", "adminPassword"); found {
+		t.Errorf("Excluded field spec.adminPassword is in the object copy")
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(obj, "metadata", "annotations", "example.com/token"); found {
+		t.Errorf("Excluded annotation is in the object copy")
+	}
+	if v, _, _ := unstructured.NestedString(obj, "metadata", "annotations", "other"); v != "value" {
+		t.Errorf("Unexpected annotation value %q", v)
+	}
+	spec := parameters["_app_example_com_database_spec"].(map[string]interface{})
+	if _, found := spec["adminPassword"]; found {
+		t.Errorf("Excluded field adminPassword is in the spec copy")
+	}
+	if spec["size"] != int64(3) {
+		t.Errorf("Unexpected size %v in the spec copy", spec["size"])
+	}
+	// Fields are only removed from the copies, neither from the CR nor from the parameters.
+	if parameters["admin_password"] != "secret" {
+		t.Errorf("Unexpected admin_password parameter %v", parameters["admin_password"])
+	}
+	if !reflect.DeepEqual(u, original) {
+		t.Errorf("The CR was modified:\n%v", u.Object)
+	}
+}
+
+func TestMakeParametersSecretVars(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "app.example.com/v1alpha1",
+		"kind":       "Database",
+		"metadata":   map[string]interface{}{"name": "example"},
+		"spec": map[string]interface{}{
+			"adminPassword": "secret",
+			"size":          int64(3),
+		},
+	}}
+
+	testRunner := runner{
+		GVK:                 schema.GroupVersionKind{Group: "app.example.com", Version: "v1alpha1", Kind: "Database"},
+		snakeCaseParameters: true,
+		secretVars:          []string{"admin_password"},
+	}
+	parameters := testRunner.makeParameters(u)
+
+	obj := parameters["_app_example_com_database"].(map[string]interface{})
+	if _, found, _ := unstructured.NestedFieldNoCopy(obj, "spec", "adminPassword"); found {
+		t.Errorf("Secret field spec.adminPassword is in the object copy")
+	}
+	spec := parameters["_app_example_com_database_spec"].(map[string]interface{})
+	if _, found := spec["adminPassword"]; found {
+		t.Errorf("Secret field adminPassword is in the spec copy")
+	}
+	if spec["size"] != int64(3) {
+		t.Errorf("Unexpected size %v in the spec copy", spec["size"])
+	}
+	if v, _, _ := unstructured.NestedString(u.Object, "spec", "adminPassword"); v != "secret" {
+		t.Errorf("The CR was modified:\n%v", u.Object)
+	}
+}
+
+func TestTakeSecretParameters(t *testing.T) {
+	testRunner := runner{secretVars: []string{"admin_password", "missing"}}
+	parameters := map[string]interface{}{"admin_password": "secret", "size": 3}
+
+	secrets := testRunner.takeSecretParameters(parameters)
+	if !reflect.DeepEqual(secrets, map[string]interface{}{"admin_password": "secret"}) {
+		t.Errorf("Unexpected secret parameters %v", secrets)
+	}
+	if !reflect.DeepEqual(parameters, map[string]interface{}{"size": 3}) {
+		t.Errorf("Unexpected parameters %v", parameters)
+	}
+}
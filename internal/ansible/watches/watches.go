@@ -22,6 +22,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
@@ -54,6 +55,11 @@ type Watch struct {
 	SnakeCaseParameters         bool                      `yaml:"snakeCaseParameters"`
 	MarkUnsafe                  bool                      `yaml:"markUnsafe"`
 	Selector                    metav1.LabelSelector      `yaml:"selector"`
+	CachePolicies               []CachePolicy             `yaml:"cachePolicies"`
+	SkipCachePaths              []string                  `yaml:"skipCachePaths"`
+	DependentWatches            []DependentWatch          `yaml:"dependentWatches"`
+	SecretVars                  []string                  `yaml:"secretVars"`
+	ExcludeFields               []string                  `yaml:"excludeFields"`
 
 	// Not configurable via watches.yaml
 	MaxConcurrentReconciles int `yaml:"-"`
@@ -68,6 +74,51 @@ type Finalizer struct {
 	Vars     map[string]interface{} `yaml:"vars"`
 }
 
+// CachePolicyType - how the proxy serves reads of a given GVK.
+type CachePolicyType string
+
+const (
+	// CachePolicyCached serves reads from the informer cache. This is the default.
+	CachePolicyCached CachePolicyType = "Cached"
+	// CachePolicyLive always sends reads to the API server.
+	CachePolicyLive CachePolicyType = "Live"
+)
+
+// CachePolicy - Expose per-GVK cache behavior of the proxy to be used by a user.
+// MaxStaleness only applies to the Cached policy: reads of a GVK that was
+// modified through the proxy less than MaxStaleness ago are sent to the API
+// server, since the informer cache may not have observed the change yet.
+type CachePolicy struct {
+	GroupVersionKind schema.GroupVersionKind `yaml:",inline"`
+	Policy           CachePolicyType         `yaml:"policy"`
+	MaxStaleness     time.Duration           `yaml:"maxStaleness"`
+}
+
+// EnqueueByType - how events of a dependent resource are mapped to its owner.
+type EnqueueByType string
+
+const (
+	// EnqueueByOwnerReference maps dependents to owners through owner references, which the proxy
+	// sets on namespaced dependents of namespaced owners and on all dependents of cluster-scoped owners.
+	EnqueueByOwnerReference EnqueueByType = "OwnerReference"
+	// EnqueueByAnnotation maps dependents to owners through the owner annotations, which the proxy
+	// sets on cluster-scoped dependents of namespaced owners, and on any dependent whose watch
+	// is declared with this mode.
+	EnqueueByAnnotation EnqueueByType = "Annotation"
+)
+
+// DependentWatch - Expose a dependent resource watch that is established when the
+// operator starts, instead of the first time the role creates the resource. If
+// EnqueueBy is empty, it is chosen from the scope of the owner and dependent.
+// Cluster-scoped dependents enqueued by annotation are only watched if
+// WatchClusterScopedResources is set.
+type DependentWatch struct {
+	GroupVersionKind    schema.GroupVersionKind `yaml:",inline"`
+	EnqueueBy           EnqueueByType           `yaml:"enqueueBy"`
+	IgnoreStatusUpdates bool                    `yaml:"ignoreStatusUpdates"`
+	Selector            metav1.LabelSelector    `yaml:"selector"`
+}
+
 // Default values for optional fields on Watch
 var (
 	blacklistDefault                   = []schema.GroupVersionKind{}
@@ -79,6 +130,7 @@ var (
 	snakeCaseParametersDefault         = true
 	markUnsafeDefault                  = false
 	selectorDefault                    = metav1.LabelSelector{}
+	ignoreStatusUpdatesDefault         = true
 
 	// these are overridden by cmdline flags
 	maxConcurrentReconcilesDefault = runtime.NumCPU()
@@ -133,6 +185,110 @@ type alias struct {
 	Blacklist                   []schema.GroupVersionKind `yaml:"blacklist,omitempty"`
 	Finalizer                   *Finalizer                `yaml:"finalizer"`
 	Selector                    tempLabelSelector         `yaml:"selector"`
+	CachePolicies               []tempCachePolicy         `yaml:"cachePolicies,omitempty"`
+	SkipCachePaths              []string                  `yaml:"skipCachePaths,omitempty"`
+	DependentWatches            []tempDependentWatch      `yaml:"dependentWatches,omitempty"`
+	SecretVars                  []string                  `yaml:"secretVars,omitempty"`
+	ExcludeFields               []string                  `yaml:"excludeFields,omitempty"`
+}
+
+type tempDependentWatch struct {
+	Group               string            `yaml:"group"`
+	Version             string            `yaml:"version"`
+	Kind                string            `yaml:"kind"`
+	EnqueueBy           EnqueueByType     `yaml:"enqueueBy"`
+	IgnoreStatusUpdates *bool             `yaml:"ignoreStatusUpdates,omitempty"`
+	Selector            tempLabelSelector `yaml:"selector"`
+}
+
+// Creates and validates DependentWatch objects. Used in Unmarshal().
+func parseDependentWatches(tmps []tempDependentWatch, blacklist []schema.GroupVersionKind) ([]DependentWatch, error) {
+	blacklisted := map[schema.GroupVersionKind]bool{}
+	for _, gvk := range blacklist {
+		blacklisted[gvk] = true
+	}
+	seen := map[schema.GroupVersionKind]bool{}
+	var dependentWatches []DependentWatch
+	for _, tmp := range tmps {
+		dw := DependentWatch{
+			GroupVersionKind: schema.GroupVersionKind{
+				Group:   tmp.Group,
+				Version: tmp.Version,
+				Kind:    tmp.Kind,
+			},
+			EnqueueBy:           tmp.EnqueueBy,
+			IgnoreStatusUpdates: ignoreStatusUpdatesDefault,
+			Selector:            parseLabelSelector(tmp.Selector),
+		}
+		if err := verifyGVK(dw.GroupVersionKind); err != nil {
+			return nil, fmt.Errorf("invalid dependent watch GVK: %s: %w", dw.GroupVersionKind, err)
+		}
+		if seen[dw.GroupVersionKind] {
+			return nil, fmt.Errorf("duplicate dependent watch GVK: %s", dw.GroupVersionKind)
+		}
+		seen[dw.GroupVersionKind] = true
+		if blacklisted[dw.GroupVersionKind] {
+			return nil, fmt.Errorf("dependent watch GVK %s is blacklisted", dw.GroupVersionKind)
+		}
+		if dw.EnqueueBy != "" && dw.EnqueueBy != EnqueueByOwnerReference && dw.EnqueueBy != EnqueueByAnnotation {
+			return nil, fmt.Errorf("dependent watch %s: unknown enqueueBy %q", dw.GroupVersionKind, dw.EnqueueBy)
+		}
+		if tmp.IgnoreStatusUpdates != nil {
+			dw.IgnoreStatusUpdates = *tmp.IgnoreStatusUpdates
+		}
+		if _, err := metav1.LabelSelectorAsSelector(&dw.Selector); err != nil {
+			return nil, fmt.Errorf("dependent watch %s: invalid selector: %w", dw.GroupVersionKind, err)
+		}
+		dependentWatches = append(dependentWatches, dw)
+	}
+	return dependentWatches, nil
+}
+
+type tempCachePolicy struct {
+	Group        string           `yaml:"group"`
+	Version      string           `yaml:"version"`
+	Kind         string           `yaml:"kind"`
+	Policy       CachePolicyType  `yaml:"policy"`
+	MaxStaleness *metav1.Duration `yaml:"maxStaleness,omitempty"`
+}
+
+// Creates and validates CachePolicy objects. Used in Unmarshal().
+func parseCachePolicies(tmps []tempCachePolicy) ([]CachePolicy, error) {
+	var policies []CachePolicy
+	for _, tmp := range tmps {
+		p := CachePolicy{
+			GroupVersionKind: schema.GroupVersionKind{
+				Group:   tmp.Group,
+				Version: tmp.Version,
+				Kind:    tmp.Kind,
+			},
+			Policy: tmp.Policy,
+		}
+		if err := verifyGVK(p.GroupVersionKind); err != nil {
+			return nil, fmt.Errorf("invalid cache policy GVK: %s: %w", p.GroupVersionKind, err)
+		}
+		if p.Policy == "" {
+			p.Policy = CachePolicyCached
+		}
+		if tmp.MaxStaleness != nil {
+			p.MaxStaleness = tmp.MaxStaleness.Duration
+		}
+		switch p.Policy {
+		case CachePolicyCached:
+		case CachePolicyLive:
+			if p.MaxStaleness != 0 {
+				return nil, fmt.Errorf("cache policy for %s: maxStaleness is only valid with policy %q",
+					p.GroupVersionKind, CachePolicyCached)
+			}
+		default:
+			return nil, fmt.Errorf("cache policy for %s: unknown policy %q", p.GroupVersionKind, p.Policy)
+		}
+		if p.MaxStaleness < 0 {
+			return nil, fmt.Errorf("cache policy for %s: maxStaleness must not be negative", p.GroupVersionKind)
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
 }
 
 // buildWatch will build Watch based on the values parsed from alias
@@ -203,9 +359,111 @@ func (w *Watch) setValuesFromAlias(tmp alias) error {
 	w.addRolePlaybookPaths(wd)
 	w.Selector = parseLabelSelector(tmp.Selector)
 
+	w.CachePolicies, err = parseCachePolicies(tmp.CachePolicies)
+	if err != nil {
+		return err
+	}
+	for _, re := range tmp.SkipCachePaths {
+		if _, err := regexp.Compile(re); err != nil {
+			return fmt.Errorf("invalid skipCachePaths regular expression %q: %w", re, err)
+		}
+	}
+	w.SkipCachePaths = tmp.SkipCachePaths
+
+	w.DependentWatches, err = parseDependentWatches(tmp.DependentWatches, w.Blacklist)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range tmp.SecretVars {
+		if name == "" {
+			return errors.New("secretVars must not contain empty names")
+		}
+	}
+	w.SecretVars = tmp.SecretVars
+	for _, path := range tmp.ExcludeFields {
+		if _, err := ParseFieldPath(path); err != nil {
+			return fmt.Errorf("invalid excludeFields path %q: %w", path, err)
+		}
+	}
+	w.ExcludeFields = tmp.ExcludeFields
+
 	return nil
 }
 
+// ParseFieldPath splits a JSONPath field expression such as "{.spec.password}",
+// ".spec.password" or "spec['tls.key']" into its field names. Dots within a
+// field name can be escaped with a backslash. Array indexes, wildcards and
+// filters are not supported.
+func ParseFieldPath(path string) ([]string, error) {
+	p := strings.TrimSpace(path)
+	if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+		p = p[1 : len(p)-1]
+	}
+	p = strings.TrimPrefix(p, "$")
+
+	var fields []string
+	var cur strings.Builder
+	inField := false
+	endField := func() error {
+		if !inField {
+			return nil
+		}
+		if cur.Len() == 0 {
+			return errors.New("empty field name")
+		}
+		fields = append(fields, cur.String())
+		cur.Reset()
+		inField = false
+		return nil
+	}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '\\':
+			if i+1 == len(p) {
+				return nil, errors.New("trailing escape character")
+			}
+			i++
+			cur.WriteByte(p[i])
+			inField = true
+		case '.':
+			if err := endField(); err != nil {
+				return nil, err
+			}
+			inField = true
+		case '[':
+			if err := endField(); err != nil {
+				return nil, err
+			}
+			end := strings.IndexByte(p[i:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated bracket")
+			}
+			key := p[i+1 : i+end]
+			if len(key) < 2 || (key[0] != '\'' && key[0] != '"') || key[len(key)-1] != key[0] {
+				return nil, fmt.Errorf("unsupported expression [%s], only quoted field names are allowed", key)
+			}
+			if key = key[1 : len(key)-1]; key == "" {
+				return nil, errors.New("empty field name")
+			}
+			fields = append(fields, key)
+			i += end
+		case '*', '?', '@', '(', ')', ' ':
+			return nil, fmt.Errorf("unsupported character %q", c)
+		default:
+			cur.WriteByte(c)
+			inField = true
+		}
+	}
+	if err := endField(); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("path must not be empty")
+	}
+	return fields, nil
+}
+
 // addRolePlaybookPaths will add the full path based on the current dir
 func (w *Watch) addRolePlaybookPaths(rootDir string) {
 	if len(w.Playbook) > 0 {
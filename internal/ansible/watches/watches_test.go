@@ -382,6 +382,73 @@ func TestLoad(t *testing.T) { //nolint:gocyclo
 			},
 			ManageStatus: true,
 		},
+		Watch{
+			GroupVersionKind: schema.GroupVersionKind{
+				Version: "v1alpha1",
+				Group:   "app.example.com",
+				Kind:    "AnsibleCachePolicyTest",
+			},
+			Role:         validTemplate.ValidRole,
+			ManageStatus: true,
+			CachePolicies: []CachePolicy{
+				{
+					GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Secret"},
+					Policy:           CachePolicyLive,
+				},
+				{
+					GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+					Policy:           CachePolicyCached,
+					MaxStaleness:     time.Second * 30,
+				},
+				{
+					GroupVersionKind: schema.GroupVersionKind{
+						Version: "v1",
+						Group:   "apiextensions.k8s.io",
+						Kind:    "CustomResourceDefinition",
+					},
+					Policy: CachePolicyCached,
+				},
+			},
+			SkipCachePaths: []string{"^/apis/metrics.k8s.io/.*"},
+		},
+		Watch{
+			GroupVersionKind: schema.GroupVersionKind{
+				Version: "v1alpha1",
+				Group:   "app.example.com",
+				Kind:    "AnsibleDependentWatchesTest",
+			},
+			Role:         validTemplate.ValidRole,
+			ManageStatus: true,
+			DependentWatches: []DependentWatch{
+				{
+					GroupVersionKind:    schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+					IgnoreStatusUpdates: true,
+				},
+				{
+					GroupVersionKind: schema.GroupVersionKind{
+						Group:   "rbac.authorization.k8s.io",
+						Version: "v1",
+						Kind:    "ClusterRole",
+					},
+					EnqueueBy:           EnqueueByAnnotation,
+					IgnoreStatusUpdates: false,
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "example"},
+					},
+				},
+			},
+		},
+		Watch{
+			GroupVersionKind: schema.GroupVersionKind{
+				Version: "v1alpha1",
+				Group:   "app.example.com",
+				Kind:    "AnsibleSecretVarsTest",
+			},
+			Role:          validTemplate.ValidRole,
+			ManageStatus:  true,
+			SecretVars:    []string{"admin_password"},
+			ExcludeFields: []string{".spec.adminPassword", "{.metadata.annotations['example.com/token']}"},
+		},
 	}
 
 	testCases := []struct {
@@ -459,6 +526,21 @@ func TestLoad(t *testing.T) { //nolint:gocyclo
 			path:        "testdata/invalid_status.yaml",
 			shouldError: true,
 		},
+		{
+			name:        "error invalid cache policy",
+			path:        "testdata/invalid_cache_policy.yaml",
+			shouldError: true,
+		},
+		{
+			name:        "error invalid dependent watch",
+			path:        "testdata/invalid_dependent_watch.yaml",
+			shouldError: true,
+		},
+		{
+			name:        "error invalid exclude fields",
+			path:        "testdata/invalid_exclude_fields.yaml",
+			shouldError: true,
+		},
 		{
 			name:        "if collection env var is not set and collection is not installed to the default locations, fail",
 			path:        "testdata/invalid_collection.yaml",
@@ -568,6 +650,31 @@ func TestLoad(t *testing.T) { //nolint:gocyclo
 						gotWatch.Selector, expectedWatch.Selector)
 				}
 
+				if !reflect.DeepEqual(gotWatch.CachePolicies, expectedWatch.CachePolicies) {
+					t.Fatalf("Incorrect cache policies GVK %s:\n\tgot %v\n\texpected %v", gvk,
+						gotWatch.CachePolicies, expectedWatch.CachePolicies)
+				}
+
+				if !reflect.DeepEqual(gotWatch.SkipCachePaths, expectedWatch.SkipCachePaths) {
+					t.Fatalf("Incorrect skip cache paths GVK %s:\n\tgot %v\n\texpected %v", gvk,
+						gotWatch.SkipCachePaths, expectedWatch.SkipCachePaths)
+				}
+
+				if !reflect.DeepEqual(gotWatch.DependentWatches, expectedWatch.DependentWatches) {
+					t.Fatalf("Incorrect dependent watches GVK %s:\n\tgot %v\n\texpected %v", gvk,
+						gotWatch.DependentWatches, expectedWatch.DependentWatches)
+				}
+
+				if !reflect.DeepEqual(gotWatch.SecretVars, expectedWatch.SecretVars) {
+					t.Fatalf("Incorrect secret vars GVK %s:\n\tgot %v\n\texpected %v", gvk,
+						gotWatch.SecretVars, expectedWatch.SecretVars)
+				}
+
+				if !reflect.DeepEqual(gotWatch.ExcludeFields, expectedWatch.ExcludeFields) {
+					t.Fatalf("Incorrect exclude fields GVK %s:\n\tgot %v\n\texpected %v", gvk,
+						gotWatch.ExcludeFields, expectedWatch.ExcludeFields)
+				}
+
 				if expectedWatch.MaxConcurrentReconciles == 0 {
 					if gotWatch.MaxConcurrentReconciles != tc.maxConcurrentReconciles {
 						t.Fatalf("Unexpected max workers: %v expected workers: %v", gotWatch.MaxConcurrentReconciles,
@@ -786,6 +893,49 @@ func TestAnsibleVerbosity(t *testing.T) {
 	}
 }
 
+func TestParseFieldPath(t *testing.T) {
+	testCases := []struct {
+		path        string
+		expected    []string
+		shouldError bool
+	}{
+		{path: ".spec.password", expected: []string{"spec", "password"}},
+		{path: "{.spec.password}", expected: []string{"spec", "password"}},
+		{path: "$.spec.password", expected: []string{"spec", "password"}},
+		{path: "spec.password", expected: []string{"spec", "password"}},
+		{path: ".metadata.annotations['example.com/token']",
+			expected: []string{"metadata", "annotations", "example.com/token"}},
+		{path: `.metadata.annotations["example.com/token"].value`,
+			expected: []string{"metadata", "annotations", "example.com/token", "value"}},
+		{path: `.metadata.annotations.example\.com/token`,
+			expected: []string{"metadata", "annotations", "example.com/token"}},
+		{path: "", shouldError: true},
+		{path: "{}", shouldError: true},
+		{path: ".spec..password", shouldError: true},
+		{path: ".spec.users[0]", shouldError: true},
+		{path: ".spec.users[*].password", shouldError: true},
+		{path: ".spec['password'", shouldError: true},
+		{path: `.spec.password\`, shouldError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			got, err := ParseFieldPath(tc.path)
+			if tc.shouldError {
+				if err == nil {
+					t.Fatalf("Expected error for %q, got %v", tc.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("Unexpected fields: %v expected: %v", got, tc.expected)
+			}
+		})
+	}
+}
+
 // Test the func getPossibleRolePaths.
 func TestGetPossibleRolePaths(t *testing.T) {
 	wd, err := os.Getwd()
@@ -18,13 +18,17 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
@@ -168,12 +172,12 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 	}
 
 	cMap := controllermap.NewControllerMap()
-	watches, err := watches.Load(f.WatchesFile, f.MaxConcurrentReconciles, f.AnsibleVerbosity)
+	ws, err := watches.Load(f.WatchesFile, f.MaxConcurrentReconciles, f.AnsibleVerbosity)
 	if err != nil {
 		log.Error(err, "Failed to load watches.")
 		os.Exit(1)
 	}
-	for _, w := range watches {
+	for _, w := range ws {
 		runner, err := runner.New(w, f.AnsibleArgs)
 		if err != nil {
 			log.Error(err, "Failed to create runner")
@@ -194,12 +198,53 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 			os.Exit(1)
 		}
 
+		var skipCachePathRegexp []*regexp.Regexp
+		for _, path := range w.SkipCachePaths {
+			re, err := regexp.Compile(path)
+			if err != nil {
+				log.Error(err, "Failed to parse skip cache path", "GVK", w.GroupVersionKind.String())
+				os.Exit(1)
+			}
+			skipCachePathRegexp = append(skipCachePathRegexp, re)
+		}
+		cachePolicies := map[schema.GroupVersionKind]watches.CachePolicy{}
+		for _, p := range w.CachePolicies {
+			cachePolicies[p.GroupVersionKind] = p
+		}
+		dependentWatches := map[schema.GroupVersionKind]watches.DependentWatch{}
+		for _, dw := range w.DependentWatches {
+			dependentWatches[dw.GroupVersionKind] = dw
+		}
+
 		cMap.Store(w.GroupVersionKind, &controllermap.Contents{Controller: *ctr,
 			WatchDependentResources:     w.WatchDependentResources,
 			WatchClusterScopedResources: w.WatchClusterScopedResources,
 			OwnerWatchMap:               controllermap.NewWatchMap(),
 			AnnotationWatchMap:          controllermap.NewWatchMap(),
+			CachePolicies:               cachePolicies,
+			SkipCachePathRegexp:         skipCachePathRegexp,
+			DependentWatches:            dependentWatches,
 		}, w.Blacklist)
+
+		if err := proxy.AddDependentWatches(cMap, w.GroupVersionKind, mgr.GetRESTMapper()); err != nil {
+			log.Error(err, "Failed to add dependent watches", "GVK", w.GroupVersionKind.String())
+			os.Exit(1)
+		}
+	}
+
+	if err := mgr.AddMetricsExtraHandler(proxy.DependentWatchesPath, proxy.DependentWatchesHandler(cMap)); err != nil {
+		log.Error(err, "Unable to set up dependent watches debug endpoint")
+		os.Exit(1)
+	}
+
+	var cacheSizeLimit int64
+	if f.ProxyCacheSizeLimit != "" {
+		q, err := resource.ParseQuantity(f.ProxyCacheSizeLimit)
+		if err != nil {
+			log.Error(err, "Failed to parse --proxy-cache-size-limit")
+			os.Exit(1)
+		}
+		cacheSizeLimit = q.Value()
 	}
 
 	// todo: remove when a upper version be bumped
@@ -208,6 +253,21 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 		log.Error(err, "Failed to add Healthz check.")
 	}
 
+	var auditLog io.Writer
+	switch f.ProxyAuditLog {
+	case "":
+	case "-":
+		auditLog = os.Stdout
+	default:
+		auditFile, err := os.OpenFile(f.ProxyAuditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			log.Error(err, "Failed to open proxy audit log.")
+			os.Exit(1)
+		}
+		defer auditFile.Close()
+		auditLog = auditFile
+	}
+
 	done := make(chan error)
 
 	// start the proxy
@@ -220,6 +280,10 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 		ControllerMap:     cMap,
 		OwnerInjection:    f.InjectOwnerRef,
 		WatchedNamespaces: []string{namespace},
+		SkipCachePaths:    f.ProxySkipCachePaths,
+		CacheSizeLimit:    cacheSizeLimit,
+		AuditLog:          auditLog,
+		AuditVerbs:        f.ProxyAuditVerbs,
 	})
 	if err != nil {
 		log.Error(err, "Error starting proxy.")
@@ -17,6 +17,7 @@ package bundle
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/bundle/graph"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/bundle/validate"
 )
 
@@ -24,7 +25,7 @@ func NewCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "bundle",
 		Short: "Manage operator bundle metadata",
-		Long: `Manage bundle builds, bundle metadata generation, and bundle validation.
+		Long: `Manage bundle builds, bundle metadata generation, bundle validation, and upgrade graph checks.
 An operator bundle is a portable operator packaging format understood by Kubernetes
 native software, like the Operator Lifecycle Manager.
 
@@ -38,6 +39,7 @@ https://sdk.operatorframework.io/docs/olm-integration
 
 	cmd.AddCommand(
 		validate.NewCmd(),
+		graph.NewCmd(),
 	)
 	return cmd
 }
@@ -26,8 +26,9 @@ var _ = Describe("Running a bundle command", func() {
 			Expect(cmd).NotTo(BeNil())
 
 			subcommands := cmd.Commands()
-			Expect(len(subcommands)).To(Equal(1))
-			Expect(subcommands[0].Use).To(Equal("validate"))
+			Expect(len(subcommands)).To(Equal(2))
+			Expect(subcommands[0].Name()).To(Equal("graph"))
+			Expect(subcommands[1].Use).To(Equal("validate"))
 		})
 	})
 })
@@ -0,0 +1,164 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/graph"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+
+	longHelp = `The 'operator-sdk bundle graph' command computes the upgrade graph of each channel of an operator
+from the replaces, skips and olm.skipRange fields of its bundles, as OLM would once they are published,
+without a cluster. Its arguments are bundle directories or images, or a single package manifests directory.
+
+For each channel, the command reports its head and the upgrade edges between bundles, and checks that:
+  - the channel has a single head, which every bundle in the channel can upgrade to;
+  - the channel has no upgrade cycles;
+  - every bundle but the oldest is an upgrade of another bundle;
+  - bundles only replace bundles in the channel;
+  - no upgrade removes the storage version of a CRD, since objects stored in that version could not be read.
+
+This command exits with an exit code of 1 if any error is found, and 0 if only warnings are found.
+`
+
+	examples = `  # Check the upgrade graph of a series of bundle directories.
+  $ operator-sdk bundle graph ./bundles/v0.1.0 ./bundles/v0.2.0 ./bundles/v0.3.0
+  Package memcached-operator (default channel: alpha)
+    Channel alpha (head: memcached-operator.v0.3.0)
+      memcached-operator.v0.1.0 -> memcached-operator.v0.2.0 (replaces)
+      memcached-operator.v0.2.0 -> memcached-operator.v0.3.0 (replaces)
+      memcached-operator.v0.1.0 -> memcached-operator.v0.3.0 (skipRange)
+
+  # Check the upgrade graph of published bundle images and a new bundle directory.
+  $ operator-sdk bundle graph quay.io/example/memcached-operator-bundle:v0.1.0 ./bundle
+
+  # Check the upgrade graph of package manifests.
+  $ operator-sdk bundle graph ./packagemanifests -o json
+`
+)
+
+type graphCmd struct {
+	output string
+}
+
+// NewCmd returns a command that checks the upgrade graph of a series of bundles.
+func NewCmd() *cobra.Command {
+	c := graphCmd{}
+	cmd := &cobra.Command{
+		Use:     "graph <bundle-dir|bundle-image>...|<packagemanifests-dir>",
+		Short:   "Check the upgrade graph of a series of bundles",
+		Long:    longHelp,
+		Example: examples,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.output != outputText && c.output != outputJSON {
+				return fmt.Errorf("invalid value for output flag: %v", c.output)
+			}
+
+			bundles, err := loadBundles(cmd.Context(), args)
+			if err != nil {
+				log.Fatal(err)
+			}
+			reports := graph.Analyze(bundles)
+			if err := c.print(os.Stdout, reports); err != nil {
+				log.Fatal(err)
+			}
+			for _, r := range reports {
+				if !r.Passed {
+					os.Exit(1)
+				}
+			}
+			return nil
+		},
+	}
+	c.addToFlagSet(cmd.Flags())
+	return cmd
+}
+
+func (c *graphCmd) addToFlagSet(fs *pflag.FlagSet) {
+	fs.StringVarP(&c.output, "output", "o", outputText, "Result format for results. One of: [text, json]")
+}
+
+// loadBundles loads the graph nodes of the bundles in args.
+func loadBundles(ctx context.Context, args []string) ([]graph.Bundle, error) {
+	manifests, err := operator.LoadBundleRefs(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	var bundles []graph.Bundle
+	for _, m := range manifests {
+		b, err := graph.NewBundle(m, m.Package, m.Channels, m.DefaultChannel)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, b)
+	}
+	return bundles, nil
+}
+
+func (c graphCmd) print(w io.Writer, reports []graph.Report) error {
+	if c.output == outputJSON {
+		b, err := json.MarshalIndent(reports, "", "    ")
+		if err != nil {
+			return fmt.Errorf("error marshaling JSON output: %v", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	}
+
+	for _, r := range reports {
+		fmt.Fprintf(w, "Package %s", r.Package)
+		if r.DefaultChannel != "" {
+			fmt.Fprintf(w, " (default channel: %s)", r.DefaultChannel)
+		}
+		fmt.Fprintln(w)
+		for _, ch := range r.Channels {
+			head := ch.Head
+			if head == "" {
+				head = "none"
+			}
+			fmt.Fprintf(w, "  Channel %s (head: %s)\n", ch.Name, head)
+			if len(ch.Edges) == 0 {
+				fmt.Fprintf(w, "    %s\n", strings.Join(ch.Bundles, ", "))
+			}
+			for _, e := range ch.Edges {
+				fmt.Fprintf(w, "    %s -> %s (%s)\n", e.From, e.To, e.Reason)
+			}
+		}
+		for _, f := range r.Findings {
+			prefix := strings.ToUpper(f.Severity)
+			if f.Channel != "" {
+				prefix = fmt.Sprintf("%s [channel %s]", prefix, f.Channel)
+			}
+			fmt.Fprintf(w, "%s: %s\n", prefix, f.Message)
+		}
+	}
+	return nil
+}
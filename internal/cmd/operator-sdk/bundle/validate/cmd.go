@@ -68,6 +68,8 @@ To list and run optional validators, which are specified by a label selector:
   NAME           LABELS                     DESCRIPTION
   operatorhub    name=operatorhub           OperatorHub.io metadata validation
                  suite=operatorframework
+  installmodes   name=installmodes          Install mode and WATCH_NAMESPACE consistency validation
+                 suite=operatorframework
 
 To validate a bundle against the entire suite of validators for Operator Framework, in addition to required bundle validators:
 	
@@ -76,6 +78,10 @@ To validate a bundle against the entire suite of validators for Operator Framewo
 To validate a bundle against the validator for operatorhub.io specifically, in addition to required bundle validators:
 	
   $ operator-sdk bundle validate ./bundle --select-optional name=operatorhub
+
+To validate that the install modes supported by a bundle's CSV match the WATCH_NAMESPACE env var of its deployments:
+
+  $ operator-sdk bundle validate ./bundle --select-optional name=installmodes
 `
 )
 
@@ -0,0 +1,50 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+// installModesValidator checks that the install modes supported by a CSV can be served
+// by its deployments, which must set WATCH_NAMESPACE to the target namespaces of each.
+var installModesValidator interfaces.Validator = interfaces.ValidatorFunc(validateInstallModes)
+
+func validateInstallModes(objs ...interface{}) (results []apierrors.ManifestResult) {
+	for _, obj := range objs {
+		csv, ok := obj.(*v1alpha1.ClusterServiceVersion)
+		if !ok || csv == nil {
+			continue
+		}
+		result := apierrors.ManifestResult{Name: csv.GetName()}
+		supported := operator.GetSupportedInstallModes(csv.Spec.InstallModes)
+		if supported.Len() == 0 {
+			result.Add(apierrors.ErrInvalidCSV("no install modes are supported, so the operator cannot be installed", csv.GetName()))
+		}
+		errs, warns := operator.CheckWatchNamespace(csv, supported)
+		for _, err := range errs {
+			result.Add(apierrors.ErrInvalidCSV(err.Error(), csv.GetName()))
+		}
+		for _, warn := range warns {
+			result.Add(apierrors.WarnInvalidCSV(warn.Error(), csv.GetName()))
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -19,15 +19,19 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	apierrors "github.com/operator-framework/api/pkg/validation/errors"
 	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
 	"github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-sdk/internal/junit"
 )
 
 const (
 	JSONAlpha1 = "json-alpha1"
 	Text       = "text"
+	JUnit      = "junit"
 )
 
 // Result represents the final result
@@ -126,6 +130,42 @@ func (o *Result) printJSON() error {
 	return nil
 }
 
+// toJUnit returns the result as a JUnit report with a single testsuite. Each
+// error is a failed testcase and each warning a passed testcase; a result
+// without errors or warnings is a single passed testcase.
+func (o *Result) toJUnit() *junit.TestSuites {
+	const classname = "bundle.validate"
+	suite := junit.TestSuite{Name: "bundle validate"}
+	var infos []string
+	for _, obj := range o.Outputs {
+		switch obj.Type {
+		case logrus.ErrorLevel.String():
+			suite.TestCases = append(suite.TestCases, junit.TestCase{
+				Name:      obj.Message,
+				Classname: classname,
+				Failure:   &junit.Result{Message: obj.Message, Type: obj.Type},
+			})
+		case logrus.WarnLevel.String():
+			suite.TestCases = append(suite.TestCases, junit.TestCase{
+				Name:      obj.Message,
+				Classname: classname,
+				SystemOut: fmt.Sprintf("%s: %s", obj.Type, obj.Message),
+			})
+		default:
+			infos = append(infos, obj.Message)
+		}
+	}
+	if len(suite.TestCases) == 0 {
+		suite.TestCases = []junit.TestCase{{Name: "bundle is valid", Classname: classname}}
+	}
+	if len(infos) > 0 {
+		suite.SystemOut = strings.Join(infos, "\n")
+	}
+	report := &junit.TestSuites{Name: "bundle validate"}
+	report.AddSuite(suite)
+	return report
+}
+
 // prepare should be used when writing an Result to a non-log writer.
 // it will ensure that the passed boolean will properly set in the case of the setters were not properly used
 func (o *Result) prepare() error {
@@ -168,6 +208,10 @@ func (o *Result) getPrintFuncFormat(format string) func(*Result) error {
 		return func(o *Result) error {
 			return o.printJSON()
 		}
+	case JUnit:
+		return func(o *Result) error {
+			return o.toJUnit().Write(os.Stdout)
+		}
 	}
 
 	// Address all to the Stdout when the type is not JSON
@@ -200,6 +200,32 @@ var _ = Describe("Output Result", func() {
 		})
 	})
 
+	Describe("Test toJUnit()", func() {
+		It("should report a single passed testcase without outputs", func() {
+			report := result.toJUnit()
+			Expect(report.Tests).To(Equal(1))
+			Expect(report.Failures).To(Equal(0))
+			Expect(report.Suites[0].TestCases[0].Name).To(Equal("bundle is valid"))
+		})
+
+		It("should report errors as failed testcases and warnings as passed testcases", func() {
+			result.AddInfo("example of an info")
+			result.AddWarn(errors.New("example of an warn"))
+			result.AddError(errors.New("example of an error"))
+			Expect(result.prepare()).To(Succeed())
+
+			report := result.toJUnit()
+			Expect(report.Tests).To(Equal(2))
+			Expect(report.Failures).To(Equal(1))
+			suite := report.Suites[0]
+			Expect(suite.SystemOut).To(Equal("example of an info"))
+			Expect(suite.TestCases[0].Name).To(Equal("example of an warn"))
+			Expect(suite.TestCases[0].Failure).To(BeNil())
+			Expect(suite.TestCases[1].Name).To(Equal("example of an error"))
+			Expect(suite.TestCases[1].Failure).NotTo(BeNil())
+		})
+	})
+
 	Describe("Test printJSON()", func() {
 		It("should return a pretty JSON", func() {
 			By("adding an error")
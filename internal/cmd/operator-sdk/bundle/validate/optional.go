@@ -44,6 +44,15 @@ var optionalValidators = validators{
 		},
 		desc: "OperatorHub.io metadata validation",
 	},
+	{
+		Validator: installModesValidator,
+		name:      "installmodes",
+		labels: map[string]string{
+			nameKey:  "installmodes",
+			suiteKey: "operatorframework",
+		},
+		desc: "Install mode and WATCH_NAMESPACE consistency validation",
+	},
 }
 
 // runOptionalValidators runs optional validators selected by sel on bundle.
@@ -20,6 +20,7 @@ import (
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
@@ -71,6 +72,37 @@ var _ = Describe("Running optional validators", func() {
 		})
 	})
 
+	Describe("installmodes", func() {
+		It("reports inconsistent install modes and WATCH_NAMESPACE", func() {
+			bundle := &apimanifests.Bundle{}
+			bundle.CSV = &v1alpha1.ClusterServiceVersion{}
+			bundle.CSV.SetName("memcached-operator.v0.0.1")
+			bundle.CSV.Spec.InstallModes = []v1alpha1.InstallMode{
+				{Type: v1alpha1.InstallModeTypeSingleNamespace, Supported: true},
+				{Type: v1alpha1.InstallModeTypeMultiNamespace, Supported: true},
+			}
+			spec := v1alpha1.StrategyDeploymentSpec{Name: "memcached-operator"}
+			spec.Spec.Template.Spec.Containers = []corev1.Container{{
+				Name: "manager",
+				Env: []corev1.EnvVar{{
+					Name: "WATCH_NAMESPACE",
+					ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+					},
+				}},
+			}}
+			bundle.CSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{spec}
+
+			sel := labels.SelectorFromSet(map[string]string{nameKey: "installmodes"})
+			results := optionalValidators.run(bundle, sel)
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Name).To(Equal("memcached-operator.v0.0.1"))
+			Expect(results[0].Errors).To(HaveLen(1))
+			Expect(results[0].Errors[0].Detail).To(ContainSubstring(`["MultiNamespace" "SingleNamespace"]`))
+			Expect(results[0].Warnings).To(BeEmpty())
+		})
+	})
+
 	Describe("checkMatches", func() {
 		var (
 			sel labels.Selector
@@ -53,7 +53,7 @@ func (c bundleValidateCmd) validate(args []string) error {
 	if len(args) != 1 {
 		return errors.New("an image tag or directory is a required argument")
 	}
-	if c.outputFormat != internal.JSONAlpha1 && c.outputFormat != internal.Text {
+	if c.outputFormat != internal.JSONAlpha1 && c.outputFormat != internal.Text && c.outputFormat != internal.JUnit {
 		return fmt.Errorf("invalid value for output flag: %v", c.outputFormat)
 	}
 
@@ -80,7 +80,7 @@ func (c *bundleValidateCmd) addToFlagSet(fs *pflag.FlagSet) {
 		"List all optional validators available. When set, no validators will be run")
 
 	fs.StringVarP(&c.outputFormat, "output", "o", internal.Text,
-		"Result format for results. One of: [text, json-alpha1]. Note: output format types containing "+
+		"Result format for results. One of: [text, json-alpha1, junit]. Note: output format types containing "+
 			"\"alphaX\" are subject to change and not covered by guarantees of stable APIs.")
 }
 
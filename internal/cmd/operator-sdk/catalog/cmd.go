@@ -0,0 +1,42 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/catalog/render"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/catalog/validate"
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "catalog",
+		Short: "Manage file-based catalogs",
+		Long: `Render and validate file-based catalogs. A file-based catalog declares an operator's package,
+channels and bundles as plain JSON or YAML files, which can be kept in git and served to the
+Operator Lifecycle Manager with 'opm serve' or 'operator-sdk run bundle <catalog-dir>'.
+
+More information about file-based catalogs:
+https://olm.operatorframework.io/docs/reference/file-based-catalogs/
+`,
+	}
+
+	cmd.AddCommand(
+		render.NewCmd(),
+		validate.NewCmd(),
+	)
+	return cmd
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/declcfg"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+const (
+	outputJSON = "json"
+	outputYAML = "yaml"
+
+	longHelp = `The 'operator-sdk catalog render' command renders bundle directories or images, or a package manifests
+directory, into a file-based catalog: an olm.package object for each package, an olm.channel object with the
+upgrade graph of each channel, from the replaces, skips and olm.skipRange fields of the bundles, and an
+olm.bundle object for each bundle with its properties and manifests. The catalog is written to stdout.
+
+OLM pulls each bundle from its image. Bundle images are read from their arguments. Bundle directories and
+package manifests have no image, so --image-tag-base must be set to the IMAGE_TAG_BASE of the project Makefile
+to reference the images pushed by 'make bundle-build bundle-push', <image-tag-base>-bundle:v<version>.
+
+The rendered catalog is validated as with 'operator-sdk catalog validate'.
+`
+
+	examples = `  # Render the bundle images of a package into a catalog directory.
+  $ mkdir -p catalog/memcached-operator
+  $ operator-sdk catalog render quay.io/example/memcached-operator-bundle:v0.1.0 \
+      quay.io/example/memcached-operator-bundle:v0.2.0 > catalog/memcached-operator/index.json
+
+  # Render a bundle directory, referencing the bundle image built by the project Makefile.
+  $ operator-sdk catalog render ./bundle --image-tag-base quay.io/example/memcached-operator -o yaml
+
+  # Render package manifests.
+  $ operator-sdk catalog render ./packagemanifests --image-tag-base quay.io/example/memcached-operator
+`
+)
+
+type renderCmd struct {
+	output       string
+	imageTagBase string
+}
+
+// NewCmd returns a command that renders bundles into a file-based catalog.
+func NewCmd() *cobra.Command {
+	c := renderCmd{}
+	cmd := &cobra.Command{
+		Use:     "render <bundle-dir|bundle-image>...|<packagemanifests-dir>",
+		Short:   "Render bundles into a file-based catalog",
+		Long:    longHelp,
+		Example: examples,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.output != outputJSON && c.output != outputYAML {
+				return fmt.Errorf("invalid value for output flag: %v", c.output)
+			}
+			if err := c.run(cmd.Context(), args, os.Stdout); err != nil {
+				log.Fatal(err)
+			}
+			return nil
+		},
+	}
+	c.addToFlagSet(cmd.Flags())
+	return cmd
+}
+
+func (c *renderCmd) addToFlagSet(fs *pflag.FlagSet) {
+	fs.StringVarP(&c.output, "output", "o", outputJSON, "Format of the catalog. One of: [json, yaml]")
+	fs.StringVar(&c.imageTagBase, "image-tag-base", "",
+		"Base of the images of bundle directories and package manifests, which are <image-tag-base>-bundle:v<version>")
+}
+
+func (c renderCmd) run(ctx context.Context, args []string, w io.Writer) error {
+	bundles, err := operator.LoadBundleRefs(ctx, args)
+	if err != nil {
+		return err
+	}
+	for _, b := range bundles {
+		if b.BundleImage != "" {
+			continue
+		}
+		if c.imageTagBase == "" {
+			return fmt.Errorf("bundle %s has no image, set --image-tag-base so that OLM can pull it", b.CSV.GetName())
+		}
+		b.BundleImage = fmt.Sprintf("%s-bundle:v%s", c.imageTagBase, b.CSV.Spec.Version)
+	}
+
+	cfg, err := declcfg.Render(bundles)
+	if err != nil {
+		return fmt.Errorf("error rendering catalog: %v", err)
+	}
+	if err := declcfg.Validate(cfg); err != nil {
+		return fmt.Errorf("rendered catalog is invalid: %v", err)
+	}
+
+	if c.output == outputYAML {
+		return declcfg.WriteYAML(cfg, w)
+	}
+	return declcfg.WriteJSON(cfg, w)
+}
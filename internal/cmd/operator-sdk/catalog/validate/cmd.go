@@ -0,0 +1,81 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/declcfg"
+)
+
+const (
+	longHelp = `The 'operator-sdk catalog validate' command loads the file-based catalog in the JSON and YAML files
+of a directory and its subdirectories, and checks that:
+  - packages, channels and bundles are unique, and channels and bundles belong to a package;
+  - each package's default channel exists;
+  - each bundle has an image or its manifests, and an olm.package property with a valid version;
+  - each channel entry is a bundle of the package;
+  - the upgrade graph of each channel has a single head, which every bundle in the channel can upgrade to,
+    and no upgrade removes the storage version of a CRD.
+
+This command exits with an exit code of 1 if any error is found.
+`
+
+	examples = `  # Validate a catalog directory.
+  $ operator-sdk catalog validate ./catalog
+  INFO[0000] All validation tests have completed successfully
+`
+)
+
+// NewCmd returns a command that validates a file-based catalog directory.
+func NewCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "validate <catalog-dir>",
+		Short:   "Validate a file-based catalog",
+		Long:    longHelp,
+		Example: examples,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := declcfg.LoadDir(args[0])
+			if err != nil {
+				log.Fatalf("Error loading catalog: %v", err)
+			}
+			if len(cfg.Packages) == 0 {
+				log.Fatalf("No packages found in catalog %s", args[0])
+			}
+			if err := declcfg.Validate(cfg); err != nil {
+				for _, e := range errorList(err) {
+					log.Error(e)
+				}
+				log.Fatalf("Catalog %s is invalid", args[0])
+			}
+			log.Info("All validation tests have completed successfully")
+			return nil
+		},
+	}
+}
+
+// errorList returns the errors in err if it is an aggregate.
+func errorList(err error) []error {
+	var agg utilerrors.Aggregate
+	if errors.As(err, &agg) {
+		return agg.Errors()
+	}
+	return []error{err}
+}
@@ -48,6 +48,8 @@ func NewCmd() *cobra.Command {
 				log.Warnf("Cleanup operator: %v\n", pkgErr)
 			case err != nil:
 				log.Fatalf("Cleanup operator: %v\n", err)
+			case u.DryRun:
+				log.Infof("Operator %q not uninstalled, since --dry-run is set\n", u.Package)
 			default:
 				log.Infof("Operator %q uninstalled\n", u.Package)
 			}
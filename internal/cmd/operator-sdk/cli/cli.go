@@ -27,6 +27,7 @@ import (
 
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/alpha/config3alphato3"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/bundle"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/catalog"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/cleanup"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/olm"
@@ -45,6 +46,7 @@ import (
 var (
 	commands = []*cobra.Command{
 		bundle.NewCmd(),
+		catalog.NewCmd(),
 		cleanup.NewCmd(),
 		generate.NewCmd(),
 		olm.NewCmd(),
@@ -26,6 +26,23 @@ func newInstallCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "install",
 		Short: "Install Operator Lifecycle Manager in your cluster",
+		Long: `Install Operator Lifecycle Manager in your cluster.
+
+The manifests of OLM versions stored in the SDK are used without network access; manifests
+of other versions are downloaded from the OLM release, and "latest" falls back to the latest
+stored version if they cannot be downloaded. Manifests of any version can be read from local
+files with --manifests-dir, or --crds-file and --olm-file.
+
+Images in the manifests, such as the OLM and catalog images, can be replaced with mirrors
+with --image-mirror.`,
+		Example: `  # Install OLM in a cluster without internet access, using images from a mirror registry.
+  $ operator-sdk olm install --version 0.17.0 \
+      --image-mirror quay.io/operator-framework=mirror.example.com/operator-framework \
+      --image-mirror quay.io/operatorhubio/catalog=mirror.example.com/operatorhubio/catalog
+
+  # Install an OLM version from the manifests attached to its release.
+  $ operator-sdk olm install --version 0.18.0 --manifests-dir ./olm-0.18.0
+`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := mgr.Install(); err != nil {
 				log.Fatalf("Failed to install OLM version %q: %s", mgr.Version, err)
@@ -35,6 +52,10 @@ func newInstallCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&mgr.Version, "version", installer.DefaultVersion, "version of OLM resources to install")
+	cmd.Flags().Var(&mgr.ImageMirrors, "image-mirror",
+		"Mirror of an image, or of a registry or repository, in OLM manifests, of the form source=mirror, "+
+			"such as quay.io/operator-framework=mirror.example.com/operator-framework. "+
+			"This flag can be specified multiple times")
 	mgr.AddToFlagSet(cmd.Flags())
 	return cmd
 }
@@ -37,6 +37,10 @@ func newStatusCmd() *cobra.Command {
 	cmd.Flags().StringVar(&mgr.OLMNamespace, "olm-namespace", installer.DefaultOLMNamespace, "namespace where OLM is installed")
 	cmd.Flags().StringVar(&mgr.Version, "version", "", "version of OLM installed on cluster; if unset"+
 		"operator-sdk attempts to auto-discover the version")
+	cmd.Flags().StringVarP(&mgr.Output, "output", "o", installer.OutputText,
+		"output format of the status. One of: [text, json, yaml]")
+	cmd.Flags().BoolVar(&mgr.Wait, "wait", false, "wait until all OLM resources are installed, "+
+		"failing if they are not installed within --timeout")
 	mgr.AddToFlagSet(cmd.Flags())
 	return cmd
 }
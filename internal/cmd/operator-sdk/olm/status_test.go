@@ -38,6 +38,11 @@ var _ = Describe("Running an olm status command", func() {
 			Expect(flag).NotTo(BeNil())
 			Expect(flag.DefValue).To(Equal(""))
 			Expect(flag.Usage).NotTo(BeNil())
+
+			flag = cmd.Flags().Lookup("output")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.Shorthand).To(Equal("o"))
+			Expect(flag.DefValue).To(Equal(installer.OutputText))
 		})
 	})
 })
@@ -16,28 +16,58 @@ package bundle
 
 import (
 	"context"
+	"errors"
+	"os"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/operator-framework/operator-sdk/internal/olm/declcfg"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator/bundle"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 )
 
 func NewCmd(cfg *operator.Configuration) *cobra.Command {
 	i := bundle.NewInstall(cfg)
 	cmd := &cobra.Command{
-		Use:   "bundle <bundle-image>",
+		Use:   "bundle <bundle-image>|<bundle-dir>|<catalog-dir> [<dependency-bundle-image>...]",
 		Short: "Deploy an Operator in the bundle format with OLM",
-		Long: `The single argument to this command is a bundle image, with the full registry path specified.
-If using a docker.io image, you must specify docker.io(/<namespace>)?/<bundle-image-name>:<tag>.`,
-		Args:    cobra.ExactArgs(1),
+		Long: `The single argument to this command is a bundle image, with the full registry path specified,
+a bundle directory, or a file-based catalog directory. If using a docker.io image, you must specify
+docker.io(/<namespace>)?/<bundle-image-name>:<tag>.
+
+A bundle directory is served from ConfigMaps instead of an index image, so the bundle does not need to be
+built and pushed as an image. The --index-image and --secret-name flags cannot be used with bundle directories,
+and each bundle ConfigMap must hold less than 1MiB.
+
+A file-based catalog directory, such as one rendered by 'operator-sdk catalog render', must contain a single
+package. It is validated and served from ConfigMaps by 'opm serve' in a registry pod running --index-image,
+and the head of the package's default channel is installed. The catalog is split across ConfigMaps of less
+than 1MiB, so each of its objects must be smaller than 1MiB. OLM pulls the catalog's bundle images, with the
+--secret-name secrets and from their --image-mirror mirrors; --ca-file and --skip-tls cannot be used.
+
+Additional bundle images, such as those of operators the first bundle depends on, are added to the same index
+so that OLM can resolve the first bundle's dependencies without them being published in a catalog. Before
+installing, this command reports which bundle satisfies each package and API dependency of every bundle,
+and which dependencies OLM must resolve from other catalogs in the cluster. Only the first bundle is
+subscribed to; OLM installs its dependencies.`,
+		Args:    cobra.MinimumNArgs(1),
 		PreRunE: func(*cobra.Command, []string) error { return cfg.Load() },
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx, cancel := context.WithTimeout(cmd.Context(), cfg.Timeout)
 			defer cancel()
 
-			i.BundleImage = args[0]
+			if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+				if isBundleDir(args[0]) {
+					i.BundleDir = args[0]
+				} else {
+					i.CatalogDir = args[0]
+				}
+			} else {
+				i.BundleImage = args[0]
+			}
+			i.DependencyImages = args[1:]
 
 			// TODO(joelanford): Add cleanup logic if this fails?
 			_, err := i.Run(ctx)
@@ -52,3 +82,10 @@ If using a docker.io image, you must specify docker.io(/<namespace>)?/<bundle-im
 
 	return cmd
 }
+
+// isBundleDir returns true if dir has bundle metadata, or does not
+// contain a file-based catalog.
+func isBundleDir(dir string) bool {
+	_, _, err := registryutil.FindBundleMetadata(dir)
+	return !errors.As(err, new(registryutil.MetadataNotFoundError)) || !declcfg.IsCatalogDir(dir)
+}
@@ -27,12 +27,23 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/labels"
 
 	scorecardannotations "github.com/operator-framework/operator-sdk/internal/annotations/scorecard"
 	"github.com/operator-framework/operator-sdk/internal/flags"
 	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 	"github.com/operator-framework/operator-sdk/internal/scorecard"
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+const (
+	runnerPod   = "pod"
+	runnerLocal = "local"
+
+	failOnAny        = "any"
+	failOnRegression = "regression"
 )
 
 type scorecardCmd struct {
@@ -41,12 +52,28 @@ type scorecardCmd struct {
 	kubeconfig     string
 	namespace      string
 	outputFormat   string
+	runner         string
 	selector       string
 	serviceAccount string
 	list           bool
 	skipCleanup    bool
 	waitTime       time.Duration
 	testOutput     string
+	testOutputSize string
+	baseline       string
+	failOn         string
+
+	podRequests        map[string]string
+	podLimits          map[string]string
+	podNodeSelector    map[string]string
+	podTolerations     []string
+	podPullSecrets     []string
+	podSecurityContext string
+	untarImage         string
+	bundleTransfer     string
+
+	// bundleImage is the image the bundle was extracted from, if any.
+	bundleImage string
 }
 
 func NewCmd() *cobra.Command {
@@ -74,7 +101,7 @@ If the argument holds an image tag, it must be present remotely.`,
 	scorecardCmd.Flags().StringVarP(&c.config, "config", "c", "", "path to scorecard config file")
 	scorecardCmd.Flags().StringVarP(&c.namespace, "namespace", "n", "", "namespace to run the test images in")
 	scorecardCmd.Flags().StringVarP(&c.outputFormat, "output", "o", "text",
-		"Output format for results. Valid values: text, json")
+		"Output format for results. Valid values: text, json, junit")
 	scorecardCmd.Flags().StringVarP(&c.serviceAccount, "service-account", "s", "default",
 		"Service account to use for tests")
 	scorecardCmd.Flags().BoolVarP(&c.list, "list", "L", false,
@@ -85,26 +112,57 @@ If the argument holds an image tag, it must be present remotely.`,
 		"seconds to wait for tests to complete. Example: 35s")
 	scorecardCmd.Flags().StringVarP(&c.testOutput, "test-output", "t", "test-output",
 		"Test output directory.")
+	scorecardCmd.Flags().StringVar(&c.testOutputSize, "test-output-size-limit", "5Mi",
+		"Size limit of the files collected from each test with the artifacts label, e.g. 10Mi")
+	scorecardCmd.Flags().StringVar(&c.runner, "runner", runnerPod,
+		"Where to run tests. Valid values: pod, local. The local runner runs the built-in basic and olm "+
+			"tests in-process without a cluster, and reports other tests as errored")
+	scorecardCmd.Flags().StringVar(&c.baseline, "baseline", "",
+		"Path to the JSON output of a previous run to compare the results with")
+	scorecardCmd.Flags().StringVar(&c.failOn, "fail-on", failOnAny,
+		"When to exit with a non-zero status. Valid values: any, if any test does not pass, and regression, "+
+			"if a test that passed in --baseline, or is not in it, does not pass")
+	scorecardCmd.Flags().StringToStringVar(&c.podRequests, "pod-requests", nil,
+		"Resource requests of test pod containers, e.g. cpu=100m,memory=64Mi")
+	scorecardCmd.Flags().StringToStringVar(&c.podLimits, "pod-limits", nil,
+		"Resource limits of test pod containers, e.g. cpu=500m,memory=256Mi")
+	scorecardCmd.Flags().StringToStringVar(&c.podNodeSelector, "pod-node-selector", nil,
+		"Node selector of test pods, e.g. kubernetes.io/os=linux")
+	scorecardCmd.Flags().StringSliceVar(&c.podTolerations, "pod-tolerations", nil,
+		"Tolerations of test pods, in the format key[=value][:effect]")
+	scorecardCmd.Flags().StringSliceVar(&c.podPullSecrets, "pod-image-pull-secrets", nil,
+		"Names of secrets in the test namespace used to pull test images")
+	scorecardCmd.Flags().StringVar(&c.podSecurityContext, "pod-security-context", "",
+		"Security context preset of test pods. Valid values: restricted, which complies with the restricted "+
+			"Pod Security Standard")
+	scorecardCmd.Flags().StringVar(&c.untarImage, "untar-image", "",
+		"Image of the init container unpacking the bundle in test pods")
+	scorecardCmd.Flags().StringVar(&c.bundleTransfer, "bundle-transfer", string(scorecard.BundleTransferAuto),
+		"How the bundle is made available to test pods. Valid values: configmap, which splits it across "+
+			"ConfigMaps if needed, image, which pulls the bundle image in test pods, pvc, which unpacks it "+
+			"once in a PVC, and auto, which uses ConfigMaps unless the bundle is too large")
 
 	return scorecardCmd
 }
 
-func (c *scorecardCmd) printOutput(output v1alpha3.TestList) error {
+func (c *scorecardCmd) printOutput(output v1alpha3.TestList, timings *scorecard.Timings) error {
 	switch c.outputFormat {
 	case "text":
 		if len(output.Items) == 0 {
 			fmt.Println("0 tests selected")
 			return nil
 		}
-		for _, test := range output.Items {
-			fmt.Println(test.MarshalText())
-		}
+		fmt.Print(scorecard.TextReport(output, timings))
 	case "json":
 		bytes, err := json.MarshalIndent(output, "", "  ")
 		if err != nil {
 			return fmt.Errorf("marshal json error: %v", err)
 		}
 		fmt.Printf("%s\n", string(bytes))
+	case "junit":
+		if err := scorecard.JUnitReport(output, timings).Write(os.Stdout); err != nil {
+			return fmt.Errorf("write junit error: %v", err)
+		}
 	default:
 		return fmt.Errorf("invalid output format selected")
 	}
@@ -114,6 +172,7 @@ func (c *scorecardCmd) printOutput(output v1alpha3.TestList) error {
 func (c *scorecardCmd) run() (err error) {
 	// Extract bundle image contents if bundle is inferred to be an image.
 	if _, err = os.Stat(c.bundle); err != nil && errors.Is(err, os.ErrNotExist) {
+		c.bundleImage = c.bundle
 		if c.bundle, err = extractBundleImage(c.bundle); err != nil {
 			log.Fatal(err)
 		}
@@ -131,6 +190,7 @@ func (c *scorecardCmd) run() (err error) {
 
 	o := scorecard.Scorecard{
 		SkipCleanup: c.skipCleanup,
+		Timings:     &scorecard.Timings{},
 	}
 
 	configPath := c.config
@@ -141,10 +201,18 @@ func (c *scorecardCmd) run() (err error) {
 		}
 		configPath = filepath.Join(c.bundle, configDir, scorecard.ConfigFileName)
 	}
-	o.Config, err = scorecard.LoadConfig(configPath)
+	config, err := scorecard.LoadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("could not find config file %w", err)
 	}
+	o.Config = config.Configuration
+	o.TestOptions = config.TestOptions
+	flagPodOptions, err := c.podOptions()
+	if err != nil {
+		return err
+	}
+	podOptions := config.PodOptions.Merge(flagPodOptions)
+	testPodOptions := config.TestPodOptions
 
 	o.Selector, err = labels.Parse(c.selector)
 	if err != nil {
@@ -155,21 +223,10 @@ func (c *scorecardCmd) run() (err error) {
 	if c.list {
 		scorecardTests = o.List()
 	} else {
-		runner := scorecard.PodTestRunner{
-			ServiceAccount: c.serviceAccount,
-			Namespace:      scorecard.GetKubeNamespace(c.kubeconfig, c.namespace),
-			BundlePath:     c.bundle,
-			TestOutput:     c.testOutput,
-			BundleMetadata: metadata,
+		if o.TestRunner, err = c.newTestRunner(metadata, podOptions, testPodOptions); err != nil {
+			return err
 		}
 
-		// Only get the client if running tests.
-		if runner.Client, runner.RESTConfig, err = scorecard.GetKubeClient(c.kubeconfig); err != nil {
-			return fmt.Errorf("error getting kubernetes client: %w", err)
-		}
-
-		o.TestRunner = &runner
-
 		ctx, cancel := context.WithTimeout(context.Background(), c.waitTime)
 		defer cancel()
 
@@ -179,16 +236,72 @@ func (c *scorecardCmd) run() (err error) {
 		}
 	}
 
-	if err := c.printOutput(scorecardTests); err != nil {
+	if err := c.printOutput(scorecardTests, o.Timings); err != nil {
 		log.Fatal(err)
 	}
 
-	if hasFailingTest(scorecardTests) {
+	failed := hasFailingTest(scorecardTests)
+	if c.baseline != "" && !c.list {
+		baseline, err := scorecard.LoadBaseline(c.baseline)
+		if err != nil {
+			log.Fatal(err)
+		}
+		comparison := scorecard.Compare(baseline, scorecardTests, o.Timings)
+		// Keep stdout parseable for the json and junit formats.
+		out := os.Stdout
+		if c.outputFormat != "text" {
+			out = os.Stderr
+		}
+		fmt.Fprint(out, comparison.MarshalText())
+		if c.failOn == failOnRegression {
+			failed = comparison.HasRegressions()
+		}
+	}
+
+	if failed {
 		os.Exit(1)
 	}
 	return nil
 }
 
+// newTestRunner returns the test runner selected by --runner.
+func (c *scorecardCmd) newTestRunner(metadata registryutil.Labels, podOptions scorecard.PodOptions,
+	testPodOptions map[string]scorecard.PodOptions) (scorecard.TestRunner, error) {
+	if c.runner == runnerLocal {
+		return &scorecard.LocalTestRunner{
+			BundlePath:     c.bundle,
+			BundleMetadata: metadata,
+		}, nil
+	}
+
+	runner := scorecard.PodTestRunner{
+		ServiceAccount: c.serviceAccount,
+		Namespace:      scorecard.GetKubeNamespace(c.kubeconfig, c.namespace),
+		BundlePath:     c.bundle,
+		TestOutput:     c.testOutput,
+		BundleMetadata: metadata,
+		PodOptions:     podOptions,
+		TestPodOptions: testPodOptions,
+		BundleImage:    c.bundleImage,
+		BundleTransfer: scorecard.BundleTransfer(c.bundleTransfer),
+	}
+	if c.testOutputSize != "" {
+		// The quantity is checked by validate.
+		limit := resource.MustParse(c.testOutputSize)
+		runner.ArtifactsSizeLimit = limit.Value()
+	}
+	if viper.GetBool(flags.VerboseOpt) {
+		runner.LogOutput = os.Stderr
+	}
+
+	// Only get the client if running tests.
+	var err error
+	if runner.Client, runner.RESTConfig, err = scorecard.GetKubeClient(c.kubeconfig); err != nil {
+		return nil, fmt.Errorf("error getting kubernetes client: %w", err)
+	}
+	return &runner, nil
+}
+
 func hasFailingTest(list v1alpha3.TestList) bool {
 	for _, t := range list.Items {
 		for _, r := range t.Status.Results {
@@ -204,9 +317,82 @@ func (c *scorecardCmd) validate(args []string) error {
 	if len(args) != 1 {
 		return fmt.Errorf("a bundle image or directory argument is required")
 	}
+	if c.runner != "" && c.runner != runnerPod && c.runner != runnerLocal {
+		return fmt.Errorf("invalid runner %q, valid values are %s and %s", c.runner, runnerPod, runnerLocal)
+	}
+	if c.failOn != "" && c.failOn != failOnAny && c.failOn != failOnRegression {
+		return fmt.Errorf("invalid --fail-on value %q, valid values are %s and %s", c.failOn, failOnAny,
+			failOnRegression)
+	}
+	if c.failOn == failOnRegression && c.baseline == "" {
+		return fmt.Errorf("--fail-on %s requires --baseline", failOnRegression)
+	}
+	if c.podSecurityContext != "" && c.podSecurityContext != scorecard.RestrictedSecurityContext {
+		return fmt.Errorf("invalid pod security context %q, valid values are %s", c.podSecurityContext,
+			scorecard.RestrictedSecurityContext)
+	}
+	if c.testOutputSize != "" {
+		if _, err := resource.ParseQuantity(c.testOutputSize); err != nil {
+			return fmt.Errorf("invalid --test-output-size-limit %q: %v", c.testOutputSize, err)
+		}
+	}
+	if c.bundleTransfer != "" {
+		if _, err := scorecard.ParseBundleTransfer(c.bundleTransfer); err != nil {
+			return err
+		}
+	}
+	if c.list && c.outputFormat == "junit" {
+		return fmt.Errorf("output format junit cannot be used with --list")
+	}
 	return nil
 }
 
+// podOptions returns the pod options set by flags.
+func (c *scorecardCmd) podOptions() (opts scorecard.PodOptions, err error) {
+	if c.podSecurityContext == scorecard.RestrictedSecurityContext {
+		opts = scorecard.RestrictedPodOptions()
+	}
+	if len(c.podRequests) != 0 || len(c.podLimits) != 0 {
+		opts.Resources = &corev1.ResourceRequirements{}
+		if opts.Resources.Requests, err = parseResourceList(c.podRequests); err != nil {
+			return opts, fmt.Errorf("invalid --pod-requests: %w", err)
+		}
+		if opts.Resources.Limits, err = parseResourceList(c.podLimits); err != nil {
+			return opts, fmt.Errorf("invalid --pod-limits: %w", err)
+		}
+	}
+	if len(c.podNodeSelector) != 0 {
+		opts.NodeSelector = c.podNodeSelector
+	}
+	for _, t := range c.podTolerations {
+		toleration, err := k8sutil.ParseToleration(t)
+		if err != nil {
+			return opts, err
+		}
+		opts.Tolerations = append(opts.Tolerations, toleration)
+	}
+	if len(c.podPullSecrets) != 0 {
+		opts.ImagePullSecrets = c.podPullSecrets
+	}
+	opts.UntarImage = c.untarImage
+	return opts, nil
+}
+
+func parseResourceList(values map[string]string) (corev1.ResourceList, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	list := corev1.ResourceList{}
+	for name, value := range values {
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q for %s: %w", value, name, err)
+		}
+		list[corev1.ResourceName(name)] = q
+	}
+	return list, nil
+}
+
 // extractBundleImage returns bundleImage's path on disk post-extraction.
 func extractBundleImage(bundleImage string) (string, error) {
 	// Discard bundle extraction logs unless user sets verbose mode.
@@ -65,6 +65,37 @@ var _ = Describe("Running the scorecard command", func() {
 			Expect(flag).NotTo(BeNil())
 			Expect(flag.Shorthand).To(Equal("w"))
 			Expect(flag.DefValue).To(Equal("30s"))
+
+			flag = cmd.Flags().Lookup("runner")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("pod"))
+		})
+	})
+
+	Describe("podOptions", func() {
+		It("builds pod options from flags", func() {
+			cmd := scorecardCmd{
+				podRequests:        map[string]string{"cpu": "100m"},
+				podNodeSelector:    map[string]string{"pool": "ci"},
+				podTolerations:     []string{"dedicated=ci:NoSchedule"},
+				podPullSecrets:     []string{"registry"},
+				podSecurityContext: "restricted",
+				untarImage:         "mirror/busybox",
+			}
+			opts, err := cmd.podOptions()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.Resources.Requests.Cpu().String()).To(Equal("100m"))
+			Expect(opts.Resources.Limits).To(BeNil())
+			Expect(opts.NodeSelector).To(Equal(map[string]string{"pool": "ci"}))
+			Expect(opts.Tolerations).To(HaveLen(1))
+			Expect(opts.ImagePullSecrets).To(Equal([]string{"registry"}))
+			Expect(*opts.PodSecurityContext.RunAsNonRoot).To(BeTrue())
+			Expect(opts.UntarImage).To(Equal("mirror/busybox"))
+		})
+		It("fails for invalid quantities", func() {
+			cmd := scorecardCmd{podLimits: map[string]string{"memory": "lots"}}
+			_, err := cmd.podOptions()
+			Expect(err).To(MatchError(ContainSubstring("invalid --pod-limits")))
 		})
 	})
 
@@ -73,6 +104,30 @@ var _ = Describe("Running the scorecard command", func() {
 		BeforeEach(func() {
 			cmd = scorecardCmd{}
 		})
+		It("fails for an unknown --fail-on value", func() {
+			cmd.failOn = "sometimes"
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError(ContainSubstring("invalid --fail-on value")))
+		})
+		It("fails if --fail-on regression is used without a baseline", func() {
+			cmd.failOn = "regression"
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError("--fail-on regression requires --baseline"))
+			cmd.baseline = "previous.json"
+			Expect(cmd.validate([]string{"cherry"})).To(Succeed())
+		})
+		It("fails for an unknown pod security context", func() {
+			cmd.podSecurityContext = "baseline"
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError(ContainSubstring("invalid pod security context")))
+		})
+		It("fails for an unknown bundle transfer mode", func() {
+			cmd.bundleTransfer = "ftp"
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError(ContainSubstring("invalid bundle transfer mode")))
+			cmd.bundleTransfer = "pvc"
+			Expect(cmd.validate([]string{"cherry"})).To(Succeed())
+		})
+		It("fails for an invalid test output size limit", func() {
+			cmd.testOutputSize = "big"
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError(ContainSubstring("invalid --test-output-size-limit")))
+		})
 		It("fails if anything other than exactly one arg is provided", func() {
 			err := cmd.validate([]string{})
 			Expect(err).To(HaveOccurred())
@@ -86,5 +141,22 @@ var _ = Describe("Running the scorecard command", func() {
 			err := cmd.validate([]string{input})
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("fails if an unknown runner is provided", func() {
+			cmd.runner = "remote"
+			err := cmd.validate([]string{"cherry"})
+			Expect(err).To(HaveOccurred())
+
+			cmd.runner = runnerLocal
+			err = cmd.validate([]string{"cherry"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fails if junit output is requested with --list", func() {
+			cmd.list = true
+			cmd.outputFormat = "junit"
+			err := cmd.validate([]string{"cherry"})
+			Expect(err).To(HaveOccurred())
+		})
 	})
 })
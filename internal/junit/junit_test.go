@@ -0,0 +1,67 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package junit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	r := &TestSuites{Name: "example"}
+	r.AddSuite(TestSuite{
+		Name: "suite-a",
+		Time: Duration(1500 * time.Millisecond),
+		TestCases: []TestCase{
+			{Name: "passes", Time: Duration(time.Second), SystemOut: "some log"},
+			{Name: "fails", Failure: &Result{Message: "bad", Contents: "a < b"}},
+			{Name: "errors", Error: &Result{Message: "broken"}},
+		},
+	})
+	r.AddSuite(TestSuite{
+		Name:      "suite-b",
+		Time:      Duration(500 * time.Millisecond),
+		TestCases: []TestCase{{Name: "skipped", Skipped: &Result{Message: "not run"}}},
+	})
+
+	buf := &bytes.Buffer{}
+	if err := r.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="example" tests="4" failures="1" errors="1" skipped="1" time="2.000">
+  <testsuite name="suite-a" tests="3" failures="1" errors="1" skipped="0" time="1.500">
+    <testcase name="passes" time="1.000">
+      <system-out>some log</system-out>
+    </testcase>
+    <testcase name="fails" time="0.000">
+      <failure message="bad">a &lt; b</failure>
+    </testcase>
+    <testcase name="errors" time="0.000">
+      <error message="broken"></error>
+    </testcase>
+  </testsuite>
+  <testsuite name="suite-b" tests="1" failures="0" errors="0" skipped="1" time="0.500">
+    <testcase name="skipped" time="0.000">
+      <skipped message="not run"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected report:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
@@ -22,7 +22,9 @@ import (
 	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"k8s.io/apimachinery/pkg/types"
 
@@ -258,4 +260,16 @@ var _ = Describe("Client", func() {
 			})
 		})
 	})
+
+	Describe("Status.AllInstalled", func() {
+		installed := ResourceStatus{Resource: &unstructured.Unstructured{}}
+		missing := ResourceStatus{Error: apierrors.NewNotFound(corev1.Resource("pods"), "olm-operator")}
+
+		It("returns true if all resources are installed", func() {
+			Expect(Status{Resources: []ResourceStatus{installed, installed}}.AllInstalled()).To(BeTrue())
+		})
+		It("returns false if a resource is not installed", func() {
+			Expect(Status{Resources: []ResourceStatus{installed, missing}}.AllInstalled()).To(BeFalse())
+		})
+	})
 })
@@ -110,6 +110,16 @@ func (s Status) HasInstalledResources() (bool, error) {
 	return false, apiutilerrors.NewAggregate(errs)
 }
 
+// AllInstalled returns true if every resource in s was returned by the API server.
+func (s Status) AllInstalled() bool {
+	for _, r := range s.Resources {
+		if r.Resource == nil || r.Error != nil {
+			return false
+		}
+	}
+	return true
+}
+
 // getCRDKindSet returns the set of all kinds specified by all CRDs in s.
 func (s Status) getCRDKindSet() (sets.String, error) {
 	crdKindSet := sets.NewString()
@@ -151,9 +161,9 @@ func (s Status) String() string {
 		if r.Error != nil {
 			status = r.Error.Error()
 		} else if r.Resource != nil {
-			status = "Installed"
+			status = ResourceInstalled
 		} else {
-			status = "Unknown"
+			status = ResourceUnknown
 		}
 		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", nn.Name, nn.Namespace, kind, status)
 	}
@@ -161,3 +171,53 @@ func (s Status) String() string {
 
 	return out.String()
 }
+
+// Statuses of resources in a StatusReport.
+const (
+	ResourceInstalled = "Installed"
+	ResourceNotFound  = "NotFound"
+	ResourceError     = "Error"
+	ResourceUnknown   = "Unknown"
+)
+
+// StatusReport is the status of an OLM installation in a form that can be
+// marshaled to JSON or YAML.
+type StatusReport struct {
+	OLMVersion string                 `json:"olmVersion"`
+	Resources  []ResourceStatusReport `json:"resources"`
+}
+
+// ResourceStatusReport is the status of a resource in a StatusReport.
+type ResourceStatusReport struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report returns the status of each resource in s, for OLM version olmVersion.
+func (s Status) Report(olmVersion string) StatusReport {
+	report := StatusReport{OLMVersion: olmVersion, Resources: []ResourceStatusReport{}}
+	for _, r := range s.Resources {
+		rr := ResourceStatusReport{
+			Name:       r.NamespacedName.Name,
+			Namespace:  r.NamespacedName.Namespace,
+			APIVersion: r.GVK.GroupVersion().String(),
+			Kind:       r.GVK.Kind,
+		}
+		switch {
+		case r.Error != nil && apierrors.IsNotFound(r.Error):
+			rr.Status, rr.Error = ResourceNotFound, r.Error.Error()
+		case r.Error != nil:
+			rr.Status, rr.Error = ResourceError, r.Error.Error()
+		case r.Resource != nil:
+			rr.Status = ResourceInstalled
+		default:
+			rr.Status = ResourceUnknown
+		}
+		report.Resources = append(report.Resources, rr)
+	}
+	return report
+}
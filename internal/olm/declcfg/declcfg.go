@@ -0,0 +1,155 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package declcfg renders, loads and validates file-based catalogs, in which
+// packages, channels and bundles are declared as plain JSON or YAML objects
+// that `opm serve` serves to OLM.
+package declcfg
+
+import (
+	"encoding/json"
+)
+
+// Schemas of file-based catalog objects.
+const (
+	SchemaPackage = "olm.package"
+	SchemaChannel = "olm.channel"
+	SchemaBundle  = "olm.bundle"
+)
+
+// Types of bundle properties.
+const (
+	PropertyPackage         = "olm.package"
+	PropertyGVK             = "olm.gvk"
+	PropertyPackageRequired = "olm.package.required"
+	PropertyGVKRequired     = "olm.gvk.required"
+	PropertyBundleObject    = "olm.bundle.object"
+)
+
+// DeclarativeConfig is a file-based catalog.
+type DeclarativeConfig struct {
+	Packages []Package
+	Channels []Channel
+	Bundles  []Bundle
+	// Others are objects of other schemas, kept as-is.
+	Others []json.RawMessage
+}
+
+// Package is an operator package.
+type Package struct {
+	Schema         string `json:"schema"`
+	Name           string `json:"name"`
+	DefaultChannel string `json:"defaultChannel"`
+	Description    string `json:"description,omitempty"`
+}
+
+// Channel is an upgrade graph of a package's bundles.
+type Channel struct {
+	Schema  string         `json:"schema"`
+	Name    string         `json:"name"`
+	Package string         `json:"package"`
+	Entries []ChannelEntry `json:"entries"`
+}
+
+// ChannelEntry is a bundle in a channel and the bundles it upgrades from.
+type ChannelEntry struct {
+	Name      string   `json:"name"`
+	Replaces  string   `json:"replaces,omitempty"`
+	Skips     []string `json:"skips,omitempty"`
+	SkipRange string   `json:"skipRange,omitempty"`
+}
+
+// Bundle is a version of an operator, pulled by OLM from its image.
+type Bundle struct {
+	Schema        string         `json:"schema"`
+	Name          string         `json:"name"`
+	Package       string         `json:"package"`
+	Image         string         `json:"image"`
+	Properties    []Property     `json:"properties,omitempty"`
+	RelatedImages []RelatedImage `json:"relatedImages,omitempty"`
+}
+
+// Property is a typed property of a bundle.
+type Property struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// PackageProperty is the value of a bundle's olm.package property.
+type PackageProperty struct {
+	PackageName string `json:"packageName"`
+	Version     string `json:"version"`
+}
+
+// GVKProperty is the value of olm.gvk and olm.gvk.required properties.
+type GVKProperty struct {
+	Group   string `json:"group"`
+	Kind    string `json:"kind"`
+	Version string `json:"version"`
+}
+
+// PackageRequiredProperty is the value of an olm.package.required property.
+type PackageRequiredProperty struct {
+	PackageName  string `json:"packageName"`
+	VersionRange string `json:"versionRange"`
+}
+
+// BundleObjectProperty is the value of an olm.bundle.object property, a
+// manifest of the bundle encoded as base64 JSON.
+type BundleObjectProperty struct {
+	Data []byte `json:"data"`
+}
+
+// RelatedImage is an image used by a bundle.
+type RelatedImage struct {
+	Name  string `json:"name,omitempty"`
+	Image string `json:"image"`
+}
+
+// newProperty returns a property of type typ with value v.
+func newProperty(typ string, v interface{}) (Property, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return Property{}, err
+	}
+	return Property{Type: typ, Value: b}, nil
+}
+
+// PackageProperty returns the olm.package property of b.
+func (b Bundle) PackageProperty() (PackageProperty, bool, error) {
+	p := PackageProperty{}
+	for _, prop := range b.Properties {
+		if prop.Type == PropertyPackage {
+			err := json.Unmarshal(prop.Value, &p)
+			return p, true, err
+		}
+	}
+	return p, false, nil
+}
+
+// Objects returns the manifests in the olm.bundle.object properties of b.
+func (b Bundle) Objects() ([][]byte, error) {
+	var objs [][]byte
+	for _, prop := range b.Properties {
+		if prop.Type != PropertyBundleObject {
+			continue
+		}
+		obj := BundleObjectProperty{}
+		if err := json.Unmarshal(prop.Value, &obj); err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj.Data)
+	}
+	return objs, nil
+}
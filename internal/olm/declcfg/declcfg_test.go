@@ -0,0 +1,211 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declcfg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+const testBundleDir = "../../scorecard/testdata/bundle"
+
+func loadTestBundle() *apimanifests.Bundle {
+	b, err := operator.LoadBundleRef(context.TODO(), testBundleDir)
+	Expect(err).NotTo(HaveOccurred())
+	b.BundleImage = "quay.io/example/memcached-operator-bundle:v0.0.1"
+	return b
+}
+
+func rawJSON(s string) json.RawMessage {
+	return json.RawMessage(s)
+}
+
+var _ = Describe("Render", func() {
+	It("renders a package, its channels and bundles", func() {
+		cfg, err := Render([]*apimanifests.Bundle{loadTestBundle()})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(Validate(cfg)).To(Succeed())
+
+		Expect(cfg.Packages).To(Equal([]Package{
+			{Schema: SchemaPackage, Name: "memcached-operator", DefaultChannel: "stable"},
+		}))
+		Expect(cfg.Channels).To(Equal([]Channel{
+			{Schema: SchemaChannel, Name: "alpha", Package: "memcached-operator",
+				Entries: []ChannelEntry{{Name: "memcached-operator.v0.0.1"}}},
+			{Schema: SchemaChannel, Name: "stable", Package: "memcached-operator",
+				Entries: []ChannelEntry{{Name: "memcached-operator.v0.0.1"}}},
+		}))
+
+		Expect(cfg.Bundles).To(HaveLen(1))
+		b := cfg.Bundles[0]
+		Expect(b.Name).To(Equal("memcached-operator.v0.0.1"))
+		Expect(b.Image).To(Equal("quay.io/example/memcached-operator-bundle:v0.0.1"))
+		Expect(b.Properties[0]).To(Equal(Property{Type: PropertyPackage,
+			Value: rawJSON(`{"packageName":"memcached-operator","version":"0.0.1"}`)}))
+		Expect(b.Properties).To(ContainElement(Property{Type: PropertyGVK,
+			Value: rawJSON(`{"group":"cache.example.com","kind":"Memcached","version":"v1alpha1"}`)}))
+		objs, err := b.Objects()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(2))
+		Expect(b.RelatedImages[0]).To(Equal(RelatedImage{Image: b.Image}))
+	})
+
+	It("renders channel entries from replaces, skips and skipRange", func() {
+		v1, v2 := loadTestBundle(), loadTestBundle()
+		v2.CSV = v1.CSV.DeepCopy()
+		v2.CSV.SetName("memcached-operator.v0.0.2")
+		v2.CSV.Spec.Version.Version.Patch = 2
+		v2.CSV.Spec.Replaces = "memcached-operator.v0.0.1"
+		v2.CSV.SetAnnotations(map[string]string{"olm.skipRange": "<0.0.2"})
+		v2.Channels = []string{"stable"}
+
+		cfg, err := Render([]*apimanifests.Bundle{v2, v1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(Validate(cfg)).To(Succeed())
+		Expect(cfg.Channels[1].Entries).To(Equal([]ChannelEntry{
+			{Name: "memcached-operator.v0.0.1"},
+			{Name: "memcached-operator.v0.0.2", Replaces: "memcached-operator.v0.0.1", SkipRange: "<0.0.2"},
+		}))
+		head, err := cfg.ChannelHead("memcached-operator", "stable")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(head).To(Equal("memcached-operator.v0.0.2"))
+	})
+})
+
+var _ = Describe("LoadDir", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "catalog")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("loads rendered JSON and YAML catalogs", func() {
+		cfg, err := Render([]*apimanifests.Bundle{loadTestBundle()})
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, write := range []func(*DeclarativeConfig, *bytes.Buffer) error{
+			func(cfg *DeclarativeConfig, buf *bytes.Buffer) error { return WriteJSON(cfg, buf) },
+			func(cfg *DeclarativeConfig, buf *bytes.Buffer) error { return WriteYAML(cfg, buf) },
+		} {
+			buf := &bytes.Buffer{}
+			Expect(write(cfg, buf)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "memcached-operator"), 0755)).To(Succeed())
+			path := filepath.Join(dir, "memcached-operator", "index.yaml")
+			Expect(ioutil.WriteFile(path, buf.Bytes(), 0644)).To(Succeed())
+
+			loaded, err := LoadDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(Validate(loaded)).To(Succeed())
+			expected, actual := &bytes.Buffer{}, &bytes.Buffer{}
+			Expect(WriteJSON(cfg, expected)).To(Succeed())
+			Expect(WriteJSON(loaded, actual)).To(Succeed())
+			Expect(actual.String()).To(Equal(expected.String()))
+			Expect(IsCatalogDir(dir)).To(BeTrue())
+		}
+	})
+
+	It("loads a catalog split into several files", func() {
+		cfg, err := Render([]*apimanifests.Bundle{loadTestBundle()})
+		Expect(err).NotTo(HaveOccurred())
+		whole := &bytes.Buffer{}
+		Expect(WriteJSON(cfg, whole)).To(Succeed())
+
+		// The catalog cannot fit in a single chunk smaller than itself.
+		chunks, err := SplitJSON(cfg, whole.Len()-1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(chunks)).To(BeNumerically(">", 1))
+		for i, chunk := range chunks {
+			Expect(len(chunk)).To(BeNumerically("<", whole.Len()))
+			path := filepath.Join(dir, fmt.Sprintf("catalog-%d.json", i))
+			Expect(ioutil.WriteFile(path, chunk, 0644)).To(Succeed())
+		}
+
+		loaded, err := LoadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		actual := &bytes.Buffer{}
+		Expect(WriteJSON(loaded, actual)).To(Succeed())
+		Expect(actual.String()).To(Equal(whole.String()))
+	})
+
+	It("does not split objects larger than the chunk size", func() {
+		cfg, err := Render([]*apimanifests.Bundle{loadTestBundle()})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = SplitJSON(cfg, 100)
+		Expect(err).To(MatchError(ContainSubstring(`more than the limit of 100 bytes`)))
+	})
+
+	It("keeps objects of other schemas", func() {
+		catalog := `{"schema": "olm.package", "name": "foo", "defaultChannel": "alpha"}
+{"schema": "olm.deprecations", "package": "foo"}
+`
+		Expect(ioutil.WriteFile(filepath.Join(dir, "index.json"), []byte(catalog), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# foo"), 0644)).To(Succeed())
+		cfg, err := LoadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Packages).To(HaveLen(1))
+		Expect(cfg.Others).To(HaveLen(1))
+	})
+
+	It("fails on objects without a schema", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "index.json"), []byte(`{"name": "foo"}`), 0644)).To(Succeed())
+		_, err := LoadDir(dir)
+		Expect(err).To(MatchError(ContainSubstring("object has no schema")))
+		Expect(IsCatalogDir(dir)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Validate", func() {
+	It("reports invalid objects and upgrade graphs", func() {
+		pkgProperty := func(version string) []Property {
+			return []Property{{Type: PropertyPackage, Value: rawJSON(`{"packageName":"foo","version":"` + version + `"}`)}}
+		}
+		cfg := &DeclarativeConfig{
+			Packages: []Package{{Schema: SchemaPackage, Name: "foo", DefaultChannel: "stable"}},
+			Channels: []Channel{
+				{Schema: SchemaChannel, Name: "alpha", Package: "foo", Entries: []ChannelEntry{
+					{Name: "foo.v0.1.0"}, {Name: "foo.v0.2.0"}, {Name: "foo.v0.3.0"},
+				}},
+				{Schema: SchemaChannel, Name: "beta", Package: "bar"},
+			},
+			Bundles: []Bundle{
+				{Schema: SchemaBundle, Name: "foo.v0.1.0", Package: "foo", Image: "foo:v0.1.0", Properties: pkgProperty("0.1.0")},
+				{Schema: SchemaBundle, Name: "foo.v0.2.0", Package: "foo", Properties: pkgProperty("0.2.0")},
+			},
+		}
+		err := Validate(cfg)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("bundle foo.v0.2.0: image must be set"))
+		Expect(err.Error()).To(ContainSubstring(`channel beta: unknown package "bar"`))
+		Expect(err.Error()).To(ContainSubstring("package foo channel alpha: entry foo.v0.3.0 is not a bundle of the package"))
+		Expect(err.Error()).To(ContainSubstring("package foo: default channel stable does not exist"))
+		Expect(err.Error()).To(ContainSubstring("package foo channel alpha: channel has multiple heads: foo.v0.1.0, foo.v0.2.0"))
+	})
+})
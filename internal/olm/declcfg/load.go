@@ -0,0 +1,212 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declcfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// LoadDir loads the file-based catalog in the JSON and YAML files of dir
+// and its subdirectories, as `opm serve` does.
+func LoadDir(dir string) (*DeclarativeConfig, error) {
+	cfg := &DeclarativeConfig{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !isCatalogFile(path) {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := cfg.load(f); err != nil {
+			return fmt.Errorf("error loading %s: %v", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// IsCatalogDir returns true if dir contains a file-based catalog package.
+func IsCatalogDir(dir string) bool {
+	cfg, err := LoadDir(dir)
+	return err == nil && len(cfg.Packages) != 0
+}
+
+func isCatalogFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// load decodes a stream of JSON or YAML catalog objects from r into cfg.
+func (cfg *DeclarativeConfig) load(r io.Reader) error {
+	dec := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(raw)) == 0 || bytes.Equal(raw, []byte("null")) {
+			continue
+		}
+
+		meta := struct {
+			Schema string `json:"schema"`
+		}{}
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return err
+		}
+		var err error
+		switch meta.Schema {
+		case SchemaPackage:
+			p := Package{}
+			if err = json.Unmarshal(raw, &p); err == nil {
+				cfg.Packages = append(cfg.Packages, p)
+			}
+		case SchemaChannel:
+			c := Channel{}
+			if err = json.Unmarshal(raw, &c); err == nil {
+				cfg.Channels = append(cfg.Channels, c)
+			}
+		case SchemaBundle:
+			b := Bundle{}
+			if err = json.Unmarshal(raw, &b); err == nil {
+				cfg.Bundles = append(cfg.Bundles, b)
+			}
+		case "":
+			err = errors.New("object has no schema")
+		default:
+			cfg.Others = append(cfg.Others, raw)
+		}
+		if err != nil {
+			return fmt.Errorf("error decoding %s object: %v", meta.Schema, err)
+		}
+	}
+}
+
+// WriteJSON writes the objects of cfg to w as a stream of JSON objects,
+// grouped by package.
+func WriteJSON(cfg *DeclarativeConfig, w io.Writer) error {
+	return cfg.write(func(obj interface{}) error {
+		b, err := json.MarshalIndent(obj, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	})
+}
+
+// SplitJSON encodes the objects of cfg as WriteJSON does, split into streams of
+// at most maxSize bytes, so that a catalog can be stored in several ConfigMaps.
+// An error is returned if a single object is larger than maxSize.
+func SplitJSON(cfg *DeclarativeConfig, maxSize int) ([][]byte, error) {
+	var (
+		chunks [][]byte
+		buf    bytes.Buffer
+	)
+	err := cfg.write(func(obj interface{}) error {
+		b, err := json.MarshalIndent(obj, "", "    ")
+		if err != nil {
+			return err
+		}
+		b = append(b, '\n')
+		if len(b) > maxSize {
+			meta := struct {
+				Schema string `json:"schema"`
+				Name   string `json:"name"`
+			}{}
+			_ = json.Unmarshal(b, &meta)
+			return fmt.Errorf("%s object %q is %d bytes, more than the limit of %d bytes", meta.Schema, meta.Name, len(b), maxSize)
+		}
+		if buf.Len()+len(b) > maxSize {
+			chunks = append(chunks, append([]byte(nil), buf.Bytes()...))
+			buf.Reset()
+		}
+		buf.Write(b)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 0 {
+		chunks = append(chunks, buf.Bytes())
+	}
+	return chunks, nil
+}
+
+// WriteYAML writes the objects of cfg to w as a stream of YAML documents,
+// grouped by package.
+func WriteYAML(cfg *DeclarativeConfig, w io.Writer) error {
+	return cfg.write(func(obj interface{}) error {
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "---\n%s", b)
+		return err
+	})
+}
+
+func (cfg *DeclarativeConfig) write(writeObj func(interface{}) error) error {
+	packages := append([]Package(nil), cfg.Packages...)
+	sort.SliceStable(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+	for _, p := range packages {
+		if err := writeObj(p); err != nil {
+			return err
+		}
+		for _, c := range cfg.Channels {
+			if c.Package == p.Name {
+				if err := writeObj(c); err != nil {
+					return err
+				}
+			}
+		}
+		for _, b := range cfg.Bundles {
+			if b.Package == p.Name {
+				if err := writeObj(b); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, o := range cfg.Others {
+		if err := writeObj(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declcfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/graph"
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+// Render returns the file-based catalog of bundles, with a package for each
+// of their packages and the upgrade graph of each of their channels. The
+// Package, Channels, DefaultChannel and BundleImage fields of each bundle
+// must be set. The default channel of a package is that of its latest bundle.
+func Render(bundles []*apimanifests.Bundle) (*DeclarativeConfig, error) {
+	nodes := make([]graph.Bundle, 0, len(bundles))
+	for _, b := range bundles {
+		node, err := graph.NewBundle(b, b.Package, b.Channels, b.DefaultChannel)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	// Render bundles ordered by package and version.
+	order := make([]int, len(bundles))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := nodes[order[i]], nodes[order[j]]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Version.LT(b.Version)
+	})
+
+	cfg := &DeclarativeConfig{}
+	packages := map[string]int{}
+	channels := map[[2]string]int{}
+	for _, i := range order {
+		b, node := bundles[i], nodes[i]
+		if node.Package == "" {
+			return nil, fmt.Errorf("bundle %s has no package", node.Name)
+		}
+
+		pi, ok := packages[node.Package]
+		if !ok {
+			pi = len(cfg.Packages)
+			packages[node.Package] = pi
+			cfg.Packages = append(cfg.Packages, Package{Schema: SchemaPackage, Name: node.Package})
+		}
+		if node.DefaultChannel != "" {
+			cfg.Packages[pi].DefaultChannel = node.DefaultChannel
+		}
+
+		for _, name := range node.Channels {
+			key := [2]string{node.Package, name}
+			ci, ok := channels[key]
+			if !ok {
+				ci = len(cfg.Channels)
+				channels[key] = ci
+				cfg.Channels = append(cfg.Channels, Channel{Schema: SchemaChannel, Name: name, Package: node.Package})
+			}
+			cfg.Channels[ci].Entries = append(cfg.Channels[ci].Entries, ChannelEntry{
+				Name:      node.Name,
+				Replaces:  node.Replaces,
+				Skips:     node.Skips,
+				SkipRange: node.SkipRange,
+			})
+		}
+
+		bundle, err := renderBundle(b, node)
+		if err != nil {
+			return nil, fmt.Errorf("error rendering bundle %s: %v", node.Name, err)
+		}
+		cfg.Bundles = append(cfg.Bundles, bundle)
+	}
+
+	// A package without a default channel defaults to its only channel.
+	for i, p := range cfg.Packages {
+		if p.DefaultChannel != "" {
+			continue
+		}
+		var names []string
+		for _, c := range cfg.Channels {
+			if c.Package == p.Name {
+				names = append(names, c.Name)
+			}
+		}
+		if len(names) == 1 {
+			cfg.Packages[i].DefaultChannel = names[0]
+		}
+	}
+	return cfg, nil
+}
+
+// renderBundle returns the olm.bundle object of b, with properties for its
+// package and version, the APIs it provides and requires, its dependencies,
+// and each of its manifests.
+func renderBundle(b *apimanifests.Bundle, node graph.Bundle) (Bundle, error) {
+	bundle := Bundle{Schema: SchemaBundle, Name: node.Name, Package: node.Package, Image: b.BundleImage}
+	var err error
+	addProperty := func(typ string, value interface{}) {
+		if err != nil {
+			return
+		}
+		var p Property
+		if p, err = newProperty(typ, value); err == nil {
+			bundle.Properties = append(bundle.Properties, p)
+		}
+	}
+
+	addProperty(PropertyPackage, PackageProperty{PackageName: node.Package, Version: node.Version.String()})
+	csv := b.CSV
+	for _, crd := range csv.Spec.CustomResourceDefinitions.Owned {
+		addProperty(PropertyGVK, GVKProperty{Group: k8sutil.CRDGroup(crd.Name), Version: crd.Version, Kind: crd.Kind})
+	}
+	for _, api := range csv.Spec.APIServiceDefinitions.Owned {
+		addProperty(PropertyGVK, GVKProperty{Group: api.Group, Version: api.Version, Kind: api.Kind})
+	}
+	for _, crd := range csv.Spec.CustomResourceDefinitions.Required {
+		addProperty(PropertyGVKRequired, GVKProperty{Group: k8sutil.CRDGroup(crd.Name), Version: crd.Version, Kind: crd.Kind})
+	}
+	for _, api := range csv.Spec.APIServiceDefinitions.Required {
+		addProperty(PropertyGVKRequired, GVKProperty{Group: api.Group, Version: api.Version, Kind: api.Kind})
+	}
+	for _, d := range b.Dependencies {
+		switch d.Type {
+		case registry.PackageType:
+			dep := registry.PackageDependency{}
+			if err := json.Unmarshal([]byte(d.Value), &dep); err != nil {
+				return bundle, fmt.Errorf("invalid package dependency: %v", err)
+			}
+			addProperty(PropertyPackageRequired,
+				PackageRequiredProperty{PackageName: dep.PackageName, VersionRange: dep.Version})
+		case registry.GVKType:
+			dep := registry.GVKDependency{}
+			if err := json.Unmarshal([]byte(d.Value), &dep); err != nil {
+				return bundle, fmt.Errorf("invalid gvk dependency: %v", err)
+			}
+			addProperty(PropertyGVKRequired, GVKProperty{Group: dep.Group, Version: dep.Version, Kind: dep.Kind})
+		default:
+			return bundle, fmt.Errorf("unsupported dependency type %q", d.Type)
+		}
+	}
+	for _, obj := range b.Objects {
+		data, merr := obj.MarshalJSON()
+		if merr != nil {
+			return bundle, merr
+		}
+		addProperty(PropertyBundleObject, BundleObjectProperty{Data: data})
+	}
+	if err != nil {
+		return bundle, err
+	}
+
+	if b.BundleImage != "" {
+		bundle.RelatedImages = append(bundle.RelatedImages, RelatedImage{Image: b.BundleImage})
+	}
+	for _, dep := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		for _, c := range dep.Spec.Template.Spec.Containers {
+			bundle.RelatedImages = append(bundle.RelatedImages, RelatedImage{Name: c.Name, Image: c.Image})
+		}
+	}
+	return bundle, nil
+}
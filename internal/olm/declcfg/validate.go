@@ -0,0 +1,196 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declcfg
+
+import (
+	"fmt"
+
+	"github.com/blang/semver/v4"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/graph"
+)
+
+// Validate returns an error for each invalid object of cfg, and for each
+// error in the upgrade graphs of its channels, so that the catalog can be
+// served to OLM.
+func Validate(cfg *DeclarativeConfig) error {
+	var errs []error
+	addErr := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	packages := map[string]Package{}
+	for _, p := range cfg.Packages {
+		if p.Name == "" {
+			addErr("package has no name")
+			continue
+		}
+		if _, ok := packages[p.Name]; ok {
+			addErr("duplicate package %s", p.Name)
+		}
+		packages[p.Name] = p
+	}
+
+	// Bundles by package and name.
+	bundles := map[string]map[string]Bundle{}
+	versions := map[string]map[string]semver.Version{}
+	for _, b := range cfg.Bundles {
+		if _, ok := packages[b.Package]; !ok {
+			addErr("bundle %s: unknown package %q", b.Name, b.Package)
+			continue
+		}
+		if bundles[b.Package] == nil {
+			bundles[b.Package] = map[string]Bundle{}
+			versions[b.Package] = map[string]semver.Version{}
+		}
+		if _, ok := bundles[b.Package][b.Name]; ok {
+			addErr("duplicate bundle %s in package %s", b.Name, b.Package)
+		}
+		bundles[b.Package][b.Name] = b
+
+		objs, err := b.Objects()
+		if err != nil {
+			addErr("bundle %s: invalid %s property: %v", b.Name, PropertyBundleObject, err)
+		}
+		if b.Image == "" && len(objs) == 0 {
+			addErr("bundle %s: image must be set if the bundle has no %s properties", b.Name, PropertyBundleObject)
+		}
+		pkg, ok, err := b.PackageProperty()
+		switch {
+		case err != nil:
+			addErr("bundle %s: invalid %s property: %v", b.Name, PropertyPackage, err)
+		case !ok:
+			addErr("bundle %s: missing %s property", b.Name, PropertyPackage)
+		case pkg.PackageName != b.Package:
+			addErr("bundle %s: %s property has package %q, not %q", b.Name, PropertyPackage, pkg.PackageName, b.Package)
+		default:
+			v, err := semver.Parse(pkg.Version)
+			if err != nil {
+				addErr("bundle %s: invalid version %q: %v", b.Name, pkg.Version, err)
+			}
+			versions[b.Package][b.Name] = v
+		}
+	}
+
+	// Build an upgrade graph node for each bundle in each channel, since
+	// upgrade edges are specific to a channel.
+	var nodes []graph.Bundle
+	channels := map[string]map[string]bool{}
+	for _, c := range cfg.Channels {
+		if _, ok := packages[c.Package]; !ok {
+			addErr("channel %s: unknown package %q", c.Name, c.Package)
+			continue
+		}
+		if c.Name == "" {
+			addErr("package %s: channel has no name", c.Package)
+			continue
+		}
+		if channels[c.Package] == nil {
+			channels[c.Package] = map[string]bool{}
+		}
+		if channels[c.Package][c.Name] {
+			addErr("duplicate channel %s in package %s", c.Name, c.Package)
+			continue
+		}
+		channels[c.Package][c.Name] = true
+		if len(c.Entries) == 0 {
+			addErr("package %s channel %s: channel has no entries", c.Package, c.Name)
+		}
+
+		entries := map[string]bool{}
+		for _, e := range c.Entries {
+			if entries[e.Name] {
+				addErr("package %s channel %s: duplicate entry %s", c.Package, c.Name, e.Name)
+				continue
+			}
+			entries[e.Name] = true
+			if _, ok := bundles[c.Package][e.Name]; !ok {
+				addErr("package %s channel %s: entry %s is not a bundle of the package", c.Package, c.Name, e.Name)
+				continue
+			}
+			nodes = append(nodes, graph.Bundle{
+				Name:           e.Name,
+				Package:        c.Package,
+				Version:        versions[c.Package][e.Name],
+				Channels:       []string{c.Name},
+				DefaultChannel: packages[c.Package].DefaultChannel,
+				Replaces:       e.Replaces,
+				Skips:          e.Skips,
+				SkipRange:      e.SkipRange,
+			})
+		}
+	}
+
+	for _, p := range cfg.Packages {
+		if p.DefaultChannel == "" {
+			addErr("package %s: default channel must be set", p.Name)
+		} else if !channels[p.Name][p.DefaultChannel] {
+			addErr("package %s: default channel %s does not exist", p.Name, p.DefaultChannel)
+		}
+	}
+
+	for _, r := range graph.Analyze(nodes) {
+		for _, f := range r.Findings {
+			if f.Severity != graph.SeverityError {
+				continue
+			}
+			if f.Channel != "" {
+				addErr("package %s channel %s: %s", r.Package, f.Channel, f.Message)
+			} else {
+				addErr("package %s: %s", r.Package, f.Message)
+			}
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// ChannelHead returns the head of the channel named channel of package pkg,
+// the bundle all others in the channel upgrade to.
+func (cfg *DeclarativeConfig) ChannelHead(pkg, channel string) (string, error) {
+	var nodes []graph.Bundle
+	for _, c := range cfg.Channels {
+		if c.Package != pkg || c.Name != channel {
+			continue
+		}
+		for _, e := range c.Entries {
+			node := graph.Bundle{
+				Name:      e.Name,
+				Package:   pkg,
+				Channels:  []string{channel},
+				Replaces:  e.Replaces,
+				Skips:     e.Skips,
+				SkipRange: e.SkipRange,
+			}
+			for _, b := range cfg.Bundles {
+				if b.Package == pkg && b.Name == e.Name {
+					if p, ok, err := b.PackageProperty(); ok && err == nil {
+						node.Version, _ = semver.Parse(p.Version)
+					}
+				}
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	for _, r := range graph.Analyze(nodes) {
+		for _, c := range r.Channels {
+			if c.Head != "" {
+				return c.Head, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("channel %s of package %s has no head", channel, pkg)
+}
@@ -0,0 +1,361 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graph computes the upgrade graphs of an operator's channels from
+// the replaces, skips and olm.skipRange fields of its bundles, as OLM does,
+// and reports problems with them without a cluster.
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+// Bundle is a version of an operator in an upgrade graph.
+type Bundle struct {
+	// Name is the name of the bundle's CSV.
+	Name    string
+	Package string
+	Version semver.Version
+	// Channels are the channels the bundle is published in.
+	Channels       []string
+	DefaultChannel string
+	Replaces       string
+	Skips          []string
+	SkipRange      string
+	// CRDs are the CRDs owned by the bundle.
+	CRDs []CRD
+}
+
+// CRD is a CRD owned by a bundle.
+type CRD struct {
+	Name           string
+	Versions       []string
+	StorageVersion string
+}
+
+// Reasons of upgrade edges.
+const (
+	ReasonReplaces  = "replaces"
+	ReasonSkips     = "skips"
+	ReasonSkipRange = "skipRange"
+)
+
+// Edge is an upgrade from one bundle to another.
+type Edge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+// Severities of findings.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is a problem with an upgrade graph.
+type Finding struct {
+	Severity string `json:"severity"`
+	Channel  string `json:"channel,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Channel is the upgrade graph of a channel.
+type Channel struct {
+	Name string `json:"name"`
+	// Head is the version that all others upgrade to, empty if the channel
+	// does not have a single head.
+	Head string `json:"head,omitempty"`
+	// Bundles are the bundles of the channel ordered by version.
+	Bundles []string `json:"bundles"`
+	Edges   []Edge   `json:"edges"`
+}
+
+// Report is the result of analyzing the upgrade graphs of a package.
+type Report struct {
+	Package        string    `json:"package"`
+	DefaultChannel string    `json:"defaultChannel,omitempty"`
+	Channels       []Channel `json:"channels"`
+	Findings       []Finding `json:"findings,omitempty"`
+	Passed         bool      `json:"passed"`
+}
+
+// Analyze computes the upgrade graph of each channel of each package of
+// bundles, and returns a report for each package ordered by name.
+func Analyze(bundles []Bundle) []Report {
+	byPackage := map[string][]Bundle{}
+	for _, b := range bundles {
+		byPackage[b.Package] = append(byPackage[b.Package], b)
+	}
+	var reports []Report
+	for pkg, pkgBundles := range byPackage {
+		reports = append(reports, analyzePackage(pkg, pkgBundles))
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Package < reports[j].Package })
+	return reports
+}
+
+func analyzePackage(pkg string, bundles []Bundle) Report {
+	sortBundles(bundles)
+	r := Report{Package: pkg, Passed: true}
+	addFinding := func(f Finding) {
+		r.Findings = append(r.Findings, f)
+		if f.Severity == SeverityError {
+			r.Passed = false
+		}
+	}
+
+	channels := map[string][]Bundle{}
+	for _, b := range bundles {
+		if b.DefaultChannel != "" {
+			r.DefaultChannel = b.DefaultChannel
+		}
+		if len(b.Channels) == 0 {
+			addFinding(Finding{SeverityWarning, "", fmt.Sprintf("%s is not in any channel", b.Name)})
+		}
+		for _, ch := range b.Channels {
+			channels[ch] = append(channels[ch], b)
+		}
+	}
+	if _, ok := channels[r.DefaultChannel]; r.DefaultChannel != "" && !ok {
+		addFinding(Finding{SeverityError, "", fmt.Sprintf("default channel %s has no bundles", r.DefaultChannel)})
+	}
+
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// Edges in several channels have their CRDs checked once.
+	checkedEdges := map[Edge]bool{}
+	for _, name := range names {
+		ch, findings := analyzeChannel(name, channels[name], checkedEdges)
+		r.Channels = append(r.Channels, ch)
+		for _, f := range findings {
+			addFinding(f)
+		}
+	}
+	return r
+}
+
+func analyzeChannel(name string, bundles []Bundle, checkedEdges map[Edge]bool) (Channel, []Finding) {
+	ch := Channel{Name: name}
+	var findings []Finding
+	addFinding := func(severity, format string, args ...interface{}) {
+		findings = append(findings, Finding{severity, name, fmt.Sprintf(format, args...)})
+	}
+
+	byName := map[string]Bundle{}
+	for _, b := range bundles {
+		ch.Bundles = append(ch.Bundles, b.Name)
+		byName[b.Name] = b
+	}
+
+	// Compute edges from each bundle to the bundles that upgrade it.
+	upgrades := map[string][]string{}
+	upgradedFrom := map[string][]string{}
+	for _, to := range bundles {
+		if to.Replaces != "" {
+			if _, ok := byName[to.Replaces]; !ok {
+				addFinding(SeverityWarning, "%s replaces %s, which is not in the channel", to.Name, to.Replaces)
+			}
+		}
+		var inRange semver.Range
+		if to.SkipRange != "" {
+			var err error
+			if inRange, err = semver.ParseRange(to.SkipRange); err != nil {
+				addFinding(SeverityError, "%s has an invalid skipRange %q: %v", to.Name, to.SkipRange, err)
+			}
+		}
+		for _, from := range bundles {
+			if from.Name == to.Name {
+				continue
+			}
+			reason := ""
+			switch {
+			case to.Replaces == from.Name:
+				reason = ReasonReplaces
+			case containsString(to.Skips, from.Name):
+				reason = ReasonSkips
+			case inRange != nil && inRange(from.Version):
+				reason = ReasonSkipRange
+			default:
+				continue
+			}
+			edge := Edge{From: from.Name, To: to.Name, Reason: reason}
+			ch.Edges = append(ch.Edges, edge)
+			upgrades[from.Name] = append(upgrades[from.Name], to.Name)
+			upgradedFrom[to.Name] = append(upgradedFrom[to.Name], from.Name)
+			if !checkedEdges[edge] {
+				checkedEdges[edge] = true
+				findings = append(findings, checkCRDs(name, from, to)...)
+			}
+		}
+	}
+
+	for _, cycle := range findCycles(ch.Bundles, upgrades) {
+		addFinding(SeverityError, "upgrade cycle: %s", strings.Join(append(cycle, cycle[0]), " -> "))
+	}
+
+	// The head is the only bundle that does not upgrade to another.
+	var heads []string
+	for _, b := range ch.Bundles {
+		if len(upgrades[b]) == 0 {
+			heads = append(heads, b)
+		}
+	}
+	switch len(heads) {
+	case 0:
+		addFinding(SeverityError, "channel has no head, every bundle upgrades to another")
+	case 1:
+		ch.Head = heads[0]
+	default:
+		addFinding(SeverityError, "channel has multiple heads: %s", strings.Join(heads, ", "))
+	}
+
+	if ch.Head != "" {
+		reachesHead := reachable(ch.Head, upgradedFrom)
+		for _, b := range ch.Bundles {
+			if !reachesHead[b] {
+				addFinding(SeverityError, "%s cannot upgrade to the channel head %s", b, ch.Head)
+			}
+		}
+	}
+
+	// Every version but the oldest should be an upgrade of another.
+	for _, b := range ch.Bundles[1:] {
+		if len(upgradedFrom[b]) == 0 {
+			addFinding(SeverityWarning, "%s is unreachable, no other version in the channel upgrades to it", b)
+		}
+	}
+
+	return ch, findings
+}
+
+// checkCRDs returns an error for each CRD owned by from whose storage version
+// is removed by to, since objects stored in that version could not be read.
+func checkCRDs(channel string, from, to Bundle) (findings []Finding) {
+	for _, fromCRD := range from.CRDs {
+		for _, toCRD := range to.CRDs {
+			if toCRD.Name != fromCRD.Name || fromCRD.StorageVersion == "" {
+				continue
+			}
+			if !containsString(toCRD.Versions, fromCRD.StorageVersion) {
+				findings = append(findings, Finding{SeverityError, channel, fmt.Sprintf(
+					"upgrading from %s to %s removes version %s of CRD %s, which is the storage version in %s",
+					from.Name, to.Name, fromCRD.StorageVersion, fromCRD.Name, from.Name)})
+			}
+		}
+	}
+	return findings
+}
+
+// reachable returns the set of nodes from which start can be reached, given
+// the edges from each node to its predecessors.
+func reachable(start string, predecessors map[string][]string) map[string]bool {
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, p := range predecessors[node] {
+			if !seen[p] {
+				seen[p] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+	return seen
+}
+
+// findCycles returns the strongly connected components of the graph of edges
+// with more than one node, each ordered along a cycle where possible.
+func findCycles(nodes []string, edges map[string][]string) [][]string {
+	index, lowlink := map[string]int{}, map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var cycles [][]string
+	next := 0
+
+	var connect func(string)
+	connect = func(v string) {
+		index[v], lowlink[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range edges[v] {
+			if _, ok := index[w]; !ok {
+				connect(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], index[w])
+			}
+		}
+		if lowlink[v] != index[v] {
+			return
+		}
+		var component []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		if len(component) > 1 {
+			// Nodes are popped in reverse order of the path that found them.
+			for i, j := 0, len(component)-1; i < j; i, j = i+1, j-1 {
+				component[i], component[j] = component[j], component[i]
+			}
+			cycles = append(cycles, component)
+		}
+	}
+	for _, v := range nodes {
+		if _, ok := index[v]; !ok {
+			connect(v)
+		}
+	}
+	return cycles
+}
+
+func sortBundles(bundles []Bundle) {
+	sort.SliceStable(bundles, func(i, j int) bool {
+		if c := bundles[i].Version.Compare(bundles[j].Version); c != 0 {
+			return c < 0
+		}
+		return bundles[i].Name < bundles[j].Name
+	})
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
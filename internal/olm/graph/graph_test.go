@@ -0,0 +1,179 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/blang/semver/v4"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func newBundle(version string, channels ...string) Bundle {
+	return Bundle{
+		Name:     "memcached-operator.v" + version,
+		Package:  "memcached-operator",
+		Version:  semver.MustParse(version),
+		Channels: channels,
+	}
+}
+
+func messages(findings []Finding) (msgs []string) {
+	for _, f := range findings {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", f.Severity, f.Message))
+	}
+	return msgs
+}
+
+var _ = Describe("Analyze", func() {
+	It("computes the upgrade graph of each channel", func() {
+		v1, v2, v3 := newBundle("0.1.0", "alpha", "stable"), newBundle("0.2.0", "alpha", "stable"), newBundle("0.3.0", "alpha", "stable")
+		v2.Replaces = v1.Name
+		v3.Replaces = v2.Name
+		v3.SkipRange = "<0.2.0"
+		v3.DefaultChannel = "stable"
+
+		reports := Analyze([]Bundle{v3, v1, v2})
+		Expect(reports).To(HaveLen(1))
+		r := reports[0]
+		Expect(r.Passed).To(BeTrue())
+		Expect(r.Findings).To(BeEmpty())
+		Expect(r.DefaultChannel).To(Equal("stable"))
+		Expect(r.Channels).To(HaveLen(2))
+
+		alpha := r.Channels[0]
+		Expect(alpha.Head).To(Equal(v3.Name))
+		Expect(alpha.Bundles).To(Equal([]string{v1.Name, v2.Name, v3.Name}))
+		Expect(alpha.Edges).To(ConsistOf(
+			Edge{From: v1.Name, To: v2.Name, Reason: ReasonReplaces},
+			Edge{From: v2.Name, To: v3.Name, Reason: ReasonReplaces},
+			Edge{From: v1.Name, To: v3.Name, Reason: ReasonSkipRange},
+		))
+		Expect(r.Channels[1].Head).To(Equal(v3.Name))
+	})
+
+	It("reports multiple heads and versions that cannot reach the head", func() {
+		v1, v2, v3 := newBundle("0.1.0", "alpha"), newBundle("0.2.0", "alpha"), newBundle("0.3.0", "alpha")
+		v3.Replaces = v1.Name
+
+		r := Analyze([]Bundle{v1, v2, v3})[0]
+		Expect(r.Passed).To(BeFalse())
+		Expect(messages(r.Findings)).To(ConsistOf(
+			"error: channel has multiple heads: memcached-operator.v0.2.0, memcached-operator.v0.3.0",
+			"warning: memcached-operator.v0.2.0 is unreachable, no other version in the channel upgrades to it",
+		))
+	})
+
+	It("reports cycles", func() {
+		v1, v2 := newBundle("0.1.0", "alpha"), newBundle("0.2.0", "alpha")
+		v1.Replaces = v2.Name
+		v2.Replaces = v1.Name
+
+		r := Analyze([]Bundle{v1, v2})[0]
+		Expect(r.Passed).To(BeFalse())
+		Expect(messages(r.Findings)).To(ContainElements(
+			"error: upgrade cycle: memcached-operator.v0.1.0 -> memcached-operator.v0.2.0 -> memcached-operator.v0.1.0",
+			"error: channel has no head, every bundle upgrades to another",
+		))
+	})
+
+	It("reports stranded versions, missing replaces and invalid skip ranges", func() {
+		v1, v2, v3 := newBundle("0.1.0", "alpha"), newBundle("0.2.0", "alpha"), newBundle("0.3.0", "alpha")
+		v1.Replaces = "memcached-operator.v0.0.1"
+		v2.Replaces = v1.Name
+		v2.Skips = []string{v3.Name}
+		v3.SkipRange = "not a range"
+
+		r := Analyze([]Bundle{v1, v2, v3})[0]
+		Expect(messages(r.Findings)).To(ConsistOf(
+			"warning: memcached-operator.v0.1.0 replaces memcached-operator.v0.0.1, which is not in the channel",
+			`error: memcached-operator.v0.3.0 has an invalid skipRange "not a range": `+
+				`Could not get version from string: "not"`,
+			"warning: memcached-operator.v0.3.0 is unreachable, no other version in the channel upgrades to it",
+		))
+		Expect(r.Channels[0].Head).To(Equal(v2.Name))
+	})
+
+	It("reports CRD storage version removals once", func() {
+		v1, v2 := newBundle("0.1.0", "alpha", "stable"), newBundle("0.2.0", "alpha", "stable")
+		v1.CRDs = []CRD{{Name: "memcacheds.cache.example.com", Versions: []string{"v1alpha1"}, StorageVersion: "v1alpha1"}}
+		v2.CRDs = []CRD{{Name: "memcacheds.cache.example.com", Versions: []string{"v1beta1"}, StorageVersion: "v1beta1"}}
+		v2.Replaces = v1.Name
+
+		r := Analyze([]Bundle{v1, v2})[0]
+		Expect(r.Passed).To(BeFalse())
+		Expect(messages(r.Findings)).To(Equal([]string{
+			"error: upgrading from memcached-operator.v0.1.0 to memcached-operator.v0.2.0 removes version v1alpha1 " +
+				"of CRD memcacheds.cache.example.com, which is the storage version in memcached-operator.v0.1.0",
+		}))
+
+		v2.CRDs[0].Versions = []string{"v1alpha1", "v1beta1"}
+		Expect(Analyze([]Bundle{v1, v2})[0].Passed).To(BeTrue())
+	})
+})
+
+var _ = Describe("LoadPackageManifests", func() {
+	It("places bundles in the channels they are reached from", func() {
+		dir, err := ioutil.TempDir("", "packagemanifests")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		writeFile := func(path, content string) {
+			path = filepath.Join(dir, path)
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		}
+		writeFile("memcached-operator.package.yaml", `packageName: memcached-operator
+defaultChannel: stable
+channels:
+- name: stable
+  currentCSV: memcached-operator.v0.2.0
+`)
+		csv := `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: memcached-operator.v%[1]s
+spec:
+  version: %[1]s
+%[2]s`
+		writeFile("0.1.0/memcached-operator.clusterserviceversion.yaml", fmt.Sprintf(csv, "0.1.0", ""))
+		writeFile("0.1.1/memcached-operator.clusterserviceversion.yaml", fmt.Sprintf(csv, "0.1.1", ""))
+		writeFile("0.2.0/memcached-operator.clusterserviceversion.yaml", fmt.Sprintf(csv, "0.2.0",
+			"  replaces: memcached-operator.v0.1.0\n  skips:\n  - memcached-operator.v0.1.1\n"))
+
+		manifests, err := LoadPackageManifests(dir)
+		Expect(err).NotTo(HaveOccurred())
+		var bundles []Bundle
+		for _, m := range manifests {
+			b, err := NewBundle(m, m.Package, m.Channels, m.DefaultChannel)
+			Expect(err).NotTo(HaveOccurred())
+			bundles = append(bundles, b)
+		}
+		r := Analyze(bundles)[0]
+		Expect(r.DefaultChannel).To(Equal("stable"))
+		Expect(r.Channels[0].Head).To(Equal("memcached-operator.v0.2.0"))
+		Expect(r.Channels[0].Edges).To(ConsistOf(
+			Edge{From: "memcached-operator.v0.1.0", To: "memcached-operator.v0.2.0", Reason: ReasonReplaces},
+			Edge{From: "memcached-operator.v0.1.1", To: "memcached-operator.v0.2.0", Reason: ReasonSkips},
+		))
+		Expect(messages(r.Findings)).To(ConsistOf(
+			"warning: memcached-operator.v0.1.1 is unreachable, no other version in the channel upgrades to it",
+		))
+	})
+})
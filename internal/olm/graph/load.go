@@ -0,0 +1,135 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// skipRangeAnnotation is the CSV annotation holding the range of versions
+// a bundle upgrades from.
+const skipRangeAnnotation = "olm.skipRange"
+
+// NewBundle returns the graph node of b, published in channels of pkg.
+func NewBundle(b *apimanifests.Bundle, pkg string, channels []string, defaultChannel string) (Bundle, error) {
+	if b.CSV == nil {
+		return Bundle{}, fmt.Errorf("bundle %s has no CSV", b.Name)
+	}
+	csv := b.CSV
+	node := Bundle{
+		Name:           csv.GetName(),
+		Package:        pkg,
+		Version:        csv.Spec.Version.Version,
+		Channels:       channels,
+		DefaultChannel: defaultChannel,
+		Replaces:       csv.Spec.Replaces,
+		Skips:          csvSkips(b),
+		SkipRange:      csv.GetAnnotations()[skipRangeAnnotation],
+	}
+
+	for _, crd := range b.V1CRDs {
+		c := CRD{Name: crd.GetName()}
+		for _, v := range crd.Spec.Versions {
+			c.Versions = append(c.Versions, v.Name)
+			if v.Storage {
+				c.StorageVersion = v.Name
+			}
+		}
+		node.CRDs = append(node.CRDs, c)
+	}
+	for _, crd := range b.V1beta1CRDs {
+		c := CRD{Name: crd.GetName()}
+		for _, v := range crd.Spec.Versions {
+			c.Versions = append(c.Versions, v.Name)
+			if v.Storage {
+				c.StorageVersion = v.Name
+			}
+		}
+		if len(c.Versions) == 0 && crd.Spec.Version != "" {
+			c.Versions, c.StorageVersion = []string{crd.Spec.Version}, crd.Spec.Version
+		}
+		node.CRDs = append(node.CRDs, c)
+	}
+	return node, nil
+}
+
+// csvSkips returns the spec.skips field of the CSV of b, which is not part of
+// the typed CSV, from the CSV's manifest.
+func csvSkips(b *apimanifests.Bundle) []string {
+	for _, obj := range b.Objects {
+		if obj.GetKind() == v1alpha1.ClusterServiceVersionKind {
+			skips, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "skips")
+			return skips
+		}
+	}
+	return nil
+}
+
+// PackageManifestChannels returns the channels of each bundle of pkg by CSV
+// name. As for OLM, a bundle is in a channel if it is reached from the
+// channel's current CSV through replaces and skips.
+func PackageManifestChannels(pkg *apimanifests.PackageManifest, bundles []*apimanifests.Bundle) map[string][]string {
+	byName := map[string]*apimanifests.Bundle{}
+	for _, b := range bundles {
+		if b.CSV != nil {
+			byName[b.CSV.GetName()] = b
+		}
+	}
+	channels := map[string][]string{}
+	for _, ch := range pkg.Channels {
+		queue := []string{ch.CurrentCSVName}
+		seen := map[string]bool{}
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			b, ok := byName[name]
+			if !ok || seen[name] {
+				continue
+			}
+			seen[name] = true
+			channels[name] = append(channels[name], ch.Name)
+			queue = append(queue, b.CSV.Spec.Replaces)
+			queue = append(queue, csvSkips(b)...)
+		}
+	}
+	return channels
+}
+
+// LoadPackageManifests returns the bundles of the package manifests in dir,
+// with their package, default channel and the channels they are reached from set.
+func LoadPackageManifests(dir string) ([]*apimanifests.Bundle, error) {
+	pkg, bundles, err := apimanifests.GetManifestsDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil || pkg.PackageName == "" {
+		return nil, fmt.Errorf("no package manifest found in %s", dir)
+	}
+
+	channels := PackageManifestChannels(pkg, bundles)
+	for _, b := range bundles {
+		if b.CSV == nil {
+			return nil, fmt.Errorf("bundle %s has no CSV", b.Name)
+		}
+		b.Package = pkg.PackageName
+		b.DefaultChannel = pkg.DefaultChannelName
+		b.Channels = channels[b.CSV.GetName()]
+	}
+	return bundles, nil
+}
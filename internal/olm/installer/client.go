@@ -41,6 +41,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	olmresourceclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 )
 
 const (
@@ -54,6 +55,10 @@ type Client struct {
 	*olmresourceclient.Client
 	HTTPClient      http.Client
 	BaseDownloadURL string
+	// ManifestSource sets local OLM manifests to use instead of downloading them.
+	ManifestSource ManifestSource
+	// ImageMirrors replace images, or registries and repositories, in OLM manifests.
+	ImageMirrors registryutil.ImageMirrors
 }
 
 func ClientForConfig(cfg *rest.Config) (*Client, error) {
@@ -173,39 +178,72 @@ func (c Client) GetStatus(ctx context.Context, namespace, version string) (*olmr
 }
 
 func (c Client) getResources(ctx context.Context, version string) ([]unstructured.Unstructured, error) {
-	log.Infof("Fetching CRDs for version %q", version)
-
-	resolvedVersion := formatVersion(version)
+	resources, err := c.getManifests(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	mirrorImages(resources, c.ImageMirrors)
+	return resources, nil
+}
 
-	var crdResources, olmResources []unstructured.Unstructured
-	var err error
+func (c Client) getManifests(ctx context.Context, version string) ([]unstructured.Unstructured, error) {
+	crdsFile, olmFile, err := c.ManifestSource.files()
+	if err != nil {
+		return nil, err
+	}
+	if crdsFile != "" {
+		log.Infof("Using resource manifests from %s and %s", crdsFile, olmFile)
+		return readManifestFiles(crdsFile, olmFile)
+	}
 
 	// If the manifests for the requested version are saved as bindata in SDK, use
 	// them instead of fetching them from github.
-	if olmmanifests.HasVersion(version) {
-		log.Infof("Using locally stored resource manifests")
-		crdManifestBindataPath := filepath.Join(bindataManifestPath, version+"-crds.yaml")
-		crdResources, err = getPackagedManifests(crdManifestBindataPath)
-		if err != nil {
-			return nil, err
-		}
+	if embedded, ok := embeddedVersion(version); ok {
+		log.Infof("Using locally stored resource manifests for version %q", embedded)
+		return getEmbeddedManifests(embedded)
+	}
 
-		olmManifestBindataPath := filepath.Join(bindataManifestPath, version+"-olm.yaml")
-		olmResources, err = getPackagedManifests(olmManifestBindataPath)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		log.Infof("Fetching resources for resolved version %q", resolvedVersion)
-		crdResources, err = c.getCRDs(ctx, resolvedVersion)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch CRDs: %v", err)
+	resolvedVersion := formatVersion(version)
+	log.Infof("Fetching resources for resolved version %q", resolvedVersion)
+	resources, err := c.downloadManifests(ctx, resolvedVersion)
+	if err == nil {
+		return resources, nil
+	}
+	// Without network access, the latest stored version is the best guess at "latest".
+	if version == DefaultVersion {
+		if embedded := latestEmbeddedVersion(); embedded != "" {
+			log.Warnf("Failed to fetch resources for version %q, using locally stored resource manifests "+
+				"for version %q: %v", version, embedded, err)
+			return getEmbeddedManifests(embedded)
 		}
+	}
+	return nil, err
+}
 
-		olmResources, err = c.getOLM(ctx, resolvedVersion)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch resources: %v", err)
-		}
+func (c Client) downloadManifests(ctx context.Context, version string) ([]unstructured.Unstructured, error) {
+	crdResources, err := c.getCRDs(ctx, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch CRDs: %v", err)
+	}
+
+	olmResources, err := c.getOLM(ctx, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch resources: %v", err)
+	}
+
+	resources := append(crdResources, olmResources...)
+	return resources, nil
+}
+
+func getEmbeddedManifests(version string) ([]unstructured.Unstructured, error) {
+	crdResources, err := getPackagedManifests(filepath.Join(bindataManifestPath, version+"-crds.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	olmResources, err := getPackagedManifests(filepath.Join(bindataManifestPath, version+"-olm.yaml"))
+	if err != nil {
+		return nil, err
 	}
 
 	resources := append(crdResources, olmResources...)
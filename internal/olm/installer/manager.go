@@ -16,20 +16,35 @@ package installer
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/yaml"
+
+	olmresourceclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 )
 
 const (
 	DefaultVersion = "latest"
 	DefaultTimeout = time.Minute * 2
+	// statusInterval is the interval at which Status polls OLM resources when waiting.
+	statusInterval = time.Second * 2
 	// DefaultOLMNamespace is the namespace where OLM is installed
 	DefaultOLMNamespace = "olm"
+
+	// Status output formats.
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
 )
 
 type Manager struct {
@@ -37,7 +52,15 @@ type Manager struct {
 	Version      string
 	Timeout      time.Duration
 	OLMNamespace string
-	once         sync.Once
+	// ManifestSource sets local OLM manifests to use instead of downloading them.
+	ManifestSource ManifestSource
+	// ImageMirrors replace images, or registries and repositories, in OLM manifests.
+	ImageMirrors registryutil.ImageMirrors
+	// Output is the format of Status output, one of text, json or yaml.
+	Output string
+	// Wait makes Status wait until all OLM resources are installed, for up to Timeout.
+	Wait bool
+	once sync.Once
 }
 
 func (m *Manager) initialize() (err error) {
@@ -56,6 +79,8 @@ func (m *Manager) initialize() (err error) {
 			}
 			m.Client = client
 		}
+		m.Client.ManifestSource = m.ManifestSource
+		m.Client.ImageMirrors = m.ImageMirrors
 		if m.Timeout <= 0 {
 			m.Timeout = DefaultTimeout
 		}
@@ -114,6 +139,11 @@ func (m *Manager) Uninstall() error {
 }
 
 func (m *Manager) Status() error {
+	switch m.Output {
+	case "", OutputText, OutputJSON, OutputYAML:
+	default:
+		return fmt.Errorf("invalid output format %q, must be one of: [%s, %s, %s]", m.Output, OutputText, OutputJSON, OutputYAML)
+	}
 	if err := m.initialize(); err != nil {
 		return err
 	}
@@ -121,29 +151,86 @@ func (m *Manager) Status() error {
 	ctx, cancel := context.WithTimeout(context.Background(), m.Timeout)
 	defer cancel()
 
+	if !m.Wait {
+		status, err := m.getStatus(ctx)
+		if err != nil {
+			return err
+		}
+		log.Infof("Successfully got OLM status for version %q", m.Version)
+		return printStatus(os.Stdout, m.Output, m.Version, status)
+	}
+
+	var (
+		status  *olmresourceclient.Status
+		lastErr error
+	)
+	err := wait.PollImmediateUntil(statusInterval, func() (bool, error) {
+		status, lastErr = m.getStatus(ctx)
+		if lastErr != nil {
+			log.Debugf("Waiting for OLM to be installed: %v", lastErr)
+			return false, nil
+		}
+		return status.AllInstalled(), nil
+	}, ctx.Done())
+	if err == nil {
+		log.Infof("Successfully got OLM status for version %q", m.Version)
+		return printStatus(os.Stdout, m.Output, m.Version, status)
+	}
+	if status != nil {
+		if perr := printStatus(os.Stdout, m.Output, m.Version, status); perr != nil {
+			return perr
+		}
+	}
+	if lastErr != nil {
+		return fmt.Errorf("timed out waiting for OLM to be installed: %v", lastErr)
+	}
+	return fmt.Errorf("timed out waiting for all OLM resources to be installed")
+}
+
+// getStatus returns the status of the OLM installation, discovering its version unless set.
+func (m *Manager) getStatus(ctx context.Context) (*olmresourceclient.Status, error) {
 	if version, err := m.Client.GetInstalledVersion(ctx, m.OLMNamespace); err != nil {
 		if m.Version == "" {
-			return fmt.Errorf("error getting installed OLM version (set --version to override the default version): %v", err)
+			return nil, fmt.Errorf("error getting installed OLM version (set --version to override the default version): %v", err)
 		}
 	} else if m.Version != "" {
 		if version != m.Version {
-			return fmt.Errorf("mismatched installed version %q vs. supplied version %q", version, m.Version)
+			return nil, fmt.Errorf("mismatched installed version %q vs. supplied version %q", version, m.Version)
 		}
 	} else {
 		m.Version = version
 	}
+	return m.Client.GetStatus(ctx, m.OLMNamespace, m.Version)
+}
 
-	status, err := m.Client.GetStatus(ctx, m.OLMNamespace, m.Version)
+// printStatus writes status to w as a table, or as a report in the JSON or YAML output format.
+func printStatus(w io.Writer, output, version string, status *olmresourceclient.Status) error {
+	var (
+		b   []byte
+		err error
+	)
+	switch output {
+	case OutputJSON:
+		b, err = json.MarshalIndent(status.Report(version), "", "  ")
+		b = append(b, '\n')
+	case OutputYAML:
+		b, err = yaml.Marshal(status.Report(version))
+	default:
+		b = []byte(fmt.Sprintf("\n%s\n", status))
+	}
 	if err != nil {
-		return err
+		return fmt.Errorf("error marshaling status: %v", err)
 	}
-
-	log.Infof("Successfully got OLM status for version %q", m.Version)
-	fmt.Print("\n")
-	fmt.Println(status)
-	return nil
+	_, err = w.Write(b)
+	return err
 }
 
 func (m *Manager) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.DurationVar(&m.Timeout, "timeout", DefaultTimeout, "time to wait for the command to complete before failing")
+	fs.StringVar(&m.ManifestSource.ManifestsDir, "manifests-dir", "",
+		"directory containing the crds.yaml and olm.yaml manifests of an OLM release, used instead of downloading them")
+	fs.StringVar(&m.ManifestSource.CRDsFile, "crds-file", "",
+		"file containing the CRD manifests of an OLM release, used with --olm-file instead of downloading them")
+	fs.StringVar(&m.ManifestSource.OLMFile, "olm-file", "",
+		"file containing the resource manifests of an OLM release, used with --crds-file instead of downloading them")
 }
@@ -0,0 +1,85 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"bytes"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	olmresourceclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+)
+
+var _ = Describe("Manager", func() {
+	Describe("printStatus", func() {
+		var status *olmresourceclient.Status
+
+		BeforeEach(func() {
+			deployGVK := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+			status = &olmresourceclient.Status{Resources: []olmresourceclient.ResourceStatus{
+				{
+					NamespacedName: types.NamespacedName{Namespace: "olm", Name: "olm-operator"},
+					GVK:            deployGVK,
+					Resource:       &unstructured.Unstructured{},
+				},
+				{
+					NamespacedName: types.NamespacedName{Namespace: "olm", Name: "catalog-operator"},
+					GVK:            deployGVK,
+					Error:          apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "catalog-operator"),
+				},
+				{
+					NamespacedName: types.NamespacedName{Name: "olm"},
+					GVK:            schema.GroupVersionKind{Version: "v1", Kind: "Namespace"},
+					Error:          errors.New("forbidden"),
+				},
+			}}
+		})
+
+		It("prints a JSON report", func() {
+			out := &bytes.Buffer{}
+			Expect(printStatus(out, OutputJSON, "0.17.0", status)).To(Succeed())
+			Expect(out.String()).To(MatchJSON(`{
+				"olmVersion": "0.17.0",
+				"resources": [
+					{"name": "olm-operator", "namespace": "olm", "apiVersion": "apps/v1", "kind": "Deployment", "status": "Installed"},
+					{"name": "catalog-operator", "namespace": "olm", "apiVersion": "apps/v1", "kind": "Deployment", "status": "NotFound",
+					 "error": "deployments.apps \"catalog-operator\" not found"},
+					{"name": "olm", "apiVersion": "v1", "kind": "Namespace", "status": "Error", "error": "forbidden"}
+				]
+			}`))
+		})
+
+		It("prints a YAML report", func() {
+			out := &bytes.Buffer{}
+			Expect(printStatus(out, OutputYAML, "0.17.0", status)).To(Succeed())
+			Expect(out.String()).To(HavePrefix("olmVersion: 0.17.0\nresources:\n- apiVersion: apps/v1\n"))
+			Expect(out.String()).To(ContainSubstring("status: NotFound"))
+		})
+
+		It("prints a table", func() {
+			out := &bytes.Buffer{}
+			Expect(printStatus(out, OutputText, "0.17.0", status)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("NAME"))
+			Expect(out.String()).To(ContainSubstring("olm-operator"))
+			Expect(out.String()).To(ContainSubstring("Installed"))
+		})
+	})
+})
@@ -0,0 +1,147 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	olmmanifests "github.com/operator-framework/operator-sdk/internal/bindata/olm"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// The names of the OLM release assets, also expected in a manifests directory.
+	crdsFileName = "crds.yaml"
+	olmFileName  = "olm.yaml"
+)
+
+// ManifestSource configures where OLM manifests are read from instead of
+// the OLM release for a version. A zero ManifestSource uses manifests embedded
+// in the SDK for known versions and downloads other versions.
+type ManifestSource struct {
+	// ManifestsDir is a directory containing crds.yaml and olm.yaml,
+	// as attached to OLM releases.
+	ManifestsDir string
+	// CRDsFile and OLMFile are the CRD and resource manifests of OLM,
+	// and must be set together.
+	CRDsFile string
+	OLMFile  string
+}
+
+// files returns the CRD and resource manifest files of s,
+// which are empty if s does not set local files.
+func (s ManifestSource) files() (crdsFile, olmFile string, err error) {
+	switch {
+	case s.ManifestsDir != "" && (s.CRDsFile != "" || s.OLMFile != ""):
+		return "", "", errors.New("a manifests directory cannot be set with CRDs or OLM files")
+	case s.ManifestsDir != "":
+		return filepath.Join(s.ManifestsDir, crdsFileName), filepath.Join(s.ManifestsDir, olmFileName), nil
+	case (s.CRDsFile == "") != (s.OLMFile == ""):
+		return "", "", errors.New("the CRDs and OLM files must be set together")
+	}
+	return s.CRDsFile, s.OLMFile, nil
+}
+
+// readManifestFiles decodes the resources in files, in order.
+func readManifestFiles(files ...string) ([]unstructured.Unstructured, error) {
+	var resources []unstructured.Unstructured
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		objs, err := decodeResources(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error decoding %s: %v", file, err)
+		}
+		resources = append(resources, objs...)
+	}
+	return resources, nil
+}
+
+// embeddedVersion returns the version of manifests embedded in the SDK for
+// version, which may be prefixed with "v", and whether there is one.
+func embeddedVersion(version string) (string, bool) {
+	version = strings.TrimPrefix(version, "v")
+	return version, olmmanifests.HasVersion(version)
+}
+
+// latestEmbeddedVersion returns the latest version of manifests embedded in the SDK.
+func latestEmbeddedVersion() string {
+	var versions []semver.Version
+	for _, name := range olmmanifests.AssetNames() {
+		name = filepath.Base(name)
+		if !strings.HasSuffix(name, "-"+crdsFileName) {
+			continue
+		}
+		if v, err := semver.Parse(strings.TrimSuffix(name, "-"+crdsFileName)); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	if len(versions) == 0 {
+		return ""
+	}
+	sort.Sort(semver.Versions(versions))
+	return versions[len(versions)-1].String()
+}
+
+// mirrorImages replaces image references in resources with mirrors. Image
+// references are matched anywhere in the resources, including container
+// arguments like "-util-image" and CatalogSource images.
+func mirrorImages(resources []unstructured.Unstructured, mirrors registryutil.ImageMirrors) {
+	if len(mirrors) == 0 {
+		return
+	}
+	for i := range resources {
+		resources[i].Object = mirrorImagesIn(resources[i].Object, mirrors).(map[string]interface{})
+	}
+}
+
+func mirrorImagesIn(value interface{}, mirrors registryutil.ImageMirrors) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = mirrorImagesIn(e, mirrors)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = mirrorImagesIn(e, mirrors)
+		}
+	case string:
+		return mirrorImage(v, mirrors)
+	}
+	return value
+}
+
+// mirrorImage returns s with its image replaced by its mirror, if any. s may be
+// an image, or a flag of the form "-flag=image".
+func mirrorImage(s string, mirrors registryutil.ImageMirrors) string {
+	prefix, image := "", strings.TrimSpace(s)
+	if i := strings.Index(image, "="); i >= 0 && strings.HasPrefix(image, "-") {
+		prefix, image = image[:i+1], image[i+1:]
+	}
+	if mirrored := mirrors.Apply(image); mirrored != image {
+		return prefix + mirrored
+	}
+	return s
+}
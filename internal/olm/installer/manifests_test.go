@@ -0,0 +1,130 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+var _ = Describe("OLM manifests", func() {
+	var (
+		server *httptest.Server
+		c      Client
+	)
+
+	BeforeEach(func() {
+		// A release server that is unreachable, like in a cluster without internet access.
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		c = Client{HTTPClient: *server.Client(), BaseDownloadURL: server.URL}
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("getResources", func() {
+		It("uses stored manifests for known versions", func() {
+			resources, err := c.getResources(context.TODO(), "v0.17.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kinds(resources)).To(ContainElements("CustomResourceDefinition", "Deployment", "CatalogSource"))
+		})
+		It("falls back to the latest stored manifests for latest", func() {
+			resources, err := c.getResources(context.TODO(), DefaultVersion)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resources).NotTo(BeEmpty())
+			Expect(latestEmbeddedVersion()).To(Equal("0.17.0"))
+		})
+		It("fails for unknown versions that cannot be downloaded", func() {
+			_, err := c.getResources(context.TODO(), "0.18.0")
+			Expect(err).To(MatchError(ContainSubstring("failed to fetch CRDs")))
+		})
+		It("reads manifests from a directory", func() {
+			dir, err := ioutil.TempDir("", "olm-manifests")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			Expect(ioutil.WriteFile(filepath.Join(dir, crdsFileName), []byte(
+				"apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: a\n"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, olmFileName), []byte(
+				"apiVersion: v1\nkind: Namespace\nmetadata:\n  name: olm\n---\n"+
+					"apiVersion: v1\nkind: Namespace\nmetadata:\n  name: operators\n"), 0644)).To(Succeed())
+
+			c.ManifestSource = ManifestSource{ManifestsDir: dir}
+			resources, err := c.getResources(context.TODO(), "0.18.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kinds(resources)).To(Equal([]string{"CustomResourceDefinition", "Namespace", "Namespace"}))
+
+			c.ManifestSource = ManifestSource{CRDsFile: filepath.Join(dir, crdsFileName)}
+			_, err = c.getResources(context.TODO(), "0.18.0")
+			Expect(err).To(MatchError("the CRDs and OLM files must be set together"))
+		})
+		It("mirrors images", func() {
+			c.ImageMirrors = registryutil.ImageMirrors{
+				{Source: "quay.io/operator-framework", Mirror: "mirror.example.com/olm"},
+				{Source: "quay.io/operatorhubio/catalog", Mirror: "mirror.example.com/catalog"},
+			}
+			resources, err := c.getResources(context.TODO(), "0.17.0")
+			Expect(err).NotTo(HaveOccurred())
+			for _, r := range resources {
+				b, err := r.MarshalJSON()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(b)).NotTo(ContainSubstring("quay.io"))
+			}
+		})
+	})
+
+	Describe("mirrorImage", func() {
+		mirrors := registryutil.ImageMirrors{
+			{Source: "quay.io/operator-framework", Mirror: "mirror.example.com/olm"},
+			{Source: "quay.io/operator-framework/olm", Mirror: "mirror.example.com/olm-operator"},
+			{Source: "quay.io/operatorhubio/catalog", Mirror: "mirror.example.com/catalog"},
+		}
+		It("replaces the longest matching prefix", func() {
+			Expect(mirrorImage("quay.io/operator-framework/olm@sha256:abc", mirrors)).
+				To(Equal("mirror.example.com/olm-operator@sha256:abc"))
+			Expect(mirrorImage("quay.io/operator-framework/configmap-operator-registry:latest", mirrors)).
+				To(Equal("mirror.example.com/olm/configmap-operator-registry:latest"))
+			Expect(mirrorImage("quay.io/operatorhubio/catalog:latest", mirrors)).
+				To(Equal("mirror.example.com/catalog:latest"))
+		})
+		It("replaces images in flags", func() {
+			Expect(mirrorImage("-configmapServerImage=quay.io/operator-framework/configmap-operator-registry:latest", mirrors)).
+				To(Equal("-configmapServerImage=mirror.example.com/olm/configmap-operator-registry:latest"))
+		})
+		It("only matches whole path components", func() {
+			Expect(mirrorImage("quay.io/operator-framework-extra/olm", mirrors)).To(Equal("quay.io/operator-framework-extra/olm"))
+			Expect(mirrorImage("--namespace", mirrors)).To(Equal("--namespace"))
+		})
+	})
+
+})
+
+func kinds(resources []unstructured.Unstructured) (kinds []string) {
+	for _, r := range resources {
+		kinds = append(kinds, r.GetKind())
+	}
+	return kinds
+}
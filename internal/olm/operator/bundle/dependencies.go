@@ -0,0 +1,142 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/blang/semver/v4"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+// requirement is a dependency of a bundle on a package or an API, and the
+// bundle being run that satisfies it, if any.
+type requirement struct {
+	// Bundle is the CSV name of the bundle with the requirement.
+	Bundle string
+	// Description describes the required package or API.
+	Description string
+	// SatisfiedBy is the CSV name of the bundle satisfying the requirement,
+	// empty if none of the bundles being run satisfy it.
+	SatisfiedBy string
+	// Err is set if the requirement cannot be checked.
+	Err error
+}
+
+// resolveDependencies returns the requirements of all bundles, from their
+// dependencies.yaml files and the APIs their CSVs require, each with the bundle
+// satisfying it. This mirrors what OLM resolves, for a report before installing.
+func resolveDependencies(bundles []*apimanifests.Bundle) []requirement {
+	var reqs []requirement
+	for _, b := range bundles {
+		for _, d := range b.Dependencies {
+			reqs = append(reqs, resolveDependency(b, d, bundles))
+		}
+		for _, crd := range b.CSV.Spec.CustomResourceDefinitions.Required {
+			gvk := schema.GroupVersionKind{Group: k8sutil.CRDGroup(crd.Name), Version: crd.Version, Kind: crd.Kind}
+			reqs = append(reqs, resolveAPI(b, gvk, bundles))
+		}
+		for _, api := range b.CSV.Spec.APIServiceDefinitions.Required {
+			gvk := schema.GroupVersionKind{Group: api.Group, Version: api.Version, Kind: api.Kind}
+			reqs = append(reqs, resolveAPI(b, gvk, bundles))
+		}
+	}
+	return reqs
+}
+
+func resolveDependency(b *apimanifests.Bundle, d *apimanifests.Dependency, bundles []*apimanifests.Bundle) requirement {
+	switch d.Type {
+	case registry.PackageType:
+		dep := registry.PackageDependency{}
+		if err := json.Unmarshal([]byte(d.Value), &dep); err != nil {
+			return invalidDependency(b, d, err)
+		}
+		versions, err := semver.ParseRange(dep.Version)
+		if err != nil {
+			return invalidDependency(b, d, err)
+		}
+		req := requirement{Bundle: b.CSV.GetName(), Description: fmt.Sprintf("package %s %s", dep.PackageName, dep.Version)}
+		for _, c := range bundles {
+			if c.Package == dep.PackageName && versions(c.CSV.Spec.Version.Version) {
+				req.SatisfiedBy = c.CSV.GetName()
+				break
+			}
+		}
+		return req
+	case registry.GVKType:
+		dep := registry.GVKDependency{}
+		if err := json.Unmarshal([]byte(d.Value), &dep); err != nil {
+			return invalidDependency(b, d, err)
+		}
+		return resolveAPI(b, schema.GroupVersionKind{Group: dep.Group, Version: dep.Version, Kind: dep.Kind}, bundles)
+	}
+	return invalidDependency(b, d, fmt.Errorf("unsupported dependency type %q", d.Type))
+}
+
+func resolveAPI(b *apimanifests.Bundle, gvk schema.GroupVersionKind, bundles []*apimanifests.Bundle) requirement {
+	req := requirement{Bundle: b.CSV.GetName(), Description: fmt.Sprintf("API %s/%s %s", gvk.Group, gvk.Version, gvk.Kind)}
+	for _, c := range bundles {
+		if providesAPI(c, gvk) {
+			req.SatisfiedBy = c.CSV.GetName()
+			break
+		}
+	}
+	return req
+}
+
+// providesAPI returns whether the CSV of b owns the API gvk.
+func providesAPI(b *apimanifests.Bundle, gvk schema.GroupVersionKind) bool {
+	for _, crd := range b.CSV.Spec.CustomResourceDefinitions.Owned {
+		if (schema.GroupVersionKind{Group: k8sutil.CRDGroup(crd.Name), Version: crd.Version, Kind: crd.Kind}) == gvk {
+			return true
+		}
+	}
+	for _, api := range b.CSV.Spec.APIServiceDefinitions.Owned {
+		if (schema.GroupVersionKind{Group: api.Group, Version: api.Version, Kind: api.Kind}) == gvk {
+			return true
+		}
+	}
+	return false
+}
+
+func invalidDependency(b *apimanifests.Bundle, d *apimanifests.Dependency, err error) requirement {
+	return requirement{
+		Bundle:      b.CSV.GetName(),
+		Description: fmt.Sprintf("%s %s", d.Type, d.Value),
+		Err:         err,
+	}
+}
+
+// logResolution reports how reqs are satisfied by the bundles being run.
+// Unsatisfied requirements may still be resolved by OLM from other catalogs.
+func logResolution(reqs []requirement) {
+	for _, req := range reqs {
+		switch {
+		case req.Err != nil:
+			log.Warnf("Cannot check dependency %s of %s: %v", req.Description, req.Bundle, req.Err)
+		case req.SatisfiedBy != "":
+			log.Infof("Dependency %s of %s is satisfied by %s", req.Description, req.Bundle, req.SatisfiedBy)
+		default:
+			log.Warnf("Dependency %s of %s is not satisfied by the given bundles, "+
+				"OLM must resolve it from another catalog in the cluster", req.Description, req.Bundle)
+		}
+	}
+}
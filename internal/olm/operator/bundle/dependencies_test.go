@@ -0,0 +1,136 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/blang/semver/v4"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/lib/version"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+var _ = Describe("Dependencies", func() {
+	newBundle := func(pkg, name, ver string) *apimanifests.Bundle {
+		return &apimanifests.Bundle{
+			Package: pkg,
+			CSV: &v1alpha1.ClusterServiceVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: v1alpha1.ClusterServiceVersionSpec{
+					Version: version.OperatorVersion{Version: semver.MustParse(ver)},
+				},
+			},
+		}
+	}
+
+	Describe("resolveDependencies", func() {
+		It("reports the bundle satisfying each requirement", func() {
+			app := newBundle("app", "app.v0.1.0", "0.1.0")
+			app.Dependencies = []*apimanifests.Dependency{
+				{Type: "olm.package", Value: `{"packageName":"etcd","version":">=0.9.0 <1.0.0"}`},
+				{Type: "olm.gvk", Value: `{"group":"cache.example.com","version":"v1","kind":"Redis"}`},
+				{Type: "olm.label", Value: `{"label":"beta"}`},
+			}
+			app.CSV.Spec.CustomResourceDefinitions.Required = []v1alpha1.CRDDescription{
+				{Name: "etcdclusters.etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster"},
+			}
+			etcd := newBundle("etcd", "etcd.v0.9.4", "0.9.4")
+			etcd.CSV.Spec.CustomResourceDefinitions.Owned = []v1alpha1.CRDDescription{
+				{Name: "etcdclusters.etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster"},
+			}
+
+			reqs := resolveDependencies([]*apimanifests.Bundle{app, etcd})
+			Expect(reqs).To(HaveLen(4))
+			Expect(reqs[0]).To(Equal(requirement{
+				Bundle: "app.v0.1.0", Description: "package etcd >=0.9.0 <1.0.0", SatisfiedBy: "etcd.v0.9.4",
+			}))
+			Expect(reqs[1]).To(Equal(requirement{
+				Bundle: "app.v0.1.0", Description: "API cache.example.com/v1 Redis",
+			}))
+			Expect(reqs[2].Err).To(MatchError(`unsupported dependency type "olm.label"`))
+			Expect(reqs[3]).To(Equal(requirement{
+				Bundle: "app.v0.1.0", Description: "API etcd.database.coreos.com/v1beta2 EtcdCluster", SatisfiedBy: "etcd.v0.9.4",
+			}))
+		})
+		It("does not satisfy package requirements out of range", func() {
+			app := newBundle("app", "app.v0.1.0", "0.1.0")
+			app.Dependencies = []*apimanifests.Dependency{
+				{Type: "olm.package", Value: `{"packageName":"etcd","version":">=1.0.0"}`},
+			}
+			reqs := resolveDependencies([]*apimanifests.Bundle{app, newBundle("etcd", "etcd.v0.9.4", "0.9.4")})
+			Expect(reqs[0].SatisfiedBy).To(BeEmpty())
+			Expect(reqs[0].Err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("LoadBundleDir", func() {
+		It("loads dependencies from the bundle metadata", func() {
+			dir, err := ioutil.TempDir("", "bundle")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			Expect(copyDir(filepath.Join("..", "..", "..", "scorecard", "testdata", "bundle"), dir)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "metadata", "dependencies.yaml"), []byte(`dependencies:
+- type: olm.package
+  value:
+    packageName: etcd
+    version: ">=0.9.0"
+`), 0644)).To(Succeed())
+
+			_, bundle, err := operator.LoadBundleDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bundle.Dependencies).To(HaveLen(1))
+			Expect(bundle.Dependencies[0].Type).To(Equal("olm.package"))
+			Expect(bundle.Dependencies[0].Value).To(MatchJSON(`{"packageName":"etcd","version":">=0.9.0"}`))
+		})
+	})
+
+	Describe("setup", func() {
+		It("does not run dependency bundle images with a bundle directory", func() {
+			i := NewInstall(&operator.Configuration{Namespace: "default"})
+			i.BundleDir = "."
+			i.DependencyImages = []string{"quay.io/example/etcd-bundle:v0.9.4"}
+			Expect(i.setup(context.TODO())).To(MatchError(ContainSubstring("cannot be run with a bundle directory")))
+		})
+	})
+})
+
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dst, rel), b, info.Mode())
+	})
+}
@@ -16,23 +16,45 @@ package bundle
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/operator-framework/operator-sdk/internal/olm/declcfg"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry/configmap"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 )
 
 type Install struct {
 	BundleImage string
+	// BundleDir is a bundle directory to deploy instead of BundleImage,
+	// served from ConfigMaps so that no image needs to be pushed.
+	BundleDir string
+	// CatalogDir is a file-based catalog directory containing a single package,
+	// served from ConfigMaps. The head of the package's default channel is installed.
+	CatalogDir string
+	// DependencyImages are bundle images added to the same index as BundleImage,
+	// typically providing its dependencies, so that OLM can resolve them.
+	DependencyImages []string
 
 	*registry.IndexImageCatalogCreator
 	*registry.OperatorInstaller
 
-	cfg *operator.Configuration
+	configMapCatalogCreator *registry.ConfigMapCatalogCreator
+	fileBasedCatalogCreator *registry.FileBasedCatalogCreator
+	subscriptionConfig      operator.SubscriptionConfig
+	cfg                     *operator.Configuration
 }
 
 func NewInstall(cfg *operator.Configuration) Install {
@@ -42,6 +64,8 @@ func NewInstall(cfg *operator.Configuration) Install {
 	}
 	i.IndexImageCatalogCreator = registry.NewIndexImageCatalogCreator(cfg)
 	i.CatalogCreator = i.IndexImageCatalogCreator
+	i.configMapCatalogCreator = registry.NewConfigMapCatalogCreator(cfg)
+	i.fileBasedCatalogCreator = registry.NewFileBasedCatalogCreator(cfg)
 	return i
 }
 
@@ -54,12 +78,17 @@ func (i *Install) BindFlags(fs *pflag.FlagSet) {
 	_ = fs.MarkHidden("mode")
 
 	i.IndexImageCatalogCreator.BindFlags(fs)
+	i.subscriptionConfig.BindFlags(fs)
+	i.OperatorInstaller.BindProgressFlags(fs)
 }
 
 func (i Install) Run(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
 	if err := i.setup(ctx); err != nil {
 		return nil, err
 	}
+	if err := i.OperatorInstaller.CheckOperatorGroup(ctx); err != nil {
+		return nil, err
+	}
 	return i.InstallOperator(ctx)
 }
 
@@ -71,14 +100,67 @@ func (i *Install) setup(ctx context.Context) error {
 		}
 	}
 
+	if i.BundleDir != "" {
+		// Bundle directories are served from ConfigMaps, without an index image or pulls.
+		switch {
+		case len(i.DependencyImages) != 0:
+			return errors.New("dependency bundle images cannot be run with a bundle directory")
+		case i.IndexImage != "" && i.IndexImage != registry.DefaultIndexImage:
+			return errors.New("--index-image cannot be used with a bundle directory")
+		case len(i.SecretNames) != 0:
+			return errors.New("--secret-name cannot be used with a bundle directory")
+		}
+	}
+	if i.CatalogDir != "" {
+		// OLM pulls the bundle images of a catalog itself, without a CA file or TLS options.
+		switch {
+		case len(i.DependencyImages) != 0:
+			return errors.New("dependency bundle images cannot be run with a catalog directory")
+		case i.PullOptions.CAFile != "":
+			return errors.New("--ca-file cannot be used with a catalog directory, since OLM pulls its bundle images")
+		case i.PullOptions.SkipTLS:
+			return errors.New("--skip-tls cannot be used with a catalog directory, since OLM pulls its bundle images")
+		}
+	}
+
+	var err error
+	if i.OperatorInstaller.SubscriptionConfig, err = i.subscriptionConfig.Build(); err != nil {
+		return err
+	}
+
+	if i.CatalogDir != "" {
+		return i.setupCatalog(ctx)
+	}
+
 	// Load bundle labels and set label-dependent values.
-	labels, bundle, err := operator.LoadBundle(ctx, i.BundleImage)
+	var (
+		labels registryutil.Labels
+		bundle *apimanifests.Bundle
+	)
+	if i.BundleDir != "" {
+		labels, bundle, err = operator.LoadBundleDir(i.BundleDir)
+	} else {
+		labels, bundle, err = i.loadBundle(ctx, i.BundleImage)
+	}
 	if err != nil {
 		return err
 	}
 	csv := bundle.CSV
+	bundle.Package = labels[registrybundle.PackageLabel]
 
-	if err := i.InstallMode.CheckCompatibility(csv, i.cfg.Namespace); err != nil {
+	// Report how the dependencies of all bundles are satisfied by the bundles being run.
+	bundles := []*apimanifests.Bundle{bundle}
+	for _, image := range i.DependencyImages {
+		depLabels, dep, err := i.loadBundle(ctx, image)
+		if err != nil {
+			return fmt.Errorf("load dependency bundle %s: %v", image, err)
+		}
+		dep.Package = depLabels[registrybundle.PackageLabel]
+		bundles = append(bundles, dep)
+	}
+	logResolution(resolveDependencies(bundles))
+
+	if err := i.checkInstallMode(csv); err != nil {
 		return err
 	}
 
@@ -88,8 +170,153 @@ func (i *Install) setup(ctx context.Context) error {
 	i.OperatorInstaller.SupportedInstallModes = operator.GetSupportedInstallModes(csv.Spec.InstallModes)
 	i.OperatorInstaller.Channel = strings.Split(labels[registrybundle.ChannelsLabel], ",")[0]
 
+	if i.BundleDir != "" {
+		pkg := packageManifestForBundle(labels, csv.GetName())
+		if err := configmap.CheckDataSizes(pkg, bundles); err != nil {
+			return fmt.Errorf("bundle directory %s is too large to be served from ConfigMaps, "+
+				"build and push a bundle image to run instead: %v", i.BundleDir, err)
+		}
+		i.configMapCatalogCreator.Package = pkg
+		i.configMapCatalogCreator.Bundles = []*apimanifests.Bundle{bundle}
+		i.OperatorInstaller.CatalogCreator = i.configMapCatalogCreator
+		return nil
+	}
+
 	i.IndexImageCatalogCreator.PackageName = i.OperatorInstaller.PackageName
 	i.IndexImageCatalogCreator.BundleImage = i.BundleImage
+	i.IndexImageCatalogCreator.DependencyImages = i.DependencyImages
+
+	return nil
+}
+
+// checkInstallMode returns an error if the install mode is not supported by csv,
+// or if the WATCH_NAMESPACE env var of csv's deployments cannot match its target namespaces.
+func (i Install) checkInstallMode(csv *v1alpha1.ClusterServiceVersion) error {
+	if err := i.InstallMode.CheckCompatibility(csv, i.cfg.Namespace); err != nil {
+		return err
+	}
+
+	mode := i.InstallMode.InstallModeType
+	if i.InstallMode.IsEmpty() {
+		mode = operator.DefaultInstallModeType(operator.GetSupportedInstallModes(csv.Spec.InstallModes))
+		if mode == "" {
+			// Reported when creating the OperatorGroup.
+			return nil
+		}
+	}
+	errs, warns := operator.CheckWatchNamespace(csv, sets.NewString(string(mode)))
+	for _, warn := range warns {
+		log.Warn(warn)
+	}
+	if len(errs) != 0 {
+		msgs := make([]string, len(errs))
+		for j, err := range errs {
+			msgs[j] = err.Error()
+		}
+		return fmt.Errorf("operator %q cannot be installed in install mode %q:\n%s",
+			csv.GetName(), mode, strings.Join(msgs, "\n"))
+	}
+	return nil
+}
+
+// setupCatalog sets up the installation of the head of the default channel
+// of the single package in the file-based catalog i.CatalogDir.
+func (i *Install) setupCatalog(ctx context.Context) error {
+	catalog, err := declcfg.LoadDir(i.CatalogDir)
+	if err != nil {
+		return fmt.Errorf("load catalog: %v", err)
+	}
+	if len(catalog.Packages) != 1 {
+		return fmt.Errorf("catalog %s must contain exactly one package, found %d", i.CatalogDir, len(catalog.Packages))
+	}
+	if err := declcfg.Validate(catalog); err != nil {
+		return fmt.Errorf("invalid catalog %s: %v", i.CatalogDir, err)
+	}
+
+	pkg := catalog.Packages[0]
+	head, err := catalog.ChannelHead(pkg.Name, pkg.DefaultChannel)
+	if err != nil {
+		return err
+	}
+	csv, err := i.catalogCSV(ctx, catalog, pkg.Name, head)
+	if err != nil {
+		return err
+	}
+
+	if err := i.checkInstallMode(csv); err != nil {
+		return err
+	}
+
+	i.OperatorInstaller.PackageName = pkg.Name
+	i.OperatorInstaller.CatalogSourceName = operator.CatalogNameForPackage(pkg.Name)
+	i.OperatorInstaller.StartingCSV = csv.GetName()
+	i.OperatorInstaller.SupportedInstallModes = operator.GetSupportedInstallModes(csv.Spec.InstallModes)
+	i.OperatorInstaller.Channel = pkg.DefaultChannel
 
+	i.fileBasedCatalogCreator.PackageName = pkg.Name
+	i.fileBasedCatalogCreator.Catalog = catalog
+	i.fileBasedCatalogCreator.IndexImage = i.IndexImage
+	i.fileBasedCatalogCreator.SecretNames = i.SecretNames
+	i.fileBasedCatalogCreator.Mirrors = i.PullOptions.Mirrors
+	i.OperatorInstaller.CatalogCreator = i.fileBasedCatalogCreator
 	return nil
 }
+
+// catalogCSV returns the CSV of the bundle named name of package pkg in catalog,
+// from the bundle's olm.bundle.object properties or else its image.
+func (i Install) catalogCSV(ctx context.Context, catalog *declcfg.DeclarativeConfig, pkg, name string) (*v1alpha1.ClusterServiceVersion, error) {
+	for _, b := range catalog.Bundles {
+		if b.Package != pkg || b.Name != name {
+			continue
+		}
+		objs, err := b.Objects()
+		if err != nil {
+			return nil, fmt.Errorf("bundle %s: %v", name, err)
+		}
+		for _, obj := range objs {
+			typeMeta := metav1.TypeMeta{}
+			if err := json.Unmarshal(obj, &typeMeta); err != nil {
+				return nil, fmt.Errorf("bundle %s: error decoding object: %v", name, err)
+			}
+			if typeMeta.Kind != v1alpha1.ClusterServiceVersionKind {
+				continue
+			}
+			csv := &v1alpha1.ClusterServiceVersion{}
+			if err := json.Unmarshal(obj, csv); err != nil {
+				return nil, fmt.Errorf("bundle %s: error decoding CSV: %v", name, err)
+			}
+			return csv, nil
+		}
+		_, bundle, err := i.loadBundle(ctx, b.Image)
+		if err != nil {
+			return nil, fmt.Errorf("bundle %s: %v", name, err)
+		}
+		return bundle.CSV, nil
+	}
+	return nil, fmt.Errorf("bundle %s of package %s not found in catalog", name, pkg)
+}
+
+// loadBundle loads bundle image from its mirror, if any, pulled with the pull options.
+func (i Install) loadBundle(ctx context.Context, image string) (registryutil.Labels, *apimanifests.Bundle, error) {
+	regOpts, err := i.PullOptions.RegistryOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+	return operator.LoadBundle(ctx, i.PullOptions.Mirrors.Apply(image), regOpts...)
+}
+
+// packageManifestForBundle returns a package manifest whose channels, from
+// the bundle labels, all have the bundle's CSV as their head.
+func packageManifestForBundle(labels registryutil.Labels, csvName string) *apimanifests.PackageManifest {
+	pkg := &apimanifests.PackageManifest{
+		PackageName:        labels[registrybundle.PackageLabel],
+		DefaultChannelName: labels[registrybundle.ChannelDefaultLabel],
+	}
+	for _, channel := range strings.Split(labels[registrybundle.ChannelsLabel], ",") {
+		pkg.Channels = append(pkg.Channels, apimanifests.PackageChannel{Name: channel, CurrentCSVName: csvName})
+	}
+	if pkg.DefaultChannelName == "" {
+		pkg.DefaultChannelName = pkg.Channels[0].Name
+	}
+	return pkg
+}
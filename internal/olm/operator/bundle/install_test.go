@@ -0,0 +1,141 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/declcfg"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+var _ = Describe("Install", func() {
+	Describe("setup", func() {
+		It("serves a bundle directory from ConfigMaps", func() {
+			i := NewInstall(&operator.Configuration{Namespace: "default"})
+			i.BundleDir = filepath.Join("..", "..", "..", "scorecard", "testdata", "bundle")
+			Expect(i.setup(context.TODO())).To(Succeed())
+
+			Expect(i.OperatorInstaller.PackageName).To(Equal("memcached-operator"))
+			Expect(i.OperatorInstaller.Channel).To(Equal("alpha"))
+			Expect(i.OperatorInstaller.CatalogCreator).To(BeAssignableToTypeOf(&registry.ConfigMapCatalogCreator{}))
+
+			pkg := i.configMapCatalogCreator.Package
+			Expect(pkg.PackageName).To(Equal("memcached-operator"))
+			Expect(pkg.DefaultChannelName).To(Equal("stable"))
+			Expect(pkg.Channels).To(Equal([]apimanifests.PackageChannel{
+				{Name: "alpha", CurrentCSVName: i.OperatorInstaller.StartingCSV},
+				{Name: "stable", CurrentCSVName: i.OperatorInstaller.StartingCSV},
+			}))
+			Expect(i.configMapCatalogCreator.Bundles).To(HaveLen(1))
+		})
+		It("rejects index image and pull secret flags with a bundle directory", func() {
+			i := NewInstall(&operator.Configuration{Namespace: "default"})
+			i.BundleDir = filepath.Join("..", "..", "..", "scorecard", "testdata", "bundle")
+			i.IndexImage = "quay.io/example/index:v0.1.0"
+			Expect(i.setup(context.TODO())).To(MatchError("--index-image cannot be used with a bundle directory"))
+
+			i.IndexImage = registry.DefaultIndexImage
+			i.SecretNames = []string{"registry"}
+			Expect(i.setup(context.TODO())).To(MatchError("--secret-name cannot be used with a bundle directory"))
+		})
+		It("serves a file-based catalog directory", func() {
+			bundle, err := operator.LoadBundleRef(context.TODO(), filepath.Join("..", "..", "..", "scorecard", "testdata", "bundle"))
+			Expect(err).NotTo(HaveOccurred())
+			bundle.BundleImage = "quay.io/example/memcached-operator-bundle:v0.0.1"
+			catalog, err := declcfg.Render([]*apimanifests.Bundle{bundle})
+			Expect(err).NotTo(HaveOccurred())
+
+			dir, err := ioutil.TempDir("", "catalog")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			f, err := os.Create(filepath.Join(dir, "index.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(declcfg.WriteJSON(catalog, f)).To(Succeed())
+			Expect(f.Close()).To(Succeed())
+
+			i := NewInstall(&operator.Configuration{Namespace: "default"})
+			i.CatalogDir = dir
+			i.IndexImage = registry.DefaultIndexImage
+			Expect(i.setup(context.TODO())).To(Succeed())
+
+			Expect(i.OperatorInstaller.PackageName).To(Equal("memcached-operator"))
+			Expect(i.OperatorInstaller.Channel).To(Equal("stable"))
+			Expect(i.OperatorInstaller.StartingCSV).To(Equal("memcached-operator.v0.0.1"))
+			Expect(i.OperatorInstaller.CatalogCreator).To(BeAssignableToTypeOf(&registry.FileBasedCatalogCreator{}))
+			Expect(i.fileBasedCatalogCreator.IndexImage).To(Equal(registry.DefaultIndexImage))
+			Expect(i.fileBasedCatalogCreator.Catalog.Bundles).To(HaveLen(1))
+
+			i.SecretNames = []string{"registry"}
+			i.PullOptions.Mirrors = registryutil.ImageMirrors{{Source: "quay.io/example", Mirror: "localhost:5000/example"}}
+			Expect(i.setup(context.TODO())).To(Succeed())
+			Expect(i.fileBasedCatalogCreator.SecretNames).To(Equal([]string{"registry"}))
+			Expect(i.fileBasedCatalogCreator.Mirrors).To(Equal(i.PullOptions.Mirrors))
+
+			i.PullOptions.CAFile = "ca.crt"
+			Expect(i.setup(context.TODO())).To(MatchError(ContainSubstring("--ca-file cannot be used with a catalog directory")))
+
+			i.DependencyImages = []string{"quay.io/example/etcd-operator-bundle:v0.1.0"}
+			Expect(i.setup(context.TODO())).To(MatchError(ContainSubstring("cannot be run with a catalog directory")))
+		})
+		It("fails for a directory without bundle metadata", func() {
+			i := NewInstall(&operator.Configuration{Namespace: "default"})
+			i.BundleDir = "."
+			Expect(i.setup(context.TODO())).To(MatchError(ContainSubstring("load bundle metadata")))
+		})
+	})
+
+	Describe("checkInstallMode", func() {
+		var csv *v1alpha1.ClusterServiceVersion
+
+		BeforeEach(func() {
+			csv = &v1alpha1.ClusterServiceVersion{}
+			csv.SetName("memcached-operator.v0.0.1")
+			csv.Spec.InstallModes = []v1alpha1.InstallMode{
+				{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+				{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: true},
+			}
+			spec := v1alpha1.StrategyDeploymentSpec{Name: "memcached-operator"}
+			spec.Spec.Template.Spec.Containers = []corev1.Container{{
+				Name: "manager",
+				Env:  []corev1.EnvVar{{Name: "WATCH_NAMESPACE", Value: ""}},
+			}}
+			csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{spec}
+		})
+
+		It("checks WATCH_NAMESPACE against the default install mode", func() {
+			i := NewInstall(&operator.Configuration{Namespace: "default"})
+			Expect(i.checkInstallMode(csv)).To(Succeed())
+		})
+		It("checks WATCH_NAMESPACE against the given install mode", func() {
+			i := NewInstall(&operator.Configuration{Namespace: "default"})
+			Expect(i.InstallMode.Set(string(v1alpha1.InstallModeTypeOwnNamespace))).To(Succeed())
+			err := i.checkInstallMode(csv)
+			Expect(err).To(MatchError(ContainSubstring(`operator "memcached-operator.v0.0.1" cannot be installed in install mode "OwnNamespace"`)))
+			Expect(err).To(MatchError(ContainSubstring(`sets WATCH_NAMESPACE to an empty value`)))
+		})
+	})
+})
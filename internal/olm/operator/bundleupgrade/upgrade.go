@@ -32,7 +32,8 @@ type Upgrade struct {
 	*registry.IndexImageCatalogCreator
 	*registry.OperatorInstaller
 
-	cfg *operator.Configuration
+	subscriptionConfig operator.SubscriptionConfig
+	cfg                *operator.Configuration
 }
 
 func NewUpgrade(cfg *operator.Configuration) Upgrade {
@@ -51,6 +52,7 @@ func (u *Upgrade) BindFlags(fs *pflag.FlagSet) {
 	_ = fs.MarkHidden("mode")
 
 	u.IndexImageCatalogCreator.BindFlags(fs)
+	u.subscriptionConfig.BindFlags(fs)
 }
 
 func (u Upgrade) Run(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
@@ -69,7 +71,17 @@ func (u *Upgrade) setup(ctx context.Context) error {
 		}
 	}
 
-	labels, bundle, err := operator.LoadBundle(ctx, u.BundleImage)
+	// The existing subscription config is kept unless a new one is set.
+	var err error
+	if u.OperatorInstaller.SubscriptionConfig, err = u.subscriptionConfig.Build(); err != nil {
+		return err
+	}
+
+	regOpts, err := u.PullOptions.RegistryOptions()
+	if err != nil {
+		return err
+	}
+	labels, bundle, err := operator.LoadBundle(ctx, u.PullOptions.Mirrors.Apply(u.BundleImage), regOpts...)
 	if err != nil {
 		return err
 	}
@@ -16,26 +16,40 @@ package operator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+	"sigs.k8s.io/yaml"
 
+	"github.com/operator-framework/operator-sdk/internal/olm/graph"
 	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 )
 
 const (
 	SDKOperatorGroupName = "operator-sdk-og"
+
+	// dependenciesFile is the optional file in a bundle's metadata directory
+	// declaring the bundle's dependencies on other packages and APIs.
+	dependenciesFile = "dependencies.yaml"
 )
 
 func CatalogNameForPackage(pkg string) string {
 	return fmt.Sprintf("%s-catalog", pkg)
 }
 
-// LoadBundle returns metadata and manifests from within bundleImage.
-func LoadBundle(ctx context.Context, bundleImage string) (registryutil.Labels, *apimanifests.Bundle, error) {
-	bundlePath, err := registryutil.ExtractBundleImage(ctx, nil, bundleImage, false)
+// LoadBundle returns metadata and manifests from within bundleImage,
+// pulled from a registry configured by regOpts.
+func LoadBundle(ctx context.Context, bundleImage string,
+	regOpts ...containerdregistry.RegistryOption) (registryutil.Labels, *apimanifests.Bundle, error) {
+	bundlePath, err := registryutil.ExtractBundleImage(ctx, nil, bundleImage, false, regOpts...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("pull bundle image: %v", err)
 	}
@@ -43,7 +57,12 @@ func LoadBundle(ctx context.Context, bundleImage string) (registryutil.Labels, *
 		_ = os.RemoveAll(bundlePath)
 	}()
 
-	labels, _, err := registryutil.FindBundleMetadata(bundlePath)
+	return LoadBundleDir(bundlePath)
+}
+
+// LoadBundleDir returns metadata and manifests from within the bundle directory bundlePath.
+func LoadBundleDir(bundlePath string) (registryutil.Labels, *apimanifests.Bundle, error) {
+	labels, annotationsPath, err := registryutil.FindBundleMetadata(bundlePath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("load bundle metadata: %v", err)
 	}
@@ -57,6 +76,86 @@ func LoadBundle(ctx context.Context, bundleImage string) (registryutil.Labels, *
 	if err != nil {
 		return nil, nil, fmt.Errorf("load bundle: %v", err)
 	}
+	if bundle.Dependencies, err = loadDependencies(filepath.Dir(annotationsPath)); err != nil {
+		return nil, nil, fmt.Errorf("load bundle dependencies: %v", err)
+	}
 
 	return labels, bundle, nil
 }
+
+// LoadBundleRef loads the bundle directory or image bundleRef, and sets the
+// bundle's package and channels from its metadata.
+func LoadBundleRef(ctx context.Context, bundleRef string) (*apimanifests.Bundle, error) {
+	var (
+		labels  registryutil.Labels
+		bundle  *apimanifests.Bundle
+		isImage bool
+		err     error
+	)
+	if info, serr := os.Stat(bundleRef); serr == nil && info.IsDir() {
+		labels, bundle, err = LoadBundleDir(bundleRef)
+	} else {
+		isImage = true
+		labels, bundle, err = LoadBundle(ctx, bundleRef)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if isImage {
+		bundle.BundleImage = bundleRef
+	}
+
+	bundle.Package = labels[registrybundle.PackageLabel]
+	if value := labels[registrybundle.ChannelsLabel]; value != "" {
+		bundle.Channels = strings.Split(value, ",")
+	}
+	bundle.DefaultChannel = labels[registrybundle.ChannelDefaultLabel]
+	return bundle, nil
+}
+
+// LoadBundleRefs loads the bundle directories and images in bundleRefs as
+// LoadBundleRef does, or the bundles of a package manifests directory if it
+// is the only ref.
+func LoadBundleRefs(ctx context.Context, bundleRefs []string) ([]*apimanifests.Bundle, error) {
+	if len(bundleRefs) == 1 {
+		if info, err := os.Stat(bundleRefs[0]); err == nil && info.IsDir() {
+			_, _, err := registryutil.FindBundleMetadata(bundleRefs[0])
+			if errors.As(err, new(registryutil.MetadataNotFoundError)) {
+				return graph.LoadPackageManifests(bundleRefs[0])
+			}
+		}
+	}
+
+	var bundles []*apimanifests.Bundle
+	for _, ref := range bundleRefs {
+		b, err := LoadBundleRef(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("error loading bundle %s: %v", ref, err)
+		}
+		bundles = append(bundles, b)
+	}
+	return bundles, nil
+}
+
+// loadDependencies returns the dependencies in the dependencies.yaml file of
+// the bundle metadata directory metadataDir, if any. Dependency values are
+// kept as JSON, to be decoded by type.
+func loadDependencies(metadataDir string) ([]*apimanifests.Dependency, error) {
+	b, err := ioutil.ReadFile(filepath.Join(metadataDir, dependenciesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	file := registry.DependenciesFile{}
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return nil, err
+	}
+	deps := make([]*apimanifests.Dependency, len(file.Dependencies))
+	for i, d := range file.Dependencies {
+		deps[i] = &apimanifests.Dependency{Type: d.Type, Value: string(d.Value)}
+	}
+	return deps, nil
+}
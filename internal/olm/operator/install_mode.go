@@ -21,10 +21,17 @@ import (
 	"strings"
 
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 )
 
+// targetNamespacesFieldPath is the downward API field path of the annotation OLM
+// sets on operator pods to the target namespaces of their OperatorGroup.
+const targetNamespacesFieldPath = "metadata.annotations['olm.targetNamespaces']"
+
 type InstallMode struct {
 	InstallModeType  v1alpha1.InstallModeType
 	TargetNamespaces []string
@@ -140,3 +147,113 @@ func GetSupportedInstallModes(csvInstallModes []v1alpha1.InstallMode) sets.Strin
 	}
 	return supported
 }
+
+// DefaultInstallModeType returns the install mode type used to install an operator
+// when none is given, which is the first of AllNamespaces, OwnNamespace, SingleNamespace
+// and MultiNamespace in supported, or an empty type if none of them are.
+func DefaultInstallModeType(supported sets.String) v1alpha1.InstallModeType {
+	for _, t := range []v1alpha1.InstallModeType{
+		v1alpha1.InstallModeTypeAllNamespaces,
+		v1alpha1.InstallModeTypeOwnNamespace,
+		v1alpha1.InstallModeTypeSingleNamespace,
+		v1alpha1.InstallModeTypeMultiNamespace,
+	} {
+		if supported.Has(string(t)) {
+			return t
+		}
+	}
+	return ""
+}
+
+// InstallModeTypeForTargets returns the install mode type an operator in operatorNamespace
+// must support to be installed by an OperatorGroup targeting targetNamespaces.
+func InstallModeTypeForTargets(targetNamespaces []string, operatorNamespace string) v1alpha1.InstallModeType {
+	switch {
+	case len(targetNamespaces) == 0 || len(targetNamespaces) == 1 && targetNamespaces[0] == "":
+		return v1alpha1.InstallModeTypeAllNamespaces
+	case len(targetNamespaces) == 1 && targetNamespaces[0] == operatorNamespace:
+		return v1alpha1.InstallModeTypeOwnNamespace
+	case len(targetNamespaces) == 1:
+		return v1alpha1.InstallModeTypeSingleNamespace
+	default:
+		return v1alpha1.InstallModeTypeMultiNamespace
+	}
+}
+
+// CheckWatchNamespace checks that the deployments of csv set WATCH_NAMESPACE such that
+// their operators watch the target namespaces of each install mode type in modes.
+// Errors are returned for values that cannot match those target namespaces,
+// and warnings for a missing WATCH_NAMESPACE or one that may not.
+func CheckWatchNamespace(csv *v1alpha1.ClusterServiceVersion, modes sets.String) (errs, warns []error) {
+	namespaced := modes.Intersection(sets.NewString(
+		string(v1alpha1.InstallModeTypeOwnNamespace),
+		string(v1alpha1.InstallModeTypeSingleNamespace),
+		string(v1alpha1.InstallModeTypeMultiNamespace),
+	))
+	for _, dep := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		found := false
+		for _, c := range dep.Spec.Template.Spec.Containers {
+			for _, ev := range c.Env {
+				if ev.Name != k8sutil.WatchNamespaceEnvVar {
+					continue
+				}
+				found = true
+				if err := checkWatchNamespaceEnv(dep.Name, c.Name, ev, modes); err != nil {
+					errs = append(errs, err)
+				} else if modes.Has(string(v1alpha1.InstallModeTypeMultiNamespace)) && isTargetNamespacesRef(ev) {
+					warns = append(warns, fmt.Errorf("deployment %q container %q is given a comma-separated list of "+
+						"namespaces in %s in install mode %s; ensure the operator watches each of them, "+
+						"or do not support that install mode",
+						dep.Name, c.Name, k8sutil.WatchNamespaceEnvVar, v1alpha1.InstallModeTypeMultiNamespace))
+				}
+			}
+		}
+		if !found && namespaced.Len() != 0 {
+			warns = append(warns, fmt.Errorf("deployment %q does not set %s, so its operator cannot be restricted "+
+				"to the target namespaces of install modes %q; set %s from field %q in the operator container, "+
+				"or only support install mode %s",
+				dep.Name, k8sutil.WatchNamespaceEnvVar, namespaced.List(), k8sutil.WatchNamespaceEnvVar,
+				targetNamespacesFieldPath, v1alpha1.InstallModeTypeAllNamespaces))
+		}
+	}
+	return errs, warns
+}
+
+// checkWatchNamespaceEnv returns an error if ev, the WATCH_NAMESPACE env var of
+// container containerName in deployment depName, does not match the target namespaces
+// of one of the install mode types in modes.
+func checkWatchNamespaceEnv(depName, containerName string, ev corev1.EnvVar, modes sets.String) error {
+	var (
+		value        string
+		incompatible sets.String
+	)
+	switch {
+	case isTargetNamespacesRef(ev):
+		return nil
+	case ev.ValueFrom == nil && ev.Value == "":
+		// An empty WATCH_NAMESPACE watches all namespaces.
+		value = "an empty value"
+		incompatible = modes.Difference(sets.NewString(string(v1alpha1.InstallModeTypeAllNamespaces)))
+	case ev.ValueFrom == nil:
+		value = fmt.Sprintf("the static value %q", ev.Value)
+		incompatible = modes
+	case ev.ValueFrom.FieldRef != nil && ev.ValueFrom.FieldRef.FieldPath == "metadata.namespace":
+		value = "the operator's namespace"
+		incompatible = modes.Difference(sets.NewString(string(v1alpha1.InstallModeTypeOwnNamespace)))
+	default:
+		value = "a value not set by OLM"
+		incompatible = modes
+	}
+	if incompatible.Len() == 0 {
+		return nil
+	}
+	return fmt.Errorf("deployment %q container %q sets %s to %s, which does not match the target namespaces "+
+		"of install modes %q; set it from field %q",
+		depName, containerName, k8sutil.WatchNamespaceEnvVar, value, incompatible.List(), targetNamespacesFieldPath)
+}
+
+// isTargetNamespacesRef returns true if ev is set from the target namespaces annotation.
+func isTargetNamespacesRef(ev corev1.EnvVar) bool {
+	return ev.ValueFrom != nil && ev.ValueFrom.FieldRef != nil &&
+		ev.ValueFrom.FieldRef.FieldPath == targetNamespacesFieldPath
+}
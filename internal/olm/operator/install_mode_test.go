@@ -18,6 +18,8 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 var _ = Describe("InstallMode", func() {
@@ -63,4 +65,94 @@ var _ = Describe("InstallMode", func() {
 			Expect(supported.Has(string(v1alpha1.InstallModeTypeAllNamespaces))).Should(BeFalse())
 		})
 	})
+
+	Describe("DefaultInstallModeType", func() {
+		It("should prefer AllNamespaces, then OwnNamespace", func() {
+			Expect(DefaultInstallModeType(sets.NewString("OwnNamespace", "AllNamespaces"))).
+				To(Equal(v1alpha1.InstallModeTypeAllNamespaces))
+			Expect(DefaultInstallModeType(sets.NewString("SingleNamespace", "OwnNamespace"))).
+				To(Equal(v1alpha1.InstallModeTypeOwnNamespace))
+		})
+		It("should return an empty type if nothing is supported", func() {
+			Expect(DefaultInstallModeType(sets.NewString())).To(BeEmpty())
+		})
+	})
+
+	Describe("InstallModeTypeForTargets", func() {
+		It("should return the install mode type required by target namespaces", func() {
+			Expect(InstallModeTypeForTargets(nil, "ns")).To(Equal(v1alpha1.InstallModeTypeAllNamespaces))
+			Expect(InstallModeTypeForTargets([]string{""}, "ns")).To(Equal(v1alpha1.InstallModeTypeAllNamespaces))
+			Expect(InstallModeTypeForTargets([]string{"ns"}, "ns")).To(Equal(v1alpha1.InstallModeTypeOwnNamespace))
+			Expect(InstallModeTypeForTargets([]string{"other"}, "ns")).To(Equal(v1alpha1.InstallModeTypeSingleNamespace))
+			Expect(InstallModeTypeForTargets([]string{"ns", "other"}, "ns")).To(Equal(v1alpha1.InstallModeTypeMultiNamespace))
+		})
+	})
+
+	Describe("CheckWatchNamespace", func() {
+		var csv *v1alpha1.ClusterServiceVersion
+
+		setEnv := func(envs ...corev1.EnvVar) {
+			csv = &v1alpha1.ClusterServiceVersion{}
+			spec := v1alpha1.StrategyDeploymentSpec{Name: "operator"}
+			spec.Spec.Template.Spec.Containers = []corev1.Container{
+				{Name: "proxy"},
+				{Name: "manager", Env: envs},
+			}
+			csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{spec}
+		}
+		fieldRef := func(path string) corev1.EnvVar {
+			return corev1.EnvVar{
+				Name:      "WATCH_NAMESPACE",
+				ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: path}},
+			}
+		}
+
+		It("should accept WATCH_NAMESPACE set from the target namespaces annotation", func() {
+			setEnv(fieldRef("metadata.annotations['olm.targetNamespaces']"))
+			errs, warns := CheckWatchNamespace(csv, sets.NewString("OwnNamespace", "SingleNamespace", "AllNamespaces"))
+			Expect(errs).To(BeEmpty())
+			Expect(warns).To(BeEmpty())
+		})
+		It("should warn about MultiNamespace with WATCH_NAMESPACE set from the target namespaces annotation", func() {
+			setEnv(fieldRef("metadata.annotations['olm.targetNamespaces']"))
+			errs, warns := CheckWatchNamespace(csv, sets.NewString("MultiNamespace"))
+			Expect(errs).To(BeEmpty())
+			Expect(warns).To(HaveLen(1))
+			Expect(warns[0].Error()).To(ContainSubstring("comma-separated list"))
+		})
+		It("should warn if a deployment does not set WATCH_NAMESPACE in namespaced install modes", func() {
+			setEnv()
+			errs, warns := CheckWatchNamespace(csv, sets.NewString("OwnNamespace", "AllNamespaces"))
+			Expect(errs).To(BeEmpty())
+			Expect(warns).To(HaveLen(1))
+			Expect(warns[0].Error()).To(ContainSubstring(`deployment "operator" does not set WATCH_NAMESPACE`))
+
+			errs, warns = CheckWatchNamespace(csv, sets.NewString("AllNamespaces"))
+			Expect(errs).To(BeEmpty())
+			Expect(warns).To(BeEmpty())
+		})
+		It("should return an error for WATCH_NAMESPACE set to the operator's namespace", func() {
+			setEnv(fieldRef("metadata.namespace"))
+			errs, _ := CheckWatchNamespace(csv, sets.NewString("OwnNamespace"))
+			Expect(errs).To(BeEmpty())
+
+			errs, _ = CheckWatchNamespace(csv, sets.NewString("OwnNamespace", "MultiNamespace"))
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Error()).To(ContainSubstring(`container "manager" sets WATCH_NAMESPACE to the operator's namespace`))
+			Expect(errs[0].Error()).To(ContainSubstring(`["MultiNamespace"]`))
+		})
+		It("should return an error for a static WATCH_NAMESPACE", func() {
+			setEnv(corev1.EnvVar{Name: "WATCH_NAMESPACE"})
+			errs, _ := CheckWatchNamespace(csv, sets.NewString("AllNamespaces"))
+			Expect(errs).To(BeEmpty())
+			errs, _ = CheckWatchNamespace(csv, sets.NewString("OwnNamespace", "AllNamespaces"))
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Error()).To(ContainSubstring("an empty value"))
+
+			setEnv(corev1.EnvVar{Name: "WATCH_NAMESPACE", Value: "foo"})
+			errs, _ = CheckWatchNamespace(csv, sets.NewString("AllNamespaces"))
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Error()).To(ContainSubstring(`the static value "foo"`))
+		})
+	})
 })
@@ -34,7 +34,8 @@ type Install struct {
 	*registry.ConfigMapCatalogCreator
 	*registry.OperatorInstaller
 
-	cfg *operator.Configuration
+	subscriptionConfig operator.SubscriptionConfig
+	cfg                *operator.Configuration
 }
 
 func NewInstall(cfg *operator.Configuration) Install {
@@ -50,6 +51,8 @@ func NewInstall(cfg *operator.Configuration) Install {
 func (i *Install) BindFlags(fs *pflag.FlagSet) {
 	fs.Var(&i.InstallMode, "install-mode", "install mode")
 	fs.StringVar(&i.Version, "version", "", "Packaged version of the operator to deploy")
+	i.subscriptionConfig.BindFlags(fs)
+	i.OperatorInstaller.BindProgressFlags(fs)
 }
 
 func (i Install) Run(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
@@ -59,7 +62,11 @@ func (i Install) Run(ctx context.Context) (*v1alpha1.ClusterServiceVersion, erro
 	return i.InstallOperator(ctx)
 }
 
-func (i *Install) setup() error {
+func (i *Install) setup() (err error) {
+	if i.OperatorInstaller.SubscriptionConfig, err = i.subscriptionConfig.Build(); err != nil {
+		return err
+	}
+
 	pkg, bundles, err := loadPackageManifests(i.PackageManifestsDirectory)
 	if err != nil {
 		return fmt.Errorf("load package manifests: %v", err)
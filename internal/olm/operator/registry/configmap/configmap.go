@@ -45,6 +45,30 @@ func (rr *RegistryResources) getRegistryConfigMaps(ctx context.Context, namespac
 	return list.Items, nil
 }
 
+// MaxDataSize is the largest total size of the data of a ConfigMap accepted
+// by the API server.
+const MaxDataSize = 1024 * 1024
+
+// CheckDataSizes returns an error if a ConfigMap of the registry of pkg and
+// bundles would hold more than MaxDataSize bytes.
+func CheckDataSizes(pkg *apimanifests.PackageManifest, bundles []*apimanifests.Bundle) error {
+	binaryDataByConfigMap, err := makeConfigMapsForPackageManifests(pkg, bundles)
+	if err != nil {
+		return err
+	}
+	for name, binaryData := range binaryDataByConfigMap {
+		size := 0
+		for key, data := range binaryData {
+			size += len(key) + len(data)
+		}
+		if size > MaxDataSize {
+			return fmt.Errorf("ConfigMap %s would hold %d bytes, more than the %d bytes a ConfigMap can hold",
+				name, size, MaxDataSize)
+		}
+	}
+	return nil
+}
+
 // makeConfigMapsForPackageManifests creates a set of ConfigMap binary data
 // for a given PackageManifest and Bundles. Each ConfigMaps's binary data is
 // indexed by the ConfigMap's name.
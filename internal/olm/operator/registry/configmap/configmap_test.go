@@ -233,6 +233,13 @@ var _ = Describe("ConfigMap", func() {
 
 			Expect(binaryDataByConfigMap).Should(Equal(val))
 		})
+		It("should check that the data of each ConfigMap fits in it", func() {
+			Expect(CheckDataSizes(&p, b)).To(Succeed())
+
+			b[0].Objects[0].Object["val1"] = strings.Repeat("a", MaxDataSize)
+			Expect(CheckDataSizes(&p, b[:1])).To(MatchError(ContainSubstring(
+				fmt.Sprintf("more than the %d bytes a ConfigMap can hold", MaxDataSize))))
+		})
 
 	})
 
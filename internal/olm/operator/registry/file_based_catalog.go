@@ -0,0 +1,135 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/declcfg"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry/configmap"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry/index"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+// FileBasedCatalogCreator serves a file-based catalog from ConfigMaps
+// mounted in a registry pod running `opm serve`.
+type FileBasedCatalogCreator struct {
+	PackageName string
+	Catalog     *declcfg.DeclarativeConfig
+	// IndexImage is the image of the registry pod, which must contain opm.
+	IndexImage string
+	// SecretNames are image pull secrets of private registries of the index and bundle images,
+	// used to pull the index image and set on the catalog source for OLM to pull bundle images.
+	SecretNames []string
+	// Mirrors replace the registries or repositories of the index image and of
+	// the bundle images of Catalog. Images are stored in annotations as given.
+	Mirrors registryutil.ImageMirrors
+
+	cfg *operator.Configuration
+}
+
+var _ CatalogCreator = &FileBasedCatalogCreator{}
+
+func NewFileBasedCatalogCreator(cfg *operator.Configuration) *FileBasedCatalogCreator {
+	return &FileBasedCatalogCreator{
+		cfg: cfg,
+	}
+}
+
+func (c FileBasedCatalogCreator) CreateCatalog(ctx context.Context, name string) (*v1alpha1.CatalogSource, error) {
+	// Split the catalog before creating anything, so that a catalog too large
+	// for ConfigMaps fails without leaving a catalog source behind.
+	chunks, err := declcfg.SplitJSON(mirrorCatalog(c.Catalog, c.Mirrors), configmap.MaxDataSize)
+	if err != nil {
+		return nil, fmt.Errorf("error splitting catalog into ConfigMaps, serve it from an index image instead: %v", err)
+	}
+
+	cs := newCatalogSource(name, c.cfg.Namespace,
+		withSDKPublisher(c.PackageName),
+		withSecrets(c.SecretNames...),
+	)
+	if err := c.cfg.Client.Create(ctx, cs); err != nil {
+		return nil, fmt.Errorf("error creating catalog source: %v", err)
+	}
+
+	var cmNames []string
+	for i, chunk := range chunks {
+		cm, err := c.newCatalogConfigMap(cs, i, chunk)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.cfg.Client.Create(ctx, cm); err != nil {
+			return nil, fmt.Errorf("error creating catalog ConfigMap: %v", err)
+		}
+		cmNames = append(cmNames, cm.GetName())
+	}
+
+	if c.IndexImage == "" {
+		c.IndexImage = DefaultIndexImage
+	}
+	registryPod := index.RegistryPod{
+		IndexImage:            c.Mirrors.Apply(c.IndexImage),
+		CatalogConfigMapNames: cmNames,
+		ImagePullSecrets:      c.SecretNames,
+	}
+	pod, err := registryPod.Create(ctx, c.cfg, cs)
+	if err != nil {
+		return nil, fmt.Errorf("error creating registry pod: %v", err)
+	}
+
+	key := types.NamespacedName{Namespace: cs.GetNamespace(), Name: cs.GetName()}
+	if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := c.cfg.Client.Get(ctx, key, cs); err != nil {
+			return err
+		}
+		updateCatalogSourceFields(cs, pod, map[string]string{
+			indexImageAnnotation:      c.IndexImage,
+			registryPodNameAnnotation: pod.GetName(),
+		})
+		return c.cfg.Client.Update(ctx, cs)
+	}); err != nil {
+		return nil, fmt.Errorf("error updating catalog source: %w", err)
+	}
+
+	return cs, nil
+}
+
+// newCatalogConfigMap returns the ConfigMap, owned by cs, holding the i-th chunk of c.Catalog.
+func (c FileBasedCatalogCreator) newCatalogConfigMap(cs *v1alpha1.CatalogSource, i int, chunk []byte) (*corev1.ConfigMap, error) {
+	name := cs.GetName() + "-configs"
+	if i > 0 {
+		name = fmt.Sprintf("%s-%d", name, i)
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cs.GetNamespace(),
+		},
+		BinaryData: map[string][]byte{index.CatalogKey: chunk},
+	}
+	if err := controllerutil.SetOwnerReference(cs, cm, c.cfg.Scheme); err != nil {
+		return nil, fmt.Errorf("set catalog ConfigMap owner reference: %v", err)
+	}
+	return cm, nil
+}
@@ -39,6 +39,14 @@ const (
 	// defaultGRPCPort is the default grpc container port that the registry pod exposes
 	defaultGRPCPort = 50051
 	defaultDBPath   = "/database/index.db"
+	// defaultCatalogDir is where file-based catalog ConfigMaps are mounted,
+	// and CatalogKey is their key holding part of the catalog.
+	defaultCatalogDir = "/configs"
+	CatalogKey        = "catalog.json"
+	// caDir is where the CA ConfigMap is mounted, and CAKey is its key
+	// holding the root certificates of private registries.
+	caDir = "/etc/registry-ca"
+	CAKey = "ca.crt"
 
 	defaultContainerName     = "registry-grpc"
 	defaultContainerPortName = "grpc"
@@ -65,6 +73,14 @@ type RegistryPod struct {
 	// if an index image is provided, the existing registry DB is located at /database/index.db
 	DBPath string
 
+	// CatalogConfigMapNames are the names of ConfigMaps each holding part of a file-based catalog
+	// under CatalogKey, served with `opm serve` instead of a DB of BundleItems. IndexImage must
+	// then contain opm.
+	CatalogConfigMapNames []string
+
+	// CatalogDir is where the file-based catalog is mounted, /configs by default.
+	CatalogDir string
+
 	// GRPCPort is the container grpc port
 	GRPCPort int32
 
@@ -72,6 +88,16 @@ type RegistryPod struct {
 	// can pull bundle images from a private registry.
 	SecretName string
 
+	// ImagePullSecrets are the names of image pull secrets used to pull IndexImage.
+	ImagePullSecrets []string
+
+	// CAConfigMapName is the name of a ConfigMap holding root certificates under CAKey,
+	// trusted by `opm registry add` when pulling bundle images from private registries.
+	CAConfigMapName string
+
+	// SkipTLS pulls bundle images over plain HTTP or without verifying TLS certificates.
+	SkipTLS bool
+
 	// pod represents a kubernetes *corev1.pod that will be created on a cluster using an index image
 	pod *corev1.Pod
 
@@ -86,6 +112,9 @@ func (rp *RegistryPod) init(cfg *operator.Configuration) error {
 	if rp.DBPath == "" {
 		rp.DBPath = defaultDBPath
 	}
+	if rp.CatalogDir == "" {
+		rp.CatalogDir = defaultCatalogDir
+	}
 	rp.cfg = cfg
 
 	// validate the RegistryPod struct and ensure required fields are set
@@ -157,6 +186,15 @@ func (rp *RegistryPod) checkPodStatus(ctx context.Context, podCheck wait.Conditi
 // validate will ensure that RegistryPod required fields are set
 // and throws error if not set
 func (rp *RegistryPod) validate() error {
+	if len(rp.CatalogConfigMapNames) != 0 {
+		if len(rp.BundleItems) != 0 {
+			return errors.New("bundle images cannot be added to a file-based catalog")
+		}
+		if rp.IndexImage == "" {
+			return errors.New("index image cannot be empty")
+		}
+		return nil
+	}
 	if len(rp.BundleItems) == 0 {
 		return errors.New("bundle image set cannot be empty")
 	}
@@ -190,8 +228,14 @@ func getPodName(bundleImage string) string {
 // podForBundleRegistry constructs and returns the registry pod definition
 // and throws error when unable to build the pod definition successfully
 func (rp *RegistryPod) podForBundleRegistry() (*corev1.Pod, error) {
-	// rp was already validated so len(rp.BundleItems) must be greater than 0.
-	bundleImage := rp.BundleItems[len(rp.BundleItems)-1].ImageTag
+	// rp was already validated so len(rp.BundleItems) must be greater than 0
+	// unless a file-based catalog is served.
+	var podName string
+	if len(rp.CatalogConfigMapNames) != 0 {
+		podName = getPodName(rp.CatalogConfigMapNames[0])
+	} else {
+		podName = getPodName(rp.BundleItems[len(rp.BundleItems)-1].ImageTag)
+	}
 
 	// construct the container command for pod spec
 	containerCmd, err := rp.getContainerCmd()
@@ -202,7 +246,7 @@ func (rp *RegistryPod) podForBundleRegistry() (*corev1.Pod, error) {
 	// make the pod definition
 	rp.pod = &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      getPodName(bundleImage),
+			Name:      podName,
 			Namespace: rp.cfg.Namespace,
 		},
 		Spec: corev1.PodSpec{
@@ -224,7 +268,12 @@ func (rp *RegistryPod) podForBundleRegistry() (*corev1.Pod, error) {
 		},
 	}
 
+	for _, name := range rp.ImagePullSecrets {
+		rp.pod.Spec.ImagePullSecrets = append(rp.pod.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+	}
 	addImagePullSecret(rp.pod, rp.SecretName)
+	addCatalogConfigMaps(rp.pod, rp.CatalogConfigMapNames, rp.CatalogDir)
+	addConfigMap(rp.pod, rp.CAConfigMapName, caDir)
 
 	return rp.pod, nil
 }
@@ -262,6 +311,59 @@ func addImagePullSecret(pod *corev1.Pod, secretName string) {
 	}
 }
 
+// addCatalogConfigMaps mounts the catalog file of each file-based catalog ConfigMap
+// in cmNames as <cmName>.json in catalogDir, in each container in pod. Files are
+// mounted with subPath so that `opm serve` does not also load the `..data`
+// directory of ConfigMap volumes.
+func addCatalogConfigMaps(pod *corev1.Pod, cmNames []string, catalogDir string) {
+	for _, cmName := range cmNames {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: cmName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: cmName},
+					Items:                []corev1.KeyToPath{{Key: CatalogKey, Path: CatalogKey}},
+				},
+			},
+		})
+
+		volumeMount := corev1.VolumeMount{
+			Name:      cmName,
+			ReadOnly:  true,
+			MountPath: path.Join(catalogDir, cmName+".json"),
+			SubPath:   CatalogKey,
+		}
+		for i := range pod.Spec.Containers {
+			pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, volumeMount)
+		}
+	}
+}
+
+// addConfigMap mounts the ConfigMap cmName at dir in each container in pod.
+func addConfigMap(pod *corev1.Pod, cmName, dir string) {
+	if cmName == "" {
+		return
+	}
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: cmName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: cmName},
+			},
+		},
+	})
+
+	volumeMount := corev1.VolumeMount{
+		Name:      cmName,
+		ReadOnly:  true,
+		MountPath: dir,
+	}
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, volumeMount)
+	}
+}
+
 func newInt32(i int32) *int32 {
 	ip := new(int32)
 	*ip = i
@@ -274,11 +376,17 @@ func newBool(b bool) *bool {
 	return bp
 }
 
-const containerCommand = `/bin/mkdir -p {{ dirname .DBPath }} && \
+const containerCommand = `{{- if .CatalogConfigMapNames -}}
+/bin/opm serve {{ .CatalogDir }} -p {{ .GRPCPort }}
+{{- else -}}
+/bin/mkdir -p {{ dirname .DBPath }} && \
 {{- range $i, $item := .BundleItems }}
-/bin/opm registry add -d {{ $.DBPath }} -b {{ $item.ImageTag }} --mode={{ $item.AddMode }} && \
+/bin/opm registry add -d {{ $.DBPath }} -b {{ $item.ImageTag }} --mode={{ $item.AddMode }}
+{{- if $.CAConfigMapName }} --ca-file={{ caFile }}{{ end }}
+{{- if $.SkipTLS }} --skip-tls{{ end }} && \
 {{- end }}
 /bin/opm registry serve -d {{ .DBPath }} -p {{ .GRPCPort }}
+{{- end }}
 `
 
 // getContainerCmd uses templating to construct the container command
@@ -288,6 +396,7 @@ func (rp *RegistryPod) getContainerCmd() (string, error) {
 	// create a custom dirname template function
 	funcMap := template.FuncMap{
 		"dirname": path.Dir,
+		"caFile":  func() string { return path.Join(caDir, CAKey) },
 	}
 
 	// add the custom dirname template function to the
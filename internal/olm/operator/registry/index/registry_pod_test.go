@@ -157,6 +157,76 @@ var _ = Describe("RegistryPod", func() {
 					}))
 				}
 			})
+
+			It("pulls from private registries with pull secrets and a CA", func() {
+				rp.ImagePullSecrets = []string{"index-secret"}
+				rp.CAConfigMapName = "registry-ca"
+				rp.SkipTLS = true
+
+				pod, err = rp.podForBundleRegistry()
+				Expect(err).NotTo((HaveOccurred()))
+				Expect(pod.Spec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{{Name: "index-secret"}}))
+				Expect(pod.Spec.Volumes).To(Equal([]corev1.Volume{{
+					Name: "registry-ca",
+					VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "registry-ca"},
+					}},
+				}}))
+				container := pod.Spec.Containers[0]
+				Expect(container.VolumeMounts).To(Equal([]corev1.VolumeMount{
+					{Name: "registry-ca", ReadOnly: true, MountPath: "/etc/registry-ca"},
+				}))
+				Expect(container.Command[2]).To(ContainSubstring(fmt.Sprintf(
+					"/bin/opm registry add -d %s -b %s --mode=%s --ca-file=/etc/registry-ca/ca.crt --skip-tls && \\\n",
+					defaultDBPath, defaultBundleItem.ImageTag, defaultBundleItem.AddMode)))
+			})
+		})
+
+		Context("with a file-based catalog", func() {
+			It("serves the catalog ConfigMaps with opm", func() {
+				cfg := &operator.Configuration{
+					Client:    newFakeClient(),
+					Namespace: "test-default",
+				}
+				rp := &RegistryPod{
+					IndexImage: testIndexImageTag,
+					CatalogConfigMapNames: []string{
+						"memcached-operator-catalog-configs",
+						"memcached-operator-catalog-configs-1",
+					},
+				}
+				Expect(rp.init(cfg)).To(Succeed())
+
+				Expect(rp.pod.Name).To(Equal("memcached-operator-catalog-configs"))
+				container := rp.pod.Spec.Containers[0]
+				Expect(container.Command).To(Equal([]string{"/bin/sh", "-c", "/bin/opm serve /configs -p 50051\n"}))
+				var volumes []corev1.Volume
+				var mounts []corev1.VolumeMount
+				for _, name := range rp.CatalogConfigMapNames {
+					volumes = append(volumes, corev1.Volume{
+						Name: name,
+						VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: name},
+							Items:                []corev1.KeyToPath{{Key: "catalog.json", Path: "catalog.json"}},
+						}},
+					})
+					mounts = append(mounts, corev1.VolumeMount{
+						Name: name, ReadOnly: true, MountPath: "/configs/" + name + ".json", SubPath: "catalog.json",
+					})
+				}
+				Expect(rp.pod.Spec.Volumes).To(Equal(volumes))
+				Expect(container.VolumeMounts).To(Equal(mounts))
+			})
+
+			It("does not add bundle images to the catalog", func() {
+				rp := &RegistryPod{
+					BundleItems:           []BundleItem{defaultBundleItem},
+					IndexImage:            testIndexImageTag,
+					CatalogConfigMapNames: []string{"memcached-operator-catalog-configs"},
+				}
+				Expect(rp.init(&operator.Configuration{})).To(MatchError(ContainSubstring(
+					"bundle images cannot be added to a file-based catalog")))
+			})
 		})
 
 		Context("with invalid registry pod values", func() {
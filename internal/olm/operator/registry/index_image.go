@@ -63,7 +63,13 @@ type IndexImageCatalogCreator struct {
 	IndexImage    string
 	BundleImage   string
 	BundleAddMode index.BundleAddMode
-	SecretName    string
+	// SecretNames are image pull secrets of private registries of the index and bundle images.
+	SecretNames []string
+	// DependencyImages are bundle images added to a new catalog after BundleImage.
+	DependencyImages []string
+	// PullOptions configure how the index and bundle images are pulled. Images are stored
+	// in CatalogSource annotations as given, and replaced by their mirrors when pulled.
+	PullOptions registryutil.PullOptions
 
 	cfg *operator.Configuration
 }
@@ -78,17 +84,18 @@ func NewIndexImageCatalogCreator(cfg *operator.Configuration) *IndexImageCatalog
 }
 
 func (c *IndexImageCatalogCreator) BindFlags(fs *pflag.FlagSet) {
-	fs.StringVar(&c.SecretName, "secret-name", "",
-		"Name of image pull secret (\"type: kubernetes.io/dockerconfigjson\") required "+
-			"to pull bundle images. This secret *must* be both in the namespace and an "+
-			"imagePullSecret of the service account that this command is configured to run in")
+	fs.StringSliceVar(&c.SecretNames, "secret-name", nil,
+		"Name of an image pull secret (\"type: kubernetes.io/dockerconfigjson\") required "+
+			"to pull the index or bundle images from a private registry. This secret *must* be in the namespace. "+
+			"This flag can be specified multiple times")
+	c.PullOptions.BindFlags(fs)
 }
 
 func (c IndexImageCatalogCreator) CreateCatalog(ctx context.Context, name string) (*v1alpha1.CatalogSource, error) {
 	// Create a CatalogSource with displaName, publisher, and any secrets.
 	cs := newCatalogSource(name, c.cfg.Namespace,
 		withSDKPublisher(c.PackageName),
-		withSecrets(c.SecretName),
+		withSecrets(c.SecretNames...),
 	)
 	if err := c.cfg.Client.Create(ctx, cs); err != nil {
 		return nil, fmt.Errorf("error creating catalog source: %v", err)
@@ -97,6 +104,9 @@ func (c IndexImageCatalogCreator) CreateCatalog(ctx context.Context, name string
 	c.setAddMode()
 
 	newItems := []index.BundleItem{{ImageTag: c.BundleImage, AddMode: c.BundleAddMode}}
+	for _, image := range c.DependencyImages {
+		newItems = append(newItems, index.BundleItem{ImageTag: image, AddMode: c.BundleAddMode})
+	}
 	if err := c.createAnnotatedRegistry(ctx, cs, newItems); err != nil {
 		return nil, fmt.Errorf("error creating registry pod: %v", err)
 	}
@@ -141,8 +151,10 @@ func (c IndexImageCatalogCreator) UpdateCatalog(ctx context.Context, cs *v1alpha
 	}
 
 	// Add non-present secrets to the CatalogSource so private bundle images can be pulled.
-	if !gofunk.ContainsString(cs.Spec.Secrets, c.SecretName) {
-		opts = append(opts, withSecrets(c.SecretName))
+	for _, name := range c.SecretNames {
+		if !gofunk.ContainsString(cs.Spec.Secrets, name) {
+			opts = append(opts, withSecrets(name))
+		}
 	}
 
 	if err := c.createAnnotatedRegistry(ctx, cs, existingItems, opts...); err != nil {
@@ -180,11 +192,18 @@ func (c IndexImageCatalogCreator) createAnnotatedRegistry(ctx context.Context, c
 	if c.IndexImage == "" {
 		c.IndexImage = DefaultIndexImage
 	}
-	// Initialize and create registry pod
+	// Initialize and create registry pod, which pulls mirrored images.
 	registryPod := index.RegistryPod{
-		BundleItems: items,
-		IndexImage:  c.IndexImage,
-		SecretName:  c.SecretName,
+		BundleItems:      c.mirrorBundleItems(items),
+		IndexImage:       c.PullOptions.Mirrors.Apply(c.IndexImage),
+		ImagePullSecrets: c.SecretNames,
+		SkipTLS:          c.PullOptions.SkipTLS,
+	}
+	if registryPod.SecretName, err = c.ensurePullSecret(ctx, cs); err != nil {
+		return err
+	}
+	if registryPod.CAConfigMapName, err = c.ensureCAConfigMap(ctx, cs); err != nil {
+		return err
 	}
 	if registryPod.DBPath, err = c.getDBPath(ctx); err != nil {
 		return fmt.Errorf("get database path: %v", err)
@@ -227,7 +246,11 @@ func (c IndexImageCatalogCreator) createAnnotatedRegistry(ctx context.Context, c
 
 // getDBPath returns the database path from the index image's labels.
 func (c IndexImageCatalogCreator) getDBPath(ctx context.Context) (string, error) {
-	labels, err := registryutil.GetImageLabels(ctx, nil, c.IndexImage, false)
+	regOpts, err := c.PullOptions.RegistryOptions()
+	if err != nil {
+		return "", err
+	}
+	labels, err := registryutil.GetImageLabels(ctx, nil, c.PullOptions.Mirrors.Apply(c.IndexImage), false, regOpts...)
 	if err != nil {
 		return "", fmt.Errorf("get index image labels: %v", err)
 	}
@@ -59,6 +59,16 @@ func withInstallPlanApproval(approval v1alpha1.Approval) func(*v1alpha1.Subscrip
 	}
 }
 
+// withSubscriptionConfig sets the Subscription's config, which OLM applies to
+// the operator's deployments. A nil config leaves the config unset.
+func withSubscriptionConfig(config *v1alpha1.SubscriptionConfig) func(*v1alpha1.Subscription) {
+	return func(sub *v1alpha1.Subscription) {
+		if config != nil {
+			sub.Spec.Config = *config.DeepCopy()
+		}
+	}
+}
+
 // newSubscription creates a new Subscription for a CSV with a name derived
 // from csvName, the CSV's objectmeta.name, in namespace. opts will be applied
 // to the Subscription object.
@@ -17,6 +17,8 @@ package registry
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 )
 
 var _ = Describe("newCatalogSource", func() {
@@ -41,5 +43,16 @@ var _ = Describe("newCatalogSource", func() {
 			Expect(cs.Spec.Publisher).To(Equal("operator-sdk"))
 		})
 	})
+	Describe("withSubscriptionConfig", func() {
+		It("should set the config of a Subscription", func() {
+			config := &v1alpha1.SubscriptionConfig{Env: []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}}}
+			sub := newSubscription("fakeName", "fakeNS", withSubscriptionConfig(config))
+			Expect(sub.Spec.Config).To(Equal(*config))
+		})
+		It("should not set a nil config", func() {
+			sub := newSubscription("fakeName", "fakeNS", withSubscriptionConfig(nil))
+			Expect(sub.Spec.Config).To(Equal(v1alpha1.SubscriptionConfig{}))
+		})
+	})
 
 })
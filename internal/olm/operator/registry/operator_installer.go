@@ -18,11 +18,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	v1 "github.com/operator-framework/api/pkg/operators/v1"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -42,21 +46,69 @@ type OperatorInstaller struct {
 	CatalogCreator        CatalogCreator
 	CatalogUpdater        CatalogUpdater
 	SupportedInstallModes sets.String
-
-	cfg *operator.Configuration
+	// SubscriptionConfig is set as the operator's Subscription config, if not nil.
+	SubscriptionConfig *v1alpha1.SubscriptionConfig
+	// ProgressOutput is the format of installation progress events, one of text or json.
+	// Text events are logged, and JSON events are written to stdout one per line.
+	ProgressOutput string
+
+	cfg         *operator.Configuration
+	progressOut io.Writer
 }
 
+// subscriptionResolutionFailed is the Subscription condition set by OLM when
+// the dependencies of the subscribed operator cannot be resolved.
+const subscriptionResolutionFailed v1alpha1.SubscriptionConditionType = "ResolutionFailed"
+
 func NewOperatorInstaller(cfg *operator.Configuration) *OperatorInstaller {
-	return &OperatorInstaller{cfg: cfg}
+	return &OperatorInstaller{cfg: cfg, ProgressOutput: ProgressOutputText, progressOut: os.Stdout}
+}
+
+// BindProgressFlags binds flags that configure how installation progress is reported.
+func (o *OperatorInstaller) BindProgressFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&o.ProgressOutput, "output", "o", ProgressOutputText,
+		fmt.Sprintf("Format of installation progress events. One of: [%s, %s]. "+
+			"JSON events are written to stdout, one per line", ProgressOutputText, ProgressOutputJSON))
+}
+
+// validateProgressOutput returns an error if o.ProgressOutput is not a known format.
+func (o OperatorInstaller) validateProgressOutput() error {
+	switch o.ProgressOutput {
+	case "", ProgressOutputText, ProgressOutputJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %q, must be one of: [%s, %s]",
+			o.ProgressOutput, ProgressOutputText, ProgressOutputJSON)
+	}
 }
 
 func (o OperatorInstaller) InstallOperator(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
+	if err := o.validateProgressOutput(); err != nil {
+		return nil, err
+	}
+
 	cs, err := o.CatalogCreator.CreateCatalog(ctx, o.CatalogSourceName)
 	if err != nil {
 		return nil, fmt.Errorf("create catalog: %v", err)
 	}
 	log.Infof("Created CatalogSource: %s", cs.GetName())
 
+	// Report the state of the catalog and of the operator's installation
+	// while waiting on them, and add it to any error.
+	tracker := o.newProgressTracker(cs.GetName())
+	stop := tracker.start(ctx, time.Second)
+	csv, err := o.installOperator(ctx, cs.GetName())
+	stop()
+	if err != nil {
+		return nil, tracker.wrapError(err)
+	}
+
+	log.Infof("OLM has successfully installed %q", o.StartingCSV)
+
+	return csv, nil
+}
+
+func (o OperatorInstaller) installOperator(ctx context.Context, csName string) (*v1alpha1.ClusterServiceVersion, error) {
 	// TODO: OLM doesn't appear to propagate the "READY" connection status to the
 	// catalogsource in a timely manner even though its catalog-operator reports
 	// a connection almost immediately. This condition either needs to be
@@ -68,13 +120,13 @@ func (o OperatorInstaller) InstallOperator(ctx context.Context) (*v1alpha1.Clust
 	// }
 
 	// Ensure Operator Group
-	if err = o.ensureOperatorGroup(ctx); err != nil {
+	if err := o.ensureOperatorGroup(ctx); err != nil {
 		return nil, err
 	}
 
-	var subscription *v1alpha1.Subscription
 	// Create Subscription
-	if subscription, err = o.createSubscription(ctx, cs.GetName()); err != nil {
+	subscription, err := o.createSubscription(ctx, csName)
+	if err != nil {
 		return nil, err
 	}
 
@@ -89,14 +141,20 @@ func (o OperatorInstaller) InstallOperator(ctx context.Context) (*v1alpha1.Clust
 	}
 
 	// Wait for successfully installed CSV
-	csv, err := o.getInstalledCSV(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	log.Infof("OLM has successfully installed %q", o.StartingCSV)
+	return o.getInstalledCSV(ctx)
+}
 
-	return csv, nil
+// newProgressTracker returns a tracker of the installation of o.StartingCSV from catalog csName.
+func (o OperatorInstaller) newProgressTracker(csName string) *progressTracker {
+	w := o.progressOut
+	if w == nil {
+		w = os.Stdout
+	}
+	t := newProgressTracker(o.cfg.Client, o.cfg.Namespace, o.ProgressOutput, w)
+	t.catalogSourceName = csName
+	t.packageName = o.PackageName
+	t.csvName = o.StartingCSV
+	return t
 }
 
 func (o OperatorInstaller) UpgradeOperator(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
@@ -129,6 +187,13 @@ func (o OperatorInstaller) UpgradeOperator(ctx context.Context) (*v1alpha1.Clust
 
 	log.Infof("Found existing subscription with name %s and namespace %s", subscription.Name, subscription.Namespace)
 
+	// Update the subscription config before the new version is installed with it.
+	if o.SubscriptionConfig != nil {
+		if err := o.updateSubscriptionConfig(ctx, subscription); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get existing catalog source from the subsription
 	catsrcKey := types.NamespacedName{
 		Namespace: subscription.Spec.CatalogSourceNamespace,
@@ -199,36 +264,76 @@ func (o OperatorInstaller) ensureOperatorGroup(ctx context.Context) error {
 		return err
 	}
 
+	targetNamespaces, err := o.resolveTargetNamespaces()
+	if err != nil {
+		return err
+	}
+
+	if !ogFound {
+		if og, err = o.createOperatorGroup(ctx, targetNamespaces); err != nil {
+			return fmt.Errorf("create operator group: %v", err)
+		}
+		log.Infof("OperatorGroup %q created", og.Name)
+	} else if err := o.checkExistingOperatorGroup(*og, targetNamespaces); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CheckOperatorGroup returns an error if the operator cannot be installed with the
+// OperatorGroup in the operator's namespace, if any, before any resources are created.
+func (o OperatorInstaller) CheckOperatorGroup(ctx context.Context) error {
+	og, ogFound, err := o.getOperatorGroup(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetNamespaces, err := o.resolveTargetNamespaces()
+	if err != nil {
+		return err
+	}
+
+	if ogFound {
+		return o.checkExistingOperatorGroup(*og, targetNamespaces)
+	}
+	return nil
+}
+
+// resolveTargetNamespaces returns the target namespaces of the OperatorGroup to create
+// from the install mode, if set, and the supported install modes.
+func (o OperatorInstaller) resolveTargetNamespaces() ([]string, error) {
 	supported := o.SupportedInstallModes
 
 	// --install-mode was given
 	if !o.InstallMode.IsEmpty() {
 		if o.InstallMode.InstallModeType == v1alpha1.InstallModeTypeSingleNamespace &&
 			o.InstallMode.TargetNamespaces[0] == o.cfg.Namespace {
-			return fmt.Errorf("use install mode %q to watch operator's namespace %q", v1alpha1.InstallModeTypeOwnNamespace, o.cfg.Namespace)
+			return nil, fmt.Errorf("use install mode %q to watch operator's namespace %q", v1alpha1.InstallModeTypeOwnNamespace, o.cfg.Namespace)
 		}
 
 		supported = supported.Intersection(sets.NewString(string(o.InstallMode.InstallModeType)))
 		if supported.Len() == 0 {
-			return fmt.Errorf("operator %q does not support install mode %q", o.StartingCSV, o.InstallMode.InstallModeType)
+			return nil, fmt.Errorf("operator %q does not support install mode %q", o.StartingCSV, o.InstallMode.InstallModeType)
 		}
 	}
 
-	targetNamespaces, err := o.getTargetNamespaces(supported)
-	if err != nil {
-		return err
-	}
+	return o.getTargetNamespaces(supported)
+}
 
-	if !ogFound {
-		if og, err = o.createOperatorGroup(ctx, targetNamespaces); err != nil {
-			return fmt.Errorf("create operator group: %v", err)
+// checkExistingOperatorGroup returns an error if the operator does not support the install
+// mode required by og, or if og does not target targetNamespaces.
+func (o OperatorInstaller) checkExistingOperatorGroup(og v1.OperatorGroup, targetNamespaces []string) error {
+	// The namespaces of an OperatorGroup with a selector are only known once OLM has resolved them.
+	if og.Spec.Selector == nil {
+		mode := operator.InstallModeTypeForTargets(og.Spec.TargetNamespaces, o.cfg.Namespace)
+		if !o.SupportedInstallModes.Has(string(mode)) {
+			return fmt.Errorf("existing operatorgroup %q in namespace %q targets namespaces %q, "+
+				"which requires install mode %q not supported by operator %q; delete it or run the operator in another namespace",
+				og.Name, o.cfg.Namespace, og.Spec.TargetNamespaces, mode, o.StartingCSV)
 		}
-		log.Infof("OperatorGroup %q created", og.Name)
-	} else if err := o.isOperatorGroupCompatible(*og, targetNamespaces); err != nil {
-		return err
 	}
-
-	return nil
+	return o.isOperatorGroupCompatible(og, targetNamespaces)
 }
 
 func (o *OperatorInstaller) createOperatorGroup(ctx context.Context, targetNamespaces []string) (*v1.OperatorGroup, error) {
@@ -249,7 +354,18 @@ func (o *OperatorInstaller) isOperatorGroupCompatible(og v1.OperatorGroup, targe
 	targets := sets.NewString(targetNamespaces...)
 	ogtargets := sets.NewString(og.Spec.TargetNamespaces...)
 	if !ogtargets.Equal(targets) {
-		return fmt.Errorf("existing operatorgroup %q is not compatible with install mode %q", og.Name, o.InstallMode)
+		// The namespaces of an OperatorGroup with a selector are only known once OLM has resolved them.
+		if og.Spec.Selector != nil {
+			return fmt.Errorf("existing operatorgroup %q selecting target namespaces is not compatible with install mode %q; "+
+				"delete it to use this install mode", og.Name, o.InstallMode)
+		}
+		// The OperatorGroup is in the operator's namespace.
+		ogMode := operator.InstallMode{
+			InstallModeType:  operator.InstallModeTypeForTargets(og.Spec.TargetNamespaces, og.GetNamespace()),
+			TargetNamespaces: og.Spec.TargetNamespaces,
+		}
+		return fmt.Errorf("existing operatorgroup %q targeting namespaces %q is not compatible with install mode %q; "+
+			"delete it or set --install-mode=%s to use it", og.Name, og.Spec.TargetNamespaces, o.InstallMode, ogMode)
 	}
 
 	return nil
@@ -271,7 +387,8 @@ func (o OperatorInstaller) getOperatorGroup(ctx context.Context) (*v1.OperatorGr
 		for _, og := range ogList.Items {
 			names = append(names, og.GetName())
 		}
-		return nil, true, fmt.Errorf("more than one operator group in namespace %s: %+q", o.cfg.Namespace, names)
+		return nil, true, fmt.Errorf("more than one operator group in namespace %s: %+q; "+
+			"OLM cannot install operators in this namespace until all but one are deleted", o.cfg.Namespace, names)
 	}
 	return &ogList.Items[0], true, nil
 }
@@ -280,7 +397,8 @@ func (o OperatorInstaller) createSubscription(ctx context.Context, csName string
 	sub := newSubscription(o.StartingCSV, o.cfg.Namespace,
 		withPackageChannel(o.PackageName, o.Channel, o.StartingCSV),
 		withCatalogSource(csName, o.cfg.Namespace),
-		withInstallPlanApproval(v1alpha1.ApprovalManual))
+		withInstallPlanApproval(v1alpha1.ApprovalManual),
+		withSubscriptionConfig(o.SubscriptionConfig))
 
 	if err := o.cfg.Client.Create(ctx, sub); err != nil {
 		return nil, fmt.Errorf("error creating subscription: %w", err)
@@ -290,6 +408,22 @@ func (o OperatorInstaller) createSubscription(ctx context.Context, csName string
 	return sub, nil
 }
 
+// updateSubscriptionConfig replaces the config of sub with o.SubscriptionConfig.
+func (o OperatorInstaller) updateSubscriptionConfig(ctx context.Context, sub *v1alpha1.Subscription) error {
+	subKey := types.NamespacedName{Namespace: sub.GetNamespace(), Name: sub.GetName()}
+	if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := o.cfg.Client.Get(ctx, subKey, sub); err != nil {
+			return err
+		}
+		withSubscriptionConfig(o.SubscriptionConfig)(sub)
+		return o.cfg.Client.Update(ctx, sub)
+	}); err != nil {
+		return fmt.Errorf("error updating subscription config: %w", err)
+	}
+	log.Infof("Updated config of subscription %s", sub.Name)
+	return nil
+}
+
 func (o OperatorInstaller) getInstalledCSV(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
 	c := olmclient.Client{KubeClient: o.cfg.Client}
 
@@ -359,20 +493,24 @@ func (o OperatorInstaller) waitForInstallPlan(ctx context.Context, sub *v1alpha1
 	})
 
 	if err := wait.PollImmediateUntil(200*time.Millisecond, ipCheck, ctx.Done()); err != nil {
+		if cond := sub.Status.GetCondition(subscriptionResolutionFailed); cond.Status == corev1.ConditionTrue {
+			return fmt.Errorf("install plan is not available for the subscription %s: dependency resolution failed: %s",
+				sub.Name, cond.Message)
+		}
 		return fmt.Errorf("install plan is not available for the subscription %s: %v", sub.Name, err)
 	}
 	return nil
 }
 
 func (o *OperatorInstaller) getTargetNamespaces(supported sets.String) ([]string, error) {
-	switch {
-	case supported.Has(string(v1alpha1.InstallModeTypeAllNamespaces)):
+	switch operator.DefaultInstallModeType(supported) {
+	case v1alpha1.InstallModeTypeAllNamespaces:
 		return nil, nil
-	case supported.Has(string(v1alpha1.InstallModeTypeOwnNamespace)):
+	case v1alpha1.InstallModeTypeOwnNamespace:
 		return []string{o.cfg.Namespace}, nil
-	case supported.Has(string(v1alpha1.InstallModeTypeSingleNamespace)):
+	case v1alpha1.InstallModeTypeSingleNamespace:
 		return o.InstallMode.TargetNamespaces, nil
-	case supported.Has(string(v1alpha1.InstallModeTypeMultiNamespace)):
+	case v1alpha1.InstallModeTypeMultiNamespace:
 		log.Warn("The selected install mode MultiNamespace may cause tenancy issues and is not recommended")
 		return o.InstallMode.TargetNamespaces, nil
 	default:
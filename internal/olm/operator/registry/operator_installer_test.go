@@ -16,6 +16,7 @@ package registry
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -74,6 +75,32 @@ var _ = Describe("OperatorInstaller", func() {
 			Expect(retSub.GetNamespace()).To(Equal(sub.GetNamespace()))
 		})
 
+		It("should create the subscription with its config", func() {
+			oi.SubscriptionConfig = &v1alpha1.SubscriptionConfig{NodeSelector: map[string]string{"kubernetes.io/os": "linux"}}
+			sub, err := oi.createSubscription(context.TODO(), "huzzah")
+			Expect(err).ToNot(HaveOccurred())
+
+			retSub := &v1alpha1.Subscription{}
+			Expect(oi.cfg.Client.Get(context.TODO(), types.NamespacedName{Namespace: sub.GetNamespace(), Name: sub.GetName()}, retSub)).To(Succeed())
+			Expect(retSub.Spec.Config.NodeSelector).To(Equal(map[string]string{"kubernetes.io/os": "linux"}))
+		})
+
+		It("should update the config of an existing subscription", func() {
+			existing := newSubscription(oi.StartingCSV, oi.cfg.Namespace,
+				withSubscriptionConfig(&v1alpha1.SubscriptionConfig{NodeSelector: map[string]string{"disk": "ssd"}}))
+			oi.cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(existing).Build()
+
+			oi.SubscriptionConfig = &v1alpha1.SubscriptionConfig{Env: []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}}}
+			sub := &v1alpha1.Subscription{}
+			sub.SetName(existing.GetName())
+			sub.SetNamespace(existing.GetNamespace())
+			Expect(oi.updateSubscriptionConfig(context.TODO(), sub)).To(Succeed())
+
+			retSub := &v1alpha1.Subscription{}
+			Expect(oi.cfg.Client.Get(context.TODO(), types.NamespacedName{Namespace: sub.GetNamespace(), Name: sub.GetName()}, retSub)).To(Succeed())
+			Expect(retSub.Spec.Config).To(Equal(*oi.SubscriptionConfig))
+		})
+
 		It("should pass through any client errors (duplicate)", func() {
 
 			sub := newSubscription(oi.StartingCSV, oi.cfg.Namespace, withCatalogSource("duplicate", oi.cfg.Namespace))
@@ -254,6 +281,27 @@ var _ = Describe("OperatorInstaller", func() {
 			err = oi.waitForInstallPlan(context.TODO(), sub)
 			Expect(err).ToNot(HaveOccurred())
 		})
+		It("should return the resolution failure of the subscription.", func() {
+			sub := &v1alpha1.Subscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "fakeName",
+					Namespace: "fakeNS",
+				},
+				Status: v1alpha1.SubscriptionStatus{
+					Conditions: []v1alpha1.SubscriptionCondition{{
+						Type:    subscriptionResolutionFailed,
+						Status:  corev1.ConditionTrue,
+						Message: "no operators found with package etcd",
+					}},
+				},
+			}
+			Expect(oi.cfg.Client.Create(context.TODO(), sub)).To(Succeed())
+
+			ctx, cancel := context.WithTimeout(context.TODO(), 500*time.Millisecond)
+			defer cancel()
+			err := oi.waitForInstallPlan(ctx, sub)
+			Expect(err).To(MatchError(ContainSubstring("dependency resolution failed: no operators found with package etcd")))
+		})
 	})
 
 	Describe("ensureOperatorGroup", func() {
@@ -454,6 +502,61 @@ var _ = Describe("OperatorInstaller", func() {
 			})
 		})
 	})
+	Describe("CheckOperatorGroup", func() {
+		var (
+			oi     OperatorInstaller
+			client crclient.Client
+		)
+		BeforeEach(func() {
+			sch := runtime.NewScheme()
+			Expect(v1.AddToScheme(sch)).To(Succeed())
+			client = fake.NewClientBuilder().WithScheme(sch).Build()
+			oi = OperatorInstaller{
+				StartingCSV: "memcached-operator.v0.0.1",
+				cfg: &operator.Configuration{
+					Scheme:    sch,
+					Client:    client,
+					Namespace: "testns",
+				},
+			}
+			oi.SupportedInstallModes = operator.GetSupportedInstallModes([]v1alpha1.InstallMode{
+				{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+				{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: true},
+			})
+		})
+		It("should return nil if no OperatorGroup exists", func() {
+			Expect(oi.CheckOperatorGroup(context.TODO())).To(Succeed())
+			og, found, err := oi.getOperatorGroup(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+			Expect(og).To(BeNil())
+		})
+		It("should return nil for an OperatorGroup requiring a supported install mode", func() {
+			_ = createOperatorGroupHelper(context.TODO(), client, "existing-og", "testns", "testns")
+			Expect(oi.CheckOperatorGroup(context.TODO())).To(Succeed())
+		})
+		It("should return an error for an OperatorGroup requiring an unsupported install mode", func() {
+			_ = createOperatorGroupHelper(context.TODO(), client, "existing-og", "testns", "otherns")
+			err := oi.CheckOperatorGroup(context.TODO())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`requires install mode "SingleNamespace" not supported by operator "memcached-operator.v0.0.1"`))
+		})
+		It("should return an error for an OperatorGroup not matching the install mode", func() {
+			_ = oi.InstallMode.Set(string(v1alpha1.InstallModeTypeOwnNamespace))
+			_ = createOperatorGroupHelper(context.TODO(), client, "existing-og", "testns")
+			err := oi.CheckOperatorGroup(context.TODO())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is not compatible"))
+		})
+		It("should return an error for multiple OperatorGroups", func() {
+			_ = createOperatorGroupHelper(context.TODO(), client, "og1", "testns")
+			_ = createOperatorGroupHelper(context.TODO(), client, "og2", "testns")
+			err := oi.CheckOperatorGroup(context.TODO())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("until all but one are deleted"))
+		})
+	})
+
 	Describe("createOperatorGroup", func() {
 		var (
 			oi     OperatorInstaller
@@ -506,6 +609,16 @@ var _ = Describe("OperatorInstaller", func() {
 			err := oi.isOperatorGroupCompatible(og, oi.InstallMode.TargetNamespaces)
 			Expect(err).ShouldNot(BeNil())
 			Expect(err.Error()).Should(ContainSubstring("is not compatible"))
+			Expect(err.Error()).Should(ContainSubstring("set --install-mode=OwnNamespace to use it"))
+		})
+		It("should suggest the install mode matching the existing operator group", func() {
+			oi.InstallMode = operator.InstallMode{
+				InstallModeType:  v1alpha1.InstallModeTypeOwnNamespace,
+				TargetNamespaces: []string{"default"},
+			}
+			aog := createOperatorGroupHelper(context.TODO(), nil, "existing-og", "default", "ns1", "ns2")
+			err := oi.isOperatorGroupCompatible(aog, oi.InstallMode.TargetNamespaces)
+			Expect(err).To(MatchError(ContainSubstring("set --install-mode=MultiNamespace=ns1,ns2 to use it")))
 		})
 		It("should return nil if no installmode is empty", func() {
 			// empty install mode
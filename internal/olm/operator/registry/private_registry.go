@@ -0,0 +1,134 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/declcfg"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry/index"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+// ensurePullSecret returns the name of the docker config secret mounted in the registry pod of cs
+// so that `opm registry add` can pull bundle images with c.SecretNames. Multiple secrets are
+// merged into a secret owned by cs, since opm reads a single docker config.
+func (c IndexImageCatalogCreator) ensurePullSecret(ctx context.Context, cs *v1alpha1.CatalogSource) (string, error) {
+	switch len(c.SecretNames) {
+	case 0:
+		return "", nil
+	case 1:
+		return c.SecretNames[0], nil
+	}
+
+	var secrets []corev1.Secret
+	for _, name := range c.SecretNames {
+		secret := corev1.Secret{}
+		if err := c.cfg.Client.Get(ctx, types.NamespacedName{Namespace: cs.GetNamespace(), Name: name}, &secret); err != nil {
+			return "", fmt.Errorf("error getting image pull secret %q: %v", name, err)
+		}
+		secrets = append(secrets, secret)
+	}
+	dockerConfig, err := mergeDockerConfigs(secrets)
+	if err != nil {
+		return "", err
+	}
+
+	secret := &corev1.Secret{}
+	secret.SetName(cs.GetName() + "-pull-secret")
+	secret.SetNamespace(cs.GetNamespace())
+	if _, err := controllerutil.CreateOrUpdate(ctx, c.cfg.Client, secret, func() error {
+		secret.Type = corev1.SecretTypeDockerConfigJson
+		secret.Data = map[string][]byte{corev1.DockerConfigJsonKey: dockerConfig}
+		return controllerutil.SetOwnerReference(cs, secret, c.cfg.Scheme)
+	}); err != nil {
+		return "", fmt.Errorf("error creating merged image pull secret: %v", err)
+	}
+	return secret.GetName(), nil
+}
+
+// mergeDockerConfigs returns a docker config with the registry auths of all secrets.
+// The auth of a registry in an earlier secret takes precedence.
+func mergeDockerConfigs(secrets []corev1.Secret) ([]byte, error) {
+	auths := map[string]json.RawMessage{}
+	for _, secret := range secrets {
+		if secret.Type != corev1.SecretTypeDockerConfigJson {
+			return nil, fmt.Errorf("image pull secret %q must be of type %q", secret.GetName(), corev1.SecretTypeDockerConfigJson)
+		}
+		dockerConfig := struct {
+			Auths map[string]json.RawMessage `json:"auths"`
+		}{}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &dockerConfig); err != nil {
+			return nil, fmt.Errorf("error decoding image pull secret %q: %v", secret.GetName(), err)
+		}
+		for registry, auth := range dockerConfig.Auths {
+			if _, ok := auths[registry]; !ok {
+				auths[registry] = auth
+			}
+		}
+	}
+	return json.Marshal(map[string]interface{}{"auths": auths})
+}
+
+// ensureCAConfigMap returns the name of a ConfigMap owned by cs holding the root certificates
+// of c.PullOptions.CAFile, trusted by `opm registry add`, or an empty name if none are set.
+func (c IndexImageCatalogCreator) ensureCAConfigMap(ctx context.Context, cs *v1alpha1.CatalogSource) (string, error) {
+	if c.PullOptions.CAFile == "" {
+		return "", nil
+	}
+	ca, err := ioutil.ReadFile(c.PullOptions.CAFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading CA file: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	cm.SetName(cs.GetName() + "-registry-ca")
+	cm.SetNamespace(cs.GetNamespace())
+	if _, err := controllerutil.CreateOrUpdate(ctx, c.cfg.Client, cm, func() error {
+		cm.Data = map[string]string{index.CAKey: string(ca)}
+		return controllerutil.SetOwnerReference(cs, cm, c.cfg.Scheme)
+	}); err != nil {
+		return "", fmt.Errorf("error creating CA ConfigMap: %v", err)
+	}
+	return cm.GetName(), nil
+}
+
+// mirrorBundleItems returns items with their images replaced by their mirrors.
+func (c IndexImageCatalogCreator) mirrorBundleItems(items []index.BundleItem) []index.BundleItem {
+	mirrored := make([]index.BundleItem, len(items))
+	for i, item := range items {
+		mirrored[i] = index.BundleItem{ImageTag: c.PullOptions.Mirrors.Apply(item.ImageTag), AddMode: item.AddMode}
+	}
+	return mirrored
+}
+
+// mirrorCatalog returns a copy of catalog with the images of its bundles replaced by their mirrors.
+func mirrorCatalog(catalog *declcfg.DeclarativeConfig, mirrors registryutil.ImageMirrors) *declcfg.DeclarativeConfig {
+	mirrored := *catalog
+	mirrored.Bundles = make([]declcfg.Bundle, len(catalog.Bundles))
+	for i, b := range catalog.Bundles {
+		b.Image = mirrors.Apply(b.Image)
+		mirrored.Bundles[i] = b
+	}
+	return &mirrored
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/declcfg"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry/index"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+var _ = Describe("Private registries", func() {
+	var (
+		c  IndexImageCatalogCreator
+		cs *v1alpha1.CatalogSource
+	)
+
+	newDockerConfigSecret := func(name, config string) *corev1.Secret {
+		secret := &corev1.Secret{}
+		secret.SetName(name)
+		secret.SetNamespace("testns")
+		secret.Type = corev1.SecretTypeDockerConfigJson
+		secret.Data = map[string][]byte{corev1.DockerConfigJsonKey: []byte(config)}
+		return secret
+	}
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+
+		cs = &v1alpha1.CatalogSource{}
+		cs.SetName("memcached-operator-catalog")
+		cs.SetNamespace("testns")
+
+		c = IndexImageCatalogCreator{cfg: &operator.Configuration{Scheme: sch, Namespace: "testns"}}
+		c.cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(
+			cs,
+			newDockerConfigSecret("quay-secret", `{"auths":{"quay.io":{"auth":"cXVheQ=="}}}`),
+			newDockerConfigSecret("mirror-secret", `{"auths":{"quay.io":{"auth":"b3RoZXI="},"mirror.example.com":{"auth":"bWlycm9y"}}}`),
+		).Build()
+	})
+
+	Describe("ensurePullSecret", func() {
+		It("mounts no secret or the only secret as is", func() {
+			name, err := c.ensurePullSecret(context.TODO(), cs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(BeEmpty())
+
+			c.SecretNames = []string{"quay-secret"}
+			name, err = c.ensurePullSecret(context.TODO(), cs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("quay-secret"))
+		})
+
+		It("merges multiple secrets into a secret owned by the catalog source", func() {
+			c.SecretNames = []string{"quay-secret", "mirror-secret"}
+			name, err := c.ensurePullSecret(context.TODO(), cs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("memcached-operator-catalog-pull-secret"))
+
+			secret := corev1.Secret{}
+			Expect(c.cfg.Client.Get(context.TODO(), types.NamespacedName{Namespace: "testns", Name: name}, &secret)).To(Succeed())
+			Expect(secret.Type).To(Equal(corev1.SecretTypeDockerConfigJson))
+			Expect(secret.Data[corev1.DockerConfigJsonKey]).To(MatchJSON(
+				`{"auths":{"quay.io":{"auth":"cXVheQ=="},"mirror.example.com":{"auth":"bWlycm9y"}}}`))
+			Expect(secret.GetOwnerReferences()).To(HaveLen(1))
+			Expect(secret.GetOwnerReferences()[0].Name).To(Equal(cs.GetName()))
+		})
+
+		It("fails if a secret does not exist", func() {
+			c.SecretNames = []string{"quay-secret", "missing-secret"}
+			_, err := c.ensurePullSecret(context.TODO(), cs)
+			Expect(err).To(MatchError(ContainSubstring(`error getting image pull secret "missing-secret"`)))
+		})
+	})
+
+	Describe("mergeDockerConfigs", func() {
+		It("rejects secrets that are not docker configs", func() {
+			secret := newDockerConfigSecret("opaque-secret", `{}`)
+			secret.Type = corev1.SecretTypeOpaque
+			_, err := mergeDockerConfigs([]corev1.Secret{*secret})
+			Expect(err).To(MatchError(ContainSubstring(`image pull secret "opaque-secret" must be of type`)))
+		})
+	})
+
+	Describe("ensureCAConfigMap", func() {
+		var tmp string
+
+		BeforeEach(func() {
+			var err error
+			tmp, err = ioutil.TempDir("", "registry-ca-")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(tmp)).To(Succeed())
+		})
+
+		It("creates no ConfigMap without a CA file", func() {
+			name, err := c.ensureCAConfigMap(context.TODO(), cs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(BeEmpty())
+		})
+
+		It("creates a ConfigMap of the CA file owned by the catalog source", func() {
+			c.PullOptions.CAFile = filepath.Join(tmp, "ca.crt")
+			Expect(ioutil.WriteFile(c.PullOptions.CAFile, []byte("ca-data"), 0600)).To(Succeed())
+
+			name, err := c.ensureCAConfigMap(context.TODO(), cs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("memcached-operator-catalog-registry-ca"))
+
+			cm := corev1.ConfigMap{}
+			Expect(c.cfg.Client.Get(context.TODO(), types.NamespacedName{Namespace: "testns", Name: name}, &cm)).To(Succeed())
+			Expect(cm.Data).To(Equal(map[string]string{index.CAKey: "ca-data"}))
+			Expect(cm.GetOwnerReferences()).To(HaveLen(1))
+		})
+	})
+
+	Describe("mirrorBundleItems", func() {
+		It("mirrors bundle images", func() {
+			c.PullOptions.Mirrors = registryutil.ImageMirrors{{Source: "quay.io/example", Mirror: "localhost:5000/example"}}
+			items := c.mirrorBundleItems([]index.BundleItem{
+				{ImageTag: "quay.io/example/memcached-operator-bundle:v0.0.1", AddMode: index.SemverBundleAddMode},
+			})
+			Expect(items).To(Equal([]index.BundleItem{
+				{ImageTag: "localhost:5000/example/memcached-operator-bundle:v0.0.1", AddMode: index.SemverBundleAddMode},
+			}))
+		})
+	})
+
+	Describe("mirrorCatalog", func() {
+		It("mirrors bundle images without changing the catalog", func() {
+			catalog := &declcfg.DeclarativeConfig{Bundles: []declcfg.Bundle{
+				{Name: "memcached-operator.v0.0.1", Image: "quay.io/example/memcached-operator-bundle:v0.0.1"},
+			}}
+			mirrors := registryutil.ImageMirrors{{Source: "quay.io/example", Mirror: "localhost:5000/example"}}
+			mirrored := mirrorCatalog(catalog, mirrors)
+			Expect(mirrored.Bundles[0].Image).To(Equal("localhost:5000/example/memcached-operator-bundle:v0.0.1"))
+			Expect(catalog.Bundles[0].Image).To(Equal("quay.io/example/memcached-operator-bundle:v0.0.1"))
+		})
+	})
+})
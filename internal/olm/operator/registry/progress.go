@@ -0,0 +1,343 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Formats of installation progress output.
+const (
+	ProgressOutputText = "text"
+	ProgressOutputJSON = "json"
+)
+
+// ProgressEvent is a change in the state of a resource created while installing an operator.
+type ProgressEvent struct {
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason,omitempty"`
+	Message   string    `json:"message,omitempty"`
+}
+
+func (e ProgressEvent) String() string {
+	s := fmt.Sprintf("%s %q: %s", e.Kind, e.Name, e.Status)
+	if e.Reason != "" {
+		s += fmt.Sprintf(" (%s)", e.Reason)
+	}
+	if e.Message != "" {
+		s += ": " + e.Message
+	}
+	return s
+}
+
+// sameState returns true if e and other describe the same state of a resource.
+func (e ProgressEvent) sameState(other ProgressEvent) bool {
+	return e.Status == other.Status && e.Reason == other.Reason && e.Message == other.Message
+}
+
+// progressTracker polls the CatalogSource, registry pods, Subscription, InstallPlan, CSV and
+// Deployments of an operator's installation, and reports each change in their state.
+type progressTracker struct {
+	client            client.Client
+	namespace         string
+	catalogSourceName string
+	packageName       string
+	csvName           string
+	output            string
+	w                 io.Writer
+
+	mu sync.Mutex
+	// last is the last event of each resource, keyed by kind and name, in the order they were first seen.
+	last  map[string]ProgressEvent
+	order []string
+}
+
+func newProgressTracker(c client.Client, namespace, output string, w io.Writer) *progressTracker {
+	return &progressTracker{
+		client:    c,
+		namespace: namespace,
+		output:    output,
+		w:         w,
+		last:      map[string]ProgressEvent{},
+	}
+}
+
+// start polls every interval until the returned stop function is called,
+// which polls a final time so that the last state of each resource is reported.
+func (t *progressTracker) start(ctx context.Context, interval time.Duration) (stop func()) {
+	pollCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait.UntilWithContext(pollCtx, t.poll, interval)
+	}()
+	return func() {
+		cancel()
+		<-done
+		t.poll(ctx)
+	}
+}
+
+// poll reports the current state of each resource that changed since the last poll.
+func (t *progressTracker) poll(ctx context.Context) {
+	var events []ProgressEvent
+	events = append(events, t.observeCatalogSource(ctx)...)
+	events = append(events, t.observeRegistryPods(ctx)...)
+	events = append(events, t.observeSubscription(ctx)...)
+	events = append(events, t.observeCSV(ctx)...)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, e := range events {
+		key := e.Kind + "/" + e.Name
+		last, seen := t.last[key]
+		if seen && last.sameState(e) {
+			continue
+		}
+		if !seen {
+			t.order = append(t.order, key)
+		}
+		t.last[key] = e
+		t.report(e)
+	}
+}
+
+func (t *progressTracker) report(e ProgressEvent) {
+	switch t.output {
+	case ProgressOutputJSON:
+		if err := json.NewEncoder(t.w).Encode(e); err != nil {
+			log.Debugf("Failed to write progress event: %v", err)
+		}
+	default:
+		log.Infof("  %s", e)
+	}
+}
+
+// summary returns the last observed state of each resource, one per line.
+func (t *progressTracker) summary() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lines := make([]string, len(t.order))
+	for i, key := range t.order {
+		lines[i] = "  " + t.last[key].String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapError adds the last observed state of each resource to err, which is otherwise
+// often a bare timeout.
+func (t *progressTracker) wrapError(err error) error {
+	summary := t.summary()
+	if summary == "" {
+		return err
+	}
+	return fmt.Errorf("%w\nLast observed state:\n%s", err, summary)
+}
+
+func (t *progressTracker) newEvent(kind, name string) ProgressEvent {
+	return ProgressEvent{Time: time.Now().UTC(), Kind: kind, Namespace: t.namespace, Name: name}
+}
+
+// get gets the object named name in the tracked namespace, returning false if it was not found.
+func (t *progressTracker) get(ctx context.Context, name string, obj client.Object) bool {
+	err := t.client.Get(ctx, types.NamespacedName{Namespace: t.namespace, Name: name}, obj)
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Debugf("Failed to get %s: %v", name, err)
+	}
+	return err == nil
+}
+
+func (t *progressTracker) observeCatalogSource(ctx context.Context) []ProgressEvent {
+	cs := &v1alpha1.CatalogSource{}
+	if t.catalogSourceName == "" || !t.get(ctx, t.catalogSourceName, cs) {
+		return nil
+	}
+	e := t.newEvent(v1alpha1.CatalogSourceKind, cs.GetName())
+	e.Status = "Pending"
+	if cs.Status.GRPCConnectionState != nil && cs.Status.GRPCConnectionState.LastObservedState != "" {
+		e.Status = cs.Status.GRPCConnectionState.LastObservedState
+	}
+	e.Reason = string(cs.Status.Reason)
+	e.Message = cs.Status.Message
+	return []ProgressEvent{e}
+}
+
+// observeRegistryPods observes the pods owned by the CatalogSource, which serve its registry.
+func (t *progressTracker) observeRegistryPods(ctx context.Context) (events []ProgressEvent) {
+	if t.catalogSourceName == "" {
+		return nil
+	}
+	pods := &corev1.PodList{}
+	if err := t.client.List(ctx, pods, client.InNamespace(t.namespace)); err != nil {
+		log.Debugf("Failed to list pods: %v", err)
+		return nil
+	}
+	for _, pod := range pods.Items {
+		owned := false
+		for _, ref := range pod.GetOwnerReferences() {
+			if ref.Kind == v1alpha1.CatalogSourceKind && ref.Name == t.catalogSourceName {
+				owned = true
+			}
+		}
+		if !owned {
+			continue
+		}
+		e := t.newEvent("Pod", pod.GetName())
+		e.Status = string(pod.Status.Phase)
+		e.Reason, e.Message = containerState(pod.Status.ContainerStatuses)
+		events = append(events, e)
+	}
+	return events
+}
+
+// containerState returns the reason and message of the first container that is waiting or terminated.
+func containerState(statuses []corev1.ContainerStatus) (reason, message string) {
+	for _, cs := range statuses {
+		switch {
+		case cs.State.Waiting != nil:
+			return cs.State.Waiting.Reason, cs.State.Waiting.Message
+		case cs.State.Terminated != nil:
+			return cs.State.Terminated.Reason, cs.State.Terminated.Message
+		}
+	}
+	return "", ""
+}
+
+// observeSubscription observes the Subscription to the package from the CatalogSource,
+// and its InstallPlan once OLM has created it.
+func (t *progressTracker) observeSubscription(ctx context.Context) []ProgressEvent {
+	subs := &v1alpha1.SubscriptionList{}
+	if err := t.client.List(ctx, subs, client.InNamespace(t.namespace)); err != nil {
+		log.Debugf("Failed to list subscriptions: %v", err)
+		return nil
+	}
+	for _, sub := range subs.Items {
+		if sub.Spec.Package != t.packageName || sub.Spec.CatalogSource != t.catalogSourceName {
+			continue
+		}
+		e := t.newEvent(v1alpha1.SubscriptionKind, sub.GetName())
+		e.Status = string(sub.Status.State)
+		if e.Status == "" {
+			e.Status = "Pending"
+		}
+		if cond := sub.Status.GetCondition(subscriptionResolutionFailed); cond.Status == corev1.ConditionTrue {
+			e.Status = string(subscriptionResolutionFailed)
+			e.Reason = cond.Reason
+			e.Message = cond.Message
+		}
+		events := []ProgressEvent{e}
+		if ref := sub.Status.InstallPlanRef; ref != nil {
+			events = append(events, t.observeInstallPlan(ctx, ref.Name)...)
+		}
+		return events
+	}
+	return nil
+}
+
+func (t *progressTracker) observeInstallPlan(ctx context.Context, name string) []ProgressEvent {
+	ip := &v1alpha1.InstallPlan{}
+	if !t.get(ctx, name, ip) {
+		return nil
+	}
+	e := t.newEvent(v1alpha1.InstallPlanKind, ip.GetName())
+	e.Status = string(ip.Status.Phase)
+	if e.Status == "" {
+		e.Status = "Pending"
+	}
+	if len(ip.Status.Plan) != 0 {
+		installed := 0
+		for _, step := range ip.Status.Plan {
+			if step.Status == v1alpha1.StepStatusCreated || step.Status == v1alpha1.StepStatusPresent {
+				installed++
+			}
+		}
+		e.Message = fmt.Sprintf("%d of %d steps installed", installed, len(ip.Status.Plan))
+	}
+	for _, cond := range ip.Status.Conditions {
+		if cond.Type == v1alpha1.InstallPlanInstalled && cond.Status == corev1.ConditionFalse {
+			e.Reason = string(cond.Reason)
+			e.Message = cond.Message
+		}
+	}
+	return []ProgressEvent{e}
+}
+
+// observeCSV observes the CSV being installed, and the rollout of its Deployments.
+func (t *progressTracker) observeCSV(ctx context.Context) []ProgressEvent {
+	csv := &v1alpha1.ClusterServiceVersion{}
+	if t.csvName == "" || !t.get(ctx, t.csvName, csv) {
+		return nil
+	}
+	e := t.newEvent(v1alpha1.ClusterServiceVersionKind, csv.GetName())
+	e.Status = string(csv.Status.Phase)
+	if e.Status == "" {
+		e.Status = "Pending"
+	}
+	e.Reason = string(csv.Status.Reason)
+	e.Message = csv.Status.Message
+	events := []ProgressEvent{e}
+
+	for _, spec := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		dep := &appsv1.Deployment{}
+		if !t.get(ctx, spec.Name, dep) {
+			continue
+		}
+		events = append(events, t.deploymentEvent(dep))
+	}
+	return events
+}
+
+// deploymentEvent returns the rollout state of dep.
+func (t *progressTracker) deploymentEvent(dep *appsv1.Deployment) ProgressEvent {
+	e := t.newEvent("Deployment", dep.GetName())
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	status := dep.Status
+	e.Message = fmt.Sprintf("%d of %d replicas updated, %d available", status.UpdatedReplicas, replicas, status.AvailableReplicas)
+	if status.ObservedGeneration >= dep.Generation && status.UpdatedReplicas == replicas &&
+		status.Replicas == replicas && status.AvailableReplicas == replicas {
+		e.Status = "RolledOut"
+		return e
+	}
+	e.Status = "RollingOut"
+	for _, cond := range status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Status != corev1.ConditionTrue ||
+			cond.Type == appsv1.DeploymentAvailable && cond.Status != corev1.ConditionTrue {
+			e.Reason = cond.Reason
+		}
+	}
+	return e
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("progressTracker", func() {
+	var (
+		client  crclient.Client
+		out     *bytes.Buffer
+		tracker *progressTracker
+		csv     *v1alpha1.ClusterServiceVersion
+	)
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+
+		cs := &v1alpha1.CatalogSource{}
+		cs.SetName("memcached-operator-catalog")
+		cs.SetNamespace("testns")
+		cs.Status.GRPCConnectionState = &v1alpha1.GRPCConnectionState{LastObservedState: "READY"}
+
+		pod := &corev1.Pod{}
+		pod.SetName("memcached-operator-catalog-pod")
+		pod.SetNamespace("testns")
+		pod.SetOwnerReferences([]metav1.OwnerReference{{Kind: v1alpha1.CatalogSourceKind, Name: cs.GetName()}})
+		pod.Status.Phase = corev1.PodPending
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name: "registry-grpc",
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"},
+			},
+		}}
+
+		sub := &v1alpha1.Subscription{}
+		sub.SetName("memcached-operator-v0-0-1-sub")
+		sub.SetNamespace("testns")
+		sub.Spec = &v1alpha1.SubscriptionSpec{Package: "memcached-operator", CatalogSource: cs.GetName()}
+		sub.Status.State = v1alpha1.SubscriptionStateUpgradePending
+		sub.Status.InstallPlanRef = &corev1.ObjectReference{Name: "install-abcde", Namespace: "testns"}
+
+		ip := &v1alpha1.InstallPlan{}
+		ip.SetName("install-abcde")
+		ip.SetNamespace("testns")
+		ip.Status.Phase = v1alpha1.InstallPlanPhaseInstalling
+		ip.Status.Plan = []*v1alpha1.Step{
+			{Status: v1alpha1.StepStatusCreated},
+			{Status: v1alpha1.StepStatusUnknown},
+		}
+
+		csv = &v1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+		csv.SetNamespace("testns")
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{
+			{Name: "memcached-operator-controller-manager"},
+		}
+		csv.Status.Phase = v1alpha1.CSVPhaseInstalling
+		csv.Status.Reason = v1alpha1.CSVReasonWaiting
+		csv.Status.Message = "installing: waiting for deployment memcached-operator-controller-manager to become ready"
+
+		replicas := int32(1)
+		dep := &appsv1.Deployment{}
+		dep.SetName("memcached-operator-controller-manager")
+		dep.SetNamespace("testns")
+		dep.Spec.Replicas = &replicas
+		dep.Status.Replicas = 1
+		dep.Status.UpdatedReplicas = 1
+		dep.Status.Conditions = []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Reason: "MinimumReplicasUnavailable"},
+		}
+
+		client = fake.NewClientBuilder().WithScheme(sch).WithObjects(cs, pod, sub, ip, csv, dep).Build()
+		out = &bytes.Buffer{}
+		tracker = newProgressTracker(client, "testns", ProgressOutputJSON, out)
+		tracker.catalogSourceName = cs.GetName()
+		tracker.packageName = "memcached-operator"
+		tracker.csvName = csv.GetName()
+	})
+
+	decodeEvents := func() (events []ProgressEvent) {
+		dec := json.NewDecoder(out)
+		for dec.More() {
+			e := ProgressEvent{}
+			ExpectWithOffset(1, dec.Decode(&e)).To(Succeed())
+			events = append(events, e)
+		}
+		return events
+	}
+
+	It("reports the state of each resource of the installation", func() {
+		tracker.poll(context.TODO())
+		events := decodeEvents()
+		Expect(events).To(HaveLen(6))
+
+		states := make([]string, len(events))
+		for i, e := range events {
+			Expect(e.Namespace).To(Equal("testns"))
+			Expect(e.Time.IsZero()).To(BeFalse())
+			states[i] = e.String()
+		}
+		Expect(states).To(Equal([]string{
+			`CatalogSource "memcached-operator-catalog": READY`,
+			`Pod "memcached-operator-catalog-pod": Pending (ImagePullBackOff): Back-off pulling image`,
+			`Subscription "memcached-operator-v0-0-1-sub": UpgradePending`,
+			`InstallPlan "install-abcde": Installing: 1 of 2 steps installed`,
+			`ClusterServiceVersion "memcached-operator.v0.0.1": Installing (InstallWaiting): ` +
+				`installing: waiting for deployment memcached-operator-controller-manager to become ready`,
+			`Deployment "memcached-operator-controller-manager": RollingOut (MinimumReplicasUnavailable): ` +
+				`1 of 1 replicas updated, 0 available`,
+		}))
+	})
+
+	It("reports only resources whose state changed", func() {
+		tracker.poll(context.TODO())
+		Expect(decodeEvents()).To(HaveLen(6))
+
+		tracker.poll(context.TODO())
+		Expect(decodeEvents()).To(BeEmpty())
+
+		csv.Status.Phase = v1alpha1.CSVPhaseSucceeded
+		csv.Status.Reason = v1alpha1.CSVReasonInstallSuccessful
+		csv.Status.Message = "install strategy completed with no errors"
+		Expect(client.Status().Update(context.TODO(), csv)).To(Succeed())
+
+		tracker.poll(context.TODO())
+		events := decodeEvents()
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Kind).To(Equal(v1alpha1.ClusterServiceVersionKind))
+		Expect(events[0].Status).To(Equal("Succeeded"))
+		Expect(events[0].Reason).To(Equal("InstallSucceeded"))
+	})
+
+	It("adds the last observed state of each resource to errors", func() {
+		timeout := errors.New("timed out waiting for the condition")
+		Expect(tracker.wrapError(timeout)).To(Equal(timeout))
+
+		stop := tracker.start(context.TODO(), time.Hour)
+		stop()
+		err := tracker.wrapError(timeout)
+		Expect(errors.Is(err, timeout)).To(BeTrue())
+		lines := strings.Split(err.Error(), "\n")
+		Expect(lines).To(HaveLen(8))
+		Expect(lines[0]).To(Equal("timed out waiting for the condition"))
+		Expect(lines[1]).To(Equal("Last observed state:"))
+		Expect(lines[2]).To(Equal(`  CatalogSource "memcached-operator-catalog": READY`))
+	})
+})
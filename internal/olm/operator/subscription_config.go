@@ -0,0 +1,224 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+// SubscriptionConfig holds the flags that configure an operator's deployment
+// through its Subscription's spec.config, which OLM applies to the operator's
+// pods. Flags are applied on top of the config file.
+type SubscriptionConfig struct {
+	// File is a YAML file containing a Subscription's spec.config.
+	File string
+	// Env are environment variables of the form "NAME=value".
+	Env []string
+	// NodeSelector is merged into the config's node selector.
+	NodeSelector map[string]string
+	// Tolerations are of the form "key[=value][:effect]".
+	Tolerations []string
+	// ResourceLimits and ResourceRequests map resource names to quantities.
+	ResourceLimits   map[string]string
+	ResourceRequests map[string]string
+}
+
+func (c *SubscriptionConfig) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&c.File, "subscription-config", "",
+		"YAML file containing a Subscription's spec.config, which configures the operator's env, envFrom, "+
+			"resources, nodeSelector, tolerations, volumes and volumeMounts. Other subscription config flags "+
+			"are applied on top of it")
+	fs.StringArrayVar(&c.Env, "subscription-env", nil,
+		"environment variable of the operator, of the form NAME=value. This flag can be specified multiple times")
+	fs.StringToStringVar(&c.NodeSelector, "subscription-node-selector", nil,
+		"node labels of the operator's node selector, of the form key=value,...")
+	fs.StringArrayVar(&c.Tolerations, "subscription-toleration", nil,
+		"toleration of the operator's pods, of the form key[=value][:effect]. This flag can be specified multiple times")
+	fs.StringToStringVar(&c.ResourceLimits, "subscription-resource-limits", nil,
+		"resource limits of the operator, of the form cpu=500m,memory=256Mi")
+	fs.StringToStringVar(&c.ResourceRequests, "subscription-resource-requests", nil,
+		"resource requests of the operator, of the form cpu=100m,memory=64Mi")
+}
+
+// IsEmpty returns true if no subscription config was set.
+func (c SubscriptionConfig) IsEmpty() bool {
+	return c.File == "" && len(c.Env) == 0 && len(c.NodeSelector) == 0 && len(c.Tolerations) == 0 &&
+		len(c.ResourceLimits) == 0 && len(c.ResourceRequests) == 0
+}
+
+// Build returns the validated spec.config of a Subscription from c,
+// or nil if c is empty.
+func (c SubscriptionConfig) Build() (*v1alpha1.SubscriptionConfig, error) {
+	if c.IsEmpty() {
+		return nil, nil
+	}
+
+	config := &v1alpha1.SubscriptionConfig{}
+	if c.File != "" {
+		b, err := ioutil.ReadFile(c.File)
+		if err != nil {
+			return nil, fmt.Errorf("error reading subscription config: %v", err)
+		}
+		if err := yaml.UnmarshalStrict(b, config); err != nil {
+			return nil, fmt.Errorf("error decoding subscription config %s: %v", c.File, err)
+		}
+	}
+
+	for _, env := range c.Env {
+		kv := strings.SplitN(env, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid env %q: must be of the form NAME=value", env)
+		}
+		config.Env = setEnv(config.Env, corev1.EnvVar{Name: kv[0], Value: kv[1]})
+	}
+
+	for k, v := range c.NodeSelector {
+		if config.NodeSelector == nil {
+			config.NodeSelector = map[string]string{}
+		}
+		config.NodeSelector[k] = v
+	}
+
+	for _, t := range c.Tolerations {
+		toleration, err := k8sutil.ParseToleration(t)
+		if err != nil {
+			return nil, err
+		}
+		config.Tolerations = append(config.Tolerations, toleration)
+	}
+
+	var err error
+	if config.Resources.Limits, err = mergeResources(config.Resources.Limits, c.ResourceLimits); err != nil {
+		return nil, fmt.Errorf("invalid resource limits: %v", err)
+	}
+	if config.Resources.Requests, err = mergeResources(config.Resources.Requests, c.ResourceRequests); err != nil {
+		return nil, fmt.Errorf("invalid resource requests: %v", err)
+	}
+
+	if err := ValidateSubscriptionConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid subscription config: %v", err)
+	}
+	return config, nil
+}
+
+// setEnv sets env in envs, replacing a variable of the same name.
+func setEnv(envs []corev1.EnvVar, env corev1.EnvVar) []corev1.EnvVar {
+	for i := range envs {
+		if envs[i].Name == env.Name {
+			envs[i] = env
+			return envs
+		}
+	}
+	return append(envs, env)
+}
+
+func mergeResources(list corev1.ResourceList, quantities map[string]string) (corev1.ResourceList, error) {
+	for name, q := range quantities {
+		quantity, err := resource.ParseQuantity(q)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		if list == nil {
+			list = corev1.ResourceList{}
+		}
+		list[corev1.ResourceName(name)] = quantity
+	}
+	return list, nil
+}
+
+// ValidateSubscriptionConfig returns an error for each invalid field of config,
+// so that invalid configs fail before OLM fails to deploy the operator.
+func ValidateSubscriptionConfig(config *v1alpha1.SubscriptionConfig) error {
+	var errs []error
+	addErrs := func(field string, msgs []string) {
+		for _, msg := range msgs {
+			errs = append(errs, fmt.Errorf("%s: %s", field, msg))
+		}
+	}
+
+	for i, env := range config.Env {
+		addErrs(fmt.Sprintf("env[%d].name", i), validation.IsEnvVarName(env.Name))
+	}
+	for i, envFrom := range config.EnvFrom {
+		if (envFrom.ConfigMapRef == nil) == (envFrom.SecretRef == nil) {
+			errs = append(errs, fmt.Errorf("envFrom[%d]: exactly one of configMapRef or secretRef must be set", i))
+		}
+	}
+
+	keys := make([]string, 0, len(config.NodeSelector))
+	for k := range config.NodeSelector {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		addErrs(fmt.Sprintf("nodeSelector[%s]", k), validation.IsQualifiedName(k))
+		addErrs(fmt.Sprintf("nodeSelector[%s]", k), validation.IsValidLabelValue(config.NodeSelector[k]))
+	}
+
+	for i, t := range config.Tolerations {
+		field := fmt.Sprintf("tolerations[%d]", i)
+		switch t.Effect {
+		case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			errs = append(errs, fmt.Errorf("%s: unknown effect %q", field, t.Effect))
+		}
+		if t.Key == "" && t.Operator != corev1.TolerationOpExists {
+			errs = append(errs, fmt.Errorf("%s: a toleration without a key must use the Exists operator", field))
+		}
+		if t.Operator == corev1.TolerationOpExists && t.Value != "" {
+			errs = append(errs, fmt.Errorf("%s: a toleration with the Exists operator must not have a value", field))
+		}
+	}
+
+	for name, request := range config.Resources.Requests {
+		if limit, ok := config.Resources.Limits[name]; ok && request.Cmp(limit) > 0 {
+			errs = append(errs, fmt.Errorf("resources: %s request %s exceeds its limit %s",
+				name, request.String(), limit.String()))
+		}
+	}
+
+	volumes := map[string]bool{}
+	for i, v := range config.Volumes {
+		addErrs(fmt.Sprintf("volumes[%d].name", i), validation.IsDNS1123Label(v.Name))
+		if volumes[v.Name] {
+			errs = append(errs, fmt.Errorf("volumes[%d].name: duplicate volume %q", i, v.Name))
+		}
+		volumes[v.Name] = true
+	}
+	for i, m := range config.VolumeMounts {
+		if m.Name == "" {
+			errs = append(errs, fmt.Errorf("volumeMounts[%d].name: must be set", i))
+		}
+		if !path.IsAbs(m.MountPath) {
+			errs = append(errs, fmt.Errorf("volumeMounts[%d].mountPath: %q must be an absolute path", i, m.MountPath))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
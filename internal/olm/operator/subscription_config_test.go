@@ -0,0 +1,131 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("SubscriptionConfig", func() {
+	writeFile := func(content string) string {
+		f, err := ioutil.TempFile("", "subscription-config-*.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		_, err = f.WriteString(content)
+		Expect(err).NotTo(HaveOccurred())
+		return f.Name()
+	}
+
+	Describe("Build", func() {
+		It("returns nil without config", func() {
+			Expect(SubscriptionConfig{}.Build()).To(BeNil())
+		})
+		It("applies flags on top of the config file", func() {
+			file := writeFile(`env:
+- name: LOG_LEVEL
+  value: info
+- name: WATCH_TIMEOUT
+  value: 10s
+nodeSelector:
+  kubernetes.io/os: linux
+resources:
+  limits:
+    cpu: 500m
+volumes:
+- name: certs
+  secret:
+    secretName: certs
+volumeMounts:
+- name: certs
+  mountPath: /etc/certs
+`)
+			defer os.Remove(file)
+
+			config, err := SubscriptionConfig{
+				File:             file,
+				Env:              []string{"LOG_LEVEL=debug", "EXTRA=a=b"},
+				NodeSelector:     map[string]string{"kubernetes.io/arch": "amd64"},
+				Tolerations:      []string{"dedicated=operators:NoSchedule", "node.kubernetes.io/unreachable"},
+				ResourceRequests: map[string]string{"cpu": "100m"},
+			}.Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Env).To(Equal([]corev1.EnvVar{
+				{Name: "LOG_LEVEL", Value: "debug"},
+				{Name: "WATCH_TIMEOUT", Value: "10s"},
+				{Name: "EXTRA", Value: "a=b"},
+			}))
+			Expect(config.NodeSelector).To(Equal(map[string]string{
+				"kubernetes.io/os":   "linux",
+				"kubernetes.io/arch": "amd64",
+			}))
+			Expect(config.Tolerations).To(Equal([]corev1.Toleration{
+				{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "operators", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "node.kubernetes.io/unreachable", Operator: corev1.TolerationOpExists},
+			}))
+			Expect(config.Resources.Limits.Cpu().String()).To(Equal("500m"))
+			Expect(config.Resources.Requests.Cpu().String()).To(Equal("100m"))
+			Expect(config.Volumes).To(HaveLen(1))
+			Expect(config.VolumeMounts).To(HaveLen(1))
+		})
+		It("rejects unknown fields in the config file", func() {
+			file := writeFile("envs:\n- name: A\n")
+			defer os.Remove(file)
+			_, err := SubscriptionConfig{File: file}.Build()
+			Expect(err).To(MatchError(ContainSubstring(`unknown field "envs"`)))
+		})
+		It("rejects invalid flags", func() {
+			_, err := SubscriptionConfig{Env: []string{"LOG_LEVEL"}}.Build()
+			Expect(err).To(MatchError(ContainSubstring("must be of the form NAME=value")))
+			_, err = SubscriptionConfig{ResourceLimits: map[string]string{"memory": "lots"}}.Build()
+			Expect(err).To(MatchError(ContainSubstring("invalid resource limits: memory")))
+		})
+	})
+
+	Describe("ValidateSubscriptionConfig", func() {
+		It("returns all errors", func() {
+			err := ValidateSubscriptionConfig(&v1alpha1.SubscriptionConfig{
+				Env:          []corev1.EnvVar{{Name: "1INVALID"}},
+				EnvFrom:      []corev1.EnvFromSource{{}},
+				NodeSelector: map[string]string{"disk": "not valid"},
+				Tolerations:  []corev1.Toleration{{Key: "a", Effect: "Never"}},
+				Resources: corev1.ResourceRequirements{
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+				},
+				Volumes:      []corev1.Volume{{Name: "certs"}, {Name: "certs"}},
+				VolumeMounts: []corev1.VolumeMount{{Name: "certs", MountPath: "certs"}},
+			})
+			Expect(err).To(HaveOccurred())
+			for _, msg := range []string{
+				"env[0].name",
+				"envFrom[0]: exactly one of configMapRef or secretRef must be set",
+				"nodeSelector[disk]",
+				`tolerations[0]: unknown effect "Never"`,
+				"resources: memory request 128Mi exceeds its limit 64Mi",
+				`volumes[1].name: duplicate volume "certs"`,
+				`volumeMounts[0].mountPath: "certs" must be an absolute path`,
+			} {
+				Expect(err.Error()).To(ContainSubstring(msg))
+			}
+		})
+	})
+})
@@ -16,6 +16,7 @@ package operator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -24,7 +25,11 @@ import (
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -45,6 +50,10 @@ type Uninstall struct {
 	DeleteCRDs               bool
 	DeleteOperatorGroups     bool
 	DeleteOperatorGroupNames []string
+	// DryRun logs the objects that would be deleted without deleting them.
+	DryRun bool
+	// Wait blocks until the CRDs, CSV and catalog source are deleted.
+	Wait bool
 
 	Logf func(string, ...interface{})
 }
@@ -52,6 +61,7 @@ type Uninstall struct {
 func NewUninstall(cfg *Configuration) *Uninstall {
 	return &Uninstall{
 		config: cfg,
+		Wait:   true,
 	}
 }
 
@@ -59,6 +69,10 @@ func (u *Uninstall) BindFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&u.DeleteCRDs, "delete-crds", false, "If set to true, owned CRDs and CRs will be deleted")
 	fs.BoolVar(&u.DeleteAll, "delete-all", true, "If set to true, all other delete options will be enabled")
 	fs.BoolVar(&u.DeleteOperatorGroups, "delete-operator-groups", false, "If set to true, operator groups will be deleted")
+	fs.BoolVar(&u.DryRun, "dry-run", false, "If set to true, the resources that would be deleted are listed "+
+		"without being deleted")
+	fs.BoolVar(&u.Wait, "wait", true, "If set to true, wait until the CRDs, CSV and catalog source are deleted, "+
+		"failing if they are not deleted within --timeout")
 }
 
 type ErrPackageNotFound struct {
@@ -69,22 +83,99 @@ func (e ErrPackageNotFound) Error() string {
 	return fmt.Sprintf("package %q not found", e.PackageName)
 }
 
+// uninstallPlan holds the objects of a package found in the cluster.
+// Nil objects were not found.
+type uninstallPlan struct {
+	subscription  client.Object
+	csv           client.Object
+	catalogSource client.Object
+	crds          []client.Object
+	// registryObjects are the registry pods, ConfigMaps, Deployments and Services
+	// owned by the catalog source, which are garbage collected with it.
+	// Only set for dry runs.
+	registryObjects []client.Object
+	// operatorGroups are the operator groups deleted once the subscription is,
+	// if it is the last in the namespace. Only set for dry runs.
+	operatorGroups []client.Object
+}
+
+func (p uninstallPlan) isEmpty() bool {
+	return p.subscription == nil && p.csv == nil && p.catalogSource == nil && len(p.crds) == 0
+}
+
 func (u *Uninstall) Run(ctx context.Context) error {
 	if u.DeleteAll {
 		u.DeleteCRDs = true
 		u.DeleteOperatorGroups = true
 	}
 
+	plan, err := u.getPlan(ctx)
+	if err != nil {
+		return err
+	}
+	u.reportCustomResources(ctx, plan)
+
+	if u.DryRun {
+		if plan.isEmpty() {
+			return &ErrPackageNotFound{u.Package}
+		}
+		u.addDryRunObjects(ctx, &plan)
+		return u.logPlan(plan)
+	}
+
+	// Deletion order:
+	//
+	// 1. Subscription to prevent further installs or upgrades of the operator while cleaning up.
+	// 2. CustomResourceDefinitions so the operator has a chance to handle CRs that have finalizers.
+	// 3. ClusterServiceVersion. OLM puts an ownerref on every namespaced resource to the CSV,
+	//    and an owner label on every cluster scoped resource so they get gc'd on deletion.
+	// 4. CatalogSource. All other resources installed by OLM or operator-sdk related to this
+	//    package will be gc'd.
+
+	// Subscriptions can be deleted asynchronously.
+	if err := u.deleteObjects(ctx, false, plan.subscription); err != nil {
+		return err
+	}
+	var objs []client.Object
+
+	if u.DeleteCRDs {
+		objs = append(objs, plan.crds...)
+	} else {
+		log.Info("Skipping CRD deletion")
+
+	}
+
+	objs = append(objs, plan.csv, plan.catalogSource)
+	// These objects may have owned resources/finalizers, so block on deletion unless told not to.
+	if err := u.deleteObjects(ctx, u.Wait, objs...); err != nil {
+		return err
+	}
+
+	// If the last subscription in the namespace was deleted and the operator group is
+	// the one operator-sdk created, delete it.
+	if u.DeleteOperatorGroups {
+		if err := u.deleteOperatorGroup(ctx); err != nil {
+			return err
+		}
+	} else {
+		log.Info("Skipping Operator Groups deletion")
+	}
+
+	// If no objects were cleaned up, the package was not found.
+	if plan.isEmpty() {
+		return &ErrPackageNotFound{u.Package}
+	}
+	return nil
+}
+
+// getPlan finds the subscription, CSV, CRDs and catalog source of u.Package that Run deletes.
+func (u *Uninstall) getPlan(ctx context.Context) (plan uninstallPlan, err error) {
 	subs := v1alpha1.SubscriptionList{}
 	if err := u.config.Client.List(ctx, &subs, client.InNamespace(u.config.Namespace)); err != nil {
-		return fmt.Errorf("list subscriptions: %v", err)
+		return plan, fmt.Errorf("list subscriptions: %v", err)
 	}
 
-	// Use nil objects to determine if the underlying was found.
-	// If it was, the object will != nil.
-	var subObj, csvObj, csObj client.Object
 	var sub *v1alpha1.Subscription
-	var crds []client.Object
 	catsrc := &v1alpha1.CatalogSource{}
 	catsrc.SetNamespace(u.config.Namespace)
 	catsrc.SetName(CatalogNameForPackage(u.Package))
@@ -99,7 +190,7 @@ func (u *Uninstall) Run(ctx context.Context) error {
 
 	catsrcKey := client.ObjectKeyFromObject(catsrc)
 	if sub != nil {
-		subObj = sub
+		plan.subscription = sub
 		// Use the subscription's catalog source data only if available.
 		keyFromSpec := types.NamespacedName{
 			Namespace: sub.Spec.CatalogSourceNamespace,
@@ -122,64 +213,181 @@ func (u *Uninstall) Run(ctx context.Context) error {
 		if csvKey.Name != "" {
 			csv := &v1alpha1.ClusterServiceVersion{}
 			if err := u.config.Client.Get(ctx, csvKey, csv); err != nil && !apierrors.IsNotFound(err) {
-				return fmt.Errorf("error getting installed CSV %q: %v", csvKey.Name, err)
+				return plan, fmt.Errorf("error getting installed CSV %q: %v", csvKey.Name, err)
 			} else if err == nil {
-				crds = getCRDs(csv)
+				plan.csv = csv
+				plan.crds = getCRDs(csv)
 			}
-			csvObj = csv
 		}
 	}
 
 	// Get the catalog source to make sure the correct error is returned.
 	if err := u.config.Client.Get(ctx, catsrcKey, catsrc); err == nil {
-		csObj = catsrc
+		plan.catalogSource = catsrc
 	} else if !apierrors.IsNotFound(err) {
-		return fmt.Errorf("error get catalog source: %v", err)
+		return plan, fmt.Errorf("error get catalog source: %v", err)
 	}
 
-	// Deletion order:
-	//
-	// 1. Subscription to prevent further installs or upgrades of the operator while cleaning up.
-	// 2. CustomResourceDefinitions so the operator has a chance to handle CRs that have finalizers.
-	// 3. ClusterServiceVersion. OLM puts an ownerref on every namespaced resource to the CSV,
-	//    and an owner label on every cluster scoped resource so they get gc'd on deletion.
-	// 4. CatalogSource. All other resources installed by OLM or operator-sdk related to this
-	//    package will be gc'd.
+	return plan, nil
+}
 
-	// Subscriptions can be deleted asynchronously.
-	if err := u.deleteObjects(ctx, false, subObj); err != nil {
-		return err
+// addDryRunObjects adds the registry objects and operator groups that Run would delete to plan.
+// These are only listed, so failing to list them is not fatal.
+func (u *Uninstall) addDryRunObjects(ctx context.Context, plan *uninstallPlan) {
+	if catsrc, ok := plan.catalogSource.(*v1alpha1.CatalogSource); ok {
+		objs, err := u.getRegistryObjects(ctx, catsrc)
+		if err != nil {
+			log.Warnf("Failed to list registry objects of catalog source %q: %v", catsrc.GetName(), err)
+		}
+		plan.registryObjects = objs
 	}
-	var objs []client.Object
 
-	if u.DeleteCRDs {
-		objs = append(objs, crds...)
-	} else {
-		log.Info("Skipping CRD deletion")
+	// Operator groups are deleted if no other subscription is left in the namespace.
+	if !u.DeleteOperatorGroups {
+		return
+	}
+	subs := v1alpha1.SubscriptionList{}
+	if err := u.config.Client.List(ctx, &subs, client.InNamespace(u.config.Namespace)); err != nil {
+		log.Warnf("Failed to list subscriptions: %v", err)
+		return
+	}
+	if len(subs.Items) > 1 || (len(subs.Items) == 1 && plan.subscription == nil) {
+		return
+	}
+	ogs := v1.OperatorGroupList{}
+	if err := u.config.Client.List(ctx, &ogs, client.InNamespace(u.config.Namespace)); err != nil {
+		log.Warnf("Failed to list operator groups: %v", err)
+		return
+	}
+	for i := range ogs.Items {
+		og := &ogs.Items[i]
+		if len(u.DeleteOperatorGroupNames) == 0 || slice.ContainsString(u.DeleteOperatorGroupNames, og.GetName(), nil) {
+			plan.operatorGroups = append(plan.operatorGroups, og)
+		}
+	}
+}
 
+// getRegistryObjects returns the objects in the namespace of catsrc that it owns,
+// which serve the registry of a catalog source created by operator-sdk.
+func (u *Uninstall) getRegistryObjects(ctx context.Context, catsrc *v1alpha1.CatalogSource) ([]client.Object, error) {
+	var objs []client.Object
+	lists := []client.ObjectList{&corev1.PodList{}, &corev1.ConfigMapList{}, &appsv1.DeploymentList{}, &corev1.ServiceList{}}
+	for _, list := range lists {
+		if err := u.config.Client.List(ctx, list, client.InNamespace(catsrc.GetNamespace())); err != nil {
+			return objs, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return objs, err
+		}
+		for _, item := range items {
+			obj := item.(client.Object)
+			for _, ref := range obj.GetOwnerReferences() {
+				if ref.Kind == v1alpha1.CatalogSourceKind && ref.Name == catsrc.GetName() {
+					objs = append(objs, obj)
+					break
+				}
+			}
+		}
 	}
+	return objs, nil
+}
 
-	objs = append(objs, csvObj, csObj)
-	// These objects may have owned resources/finalizers, so block on deletion.
-	if err := u.deleteObjects(ctx, true, objs...); err != nil {
-		return err
+// getCustomResources returns the CRs in all namespaces of the CRD named crdName,
+// or none if the CRD does not exist.
+func (u *Uninstall) getCustomResources(ctx context.Context, crdName string) ([]unstructured.Unstructured, error) {
+	crd := &apiextv1.CustomResourceDefinition{}
+	if err := u.config.Client.Get(ctx, types.NamespacedName{Name: crdName}, crd); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	version := ""
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			version = v.Name
+		}
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   crd.Spec.Group,
+		Version: version,
+		Kind:    crd.Spec.Names.ListKind,
+	})
+	if err := u.config.Client.List(ctx, list); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// reportCustomResources logs the CRs that are deleted with their CRDs,
+// or orphaned if CRDs are not deleted. CRs that cannot be listed are not reported.
+func (u *Uninstall) reportCustomResources(ctx context.Context, plan uninstallPlan) {
+	verb := "will be"
+	if u.DryRun {
+		verb = "would be"
+	}
+	for _, crd := range plan.crds {
+		crs, err := u.getCustomResources(ctx, crd.GetName())
+		if err != nil {
+			log.Warnf("Failed to list CRs of CRD %q: %v", crd.GetName(), err)
+			continue
+		}
+		if len(crs) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(crs))
+		for _, cr := range crs {
+			name := cr.GetName()
+			if cr.GetNamespace() != "" {
+				name = cr.GetNamespace() + "/" + name
+			}
+			names = append(names, name)
+		}
+		if u.DeleteCRDs {
+			u.Logf("%d CRs of CRD %q %s deleted with it: %s", len(crs), crd.GetName(), verb, strings.Join(names, ", "))
+		} else {
+			u.Logf("%d CRs of CRD %q %s orphaned, since the CRD is not deleted: %s",
+				len(crs), crd.GetName(), verb, strings.Join(names, ", "))
+		}
 	}
+}
 
-	// If the last subscription in the namespace was deleted and the operator group is
-	// the one operator-sdk created, delete it.
+// logPlan logs the objects that Run would delete.
+func (u *Uninstall) logPlan(plan uninstallPlan) error {
+	objs := []client.Object{plan.subscription}
+	if u.DeleteCRDs {
+		objs = append(objs, plan.crds...)
+	}
+	objs = append(objs, plan.csv, plan.catalogSource)
+	objs = append(objs, plan.registryObjects...)
 	if u.DeleteOperatorGroups {
-		if err := u.deleteOperatorGroup(ctx); err != nil {
+		objs = append(objs, plan.operatorGroups...)
+	}
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		lowerKind, err := u.kindOf(obj)
+		if err != nil {
 			return err
 		}
-	} else {
-		log.Info("Skipping Operator Groups deletion")
+		u.Logf("%s %q would be deleted", lowerKind, obj.GetName())
 	}
+	return nil
+}
 
-	// If no objects were cleaned up, the package was not found.
-	if subObj == nil && csObj == nil && csvObj == nil && len(crds) == 0 {
-		return &ErrPackageNotFound{u.Package}
+// kindOf returns the lowercase kind of obj.
+func (u *Uninstall) kindOf(obj client.Object) (string, error) {
+	gvks, _, err := u.config.Scheme.ObjectKinds(obj)
+	if err != nil {
+		return "", err
 	}
-	return nil
+	return strings.ToLower(gvks[0].Kind), nil
 }
 
 func (u *Uninstall) deleteOperatorGroup(ctx context.Context) error {
@@ -209,11 +417,10 @@ func (u *Uninstall) deleteObjects(ctx context.Context, waitForDelete bool, objs
 		if obj == nil {
 			continue
 		}
-		gvks, _, err := u.config.Scheme.ObjectKinds(obj)
+		lowerKind, err := u.kindOf(obj)
 		if err != nil {
 			return err
 		}
-		lowerKind := strings.ToLower(gvks[0].Kind)
 		if err := u.config.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
 			return fmt.Errorf("delete %s %q: %v", lowerKind, obj.GetName(), err)
 		} else if err == nil {
@@ -229,7 +436,11 @@ func (u *Uninstall) deleteObjects(ctx context.Context, waitForDelete bool, objs
 				}
 				return false, nil
 			}, ctx.Done()); err != nil {
-				return fmt.Errorf("wait for %s deleted: %v", lowerKind, err)
+				if errors.Is(err, wait.ErrWaitTimeout) {
+					return fmt.Errorf("timed out waiting for %s %q to be deleted, "+
+						"increase --timeout or set --wait=false to not wait for it", lowerKind, obj.GetName())
+				}
+				return fmt.Errorf("wait for %s %q deleted: %v", lowerKind, obj.GetName(), err)
 			}
 		}
 	}
@@ -0,0 +1,186 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Uninstall", func() {
+	const (
+		ns      = "default"
+		pkg     = "memcached-operator"
+		csvName = "memcached-operator.v0.1.0"
+		crdName = "memcacheds.cache.example.com"
+	)
+
+	var (
+		u    *Uninstall
+		logs []string
+	)
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		Expect(v1.AddToScheme(sch)).To(Succeed())
+		Expect(apiextv1.AddToScheme(sch)).To(Succeed())
+		// The fake client only lists CRs of kinds registered in its scheme.
+		crGV := schema.GroupVersion{Group: "cache.example.com", Version: "v1beta1"}
+		sch.AddKnownTypeWithName(crGV.WithKind("Memcached"), &unstructured.Unstructured{})
+		sch.AddKnownTypeWithName(crGV.WithKind("MemcachedList"), &unstructured.UnstructuredList{})
+
+		catsrc := &v1alpha1.CatalogSource{ObjectMeta: metav1.ObjectMeta{Name: CatalogNameForPackage(pkg), Namespace: ns}}
+		owner := []metav1.OwnerReference{{APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind: v1alpha1.CatalogSourceKind, Name: catsrc.GetName()}}
+		sub := &v1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{Name: pkg + "-sub", Namespace: ns},
+			Spec: &v1alpha1.SubscriptionSpec{
+				Package:                pkg,
+				CatalogSource:          catsrc.GetName(),
+				CatalogSourceNamespace: ns,
+			},
+			Status: v1alpha1.SubscriptionStatus{InstalledCSV: csvName},
+		}
+		csv := &v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: csvName, Namespace: ns},
+			Status: v1alpha1.ClusterServiceVersionStatus{RequirementStatus: []v1alpha1.RequirementStatus{{
+				Group: "apiextensions.k8s.io", Version: "v1", Kind: crdKind, Name: crdName,
+			}}},
+		}
+		crd := &apiextv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: crdName},
+			Spec: apiextv1.CustomResourceDefinitionSpec{
+				Group: "cache.example.com",
+				Names: apiextv1.CustomResourceDefinitionNames{Kind: "Memcached", ListKind: "MemcachedList"},
+				Versions: []apiextv1.CustomResourceDefinitionVersion{
+					{Name: "v1alpha1"},
+					{Name: "v1beta1", Storage: true},
+				},
+			},
+		}
+		cr := &unstructured.Unstructured{}
+		cr.SetAPIVersion("cache.example.com/v1beta1")
+		cr.SetKind("Memcached")
+		cr.SetNamespace("memcached")
+		cr.SetName("memcached-sample")
+		objs := []client.Object{
+			catsrc, sub, csv, crd, cr,
+			&v1.OperatorGroup{ObjectMeta: metav1.ObjectMeta{Name: SDKOperatorGroupName, Namespace: ns}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: ns, OwnerReferences: owner}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "registry-bundles", Namespace: ns, OwnerReferences: owner}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: ns}},
+		}
+
+		cfg := &Configuration{
+			Namespace: ns,
+			Scheme:    sch,
+			Client:    fake.NewClientBuilder().WithScheme(sch).WithObjects(objs...).Build(),
+		}
+		logs = nil
+		u = NewUninstall(cfg)
+		u.Package = pkg
+		u.DeleteAll = true
+		u.DeleteOperatorGroupNames = []string{SDKOperatorGroupName}
+		u.Logf = func(format string, args ...interface{}) { logs = append(logs, fmt.Sprintf(format, args...)) }
+	})
+
+	It("lists the resources that would be deleted with --dry-run", func() {
+		u.DryRun = true
+		Expect(u.Run(context.TODO())).To(Succeed())
+		Expect(logs).To(Equal([]string{
+			`1 CRs of CRD "memcacheds.cache.example.com" would be deleted with it: memcached/memcached-sample`,
+			`subscription "memcached-operator-sub" would be deleted`,
+			`customresourcedefinition "memcacheds.cache.example.com" would be deleted`,
+			`clusterserviceversion "memcached-operator.v0.1.0" would be deleted`,
+			`catalogsource "memcached-operator-catalog" would be deleted`,
+			`pod "registry" would be deleted`,
+			`configmap "registry-bundles" would be deleted`,
+			`operatorgroup "operator-sdk-og" would be deleted`,
+		}))
+
+		sub := &v1alpha1.Subscription{}
+		Expect(u.config.Client.Get(context.TODO(), client.ObjectKey{Namespace: ns, Name: pkg + "-sub"}, sub)).To(Succeed())
+	})
+
+	It("reports CRs orphaned when CRDs are kept", func() {
+		u.DryRun = true
+		u.DeleteAll = false
+		Expect(u.Run(context.TODO())).To(Succeed())
+		Expect(logs).To(ContainElement(`1 CRs of CRD "memcacheds.cache.example.com" would be orphaned, ` +
+			`since the CRD is not deleted: memcached/memcached-sample`))
+		Expect(logs).NotTo(ContainElement(ContainSubstring("customresourcedefinition")))
+		Expect(logs).NotTo(ContainElement(ContainSubstring("operatorgroup")))
+	})
+
+	It("deletes the resources and reports deleted CRs", func() {
+		Expect(u.Run(context.TODO())).To(Succeed())
+		Expect(logs).To(ContainElements(
+			`1 CRs of CRD "memcacheds.cache.example.com" will be deleted with it: memcached/memcached-sample`,
+			`subscription "memcached-operator-sub" deleted`,
+			`customresourcedefinition "memcacheds.cache.example.com" deleted`,
+			`catalogsource "memcached-operator-catalog" deleted`,
+		))
+
+		csv := &v1alpha1.ClusterServiceVersion{}
+		err := u.config.Client.Get(context.TODO(), client.ObjectKey{Namespace: ns, Name: csvName}, csv)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("lists the other resources with --dry-run if registry objects cannot be listed", func() {
+		u.DryRun = true
+		u.config.Client = failingPodListClient{u.config.Client}
+		Expect(u.Run(context.TODO())).To(Succeed())
+		Expect(logs).To(ContainElements(
+			`catalogsource "memcached-operator-catalog" would be deleted`,
+			`operatorgroup "operator-sdk-og" would be deleted`,
+		))
+		Expect(logs).NotTo(ContainElement(ContainSubstring("pod")))
+	})
+
+	It("returns ErrPackageNotFound for an unknown package", func() {
+		u.DryRun = true
+		u.Package = "unknown"
+		Expect(u.Run(context.TODO())).To(MatchError(&ErrPackageNotFound{"unknown"}))
+	})
+})
+
+// failingPodListClient fails to list pods.
+type failingPodListClient struct {
+	client.Client
+}
+
+func (c failingPodListClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*corev1.PodList); ok {
+		return apierrors.NewForbidden(corev1.Resource("pods"), "", fmt.Errorf("not allowed"))
+	}
+	return c.Client.List(ctx, list, opts...)
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+	"github.com/operator-framework/operator-registry/pkg/lib/certs"
+	"github.com/spf13/pflag"
+)
+
+// PullOptions configure how images are pulled from private or local registries.
+type PullOptions struct {
+	// CAFile is a PEM file of root certificates trusted in addition to the system's.
+	CAFile string
+	// SkipTLS pulls images over plain HTTP, or without verifying TLS certificates.
+	SkipTLS bool
+	// Mirrors replace the registries or repositories of images before they are pulled.
+	Mirrors ImageMirrors
+}
+
+func (o *PullOptions) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.CAFile, "ca-file", "",
+		"PEM file of root certificates of private registries with custom TLS, trusted in addition "+
+			"to the system's when pulling bundle and index images")
+	fs.BoolVar(&o.SkipTLS, "skip-tls", false,
+		"Pull bundle images over plain HTTP or without verifying TLS certificates, such as from "+
+			"a local development registry. This applies to all registries")
+	fs.Var(&o.Mirrors, "image-mirror",
+		"Mirror of the registry or repository of bundle and index images, of the form source=mirror, "+
+			"such as quay.io/operator-framework=mirror.example.com/operator-framework. "+
+			"This flag can be specified multiple times")
+}
+
+// RegistryOptions returns options of a containerd registry pulling images with o.
+func (o PullOptions) RegistryOptions() ([]containerdregistry.RegistryOption, error) {
+	var opts []containerdregistry.RegistryOption
+	if o.CAFile != "" {
+		roots, err := certs.RootCAs(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("load CA file: %v", err)
+		}
+		opts = append(opts, containerdregistry.WithRootCAs(roots))
+	}
+	if o.SkipTLS {
+		opts = append(opts, containerdregistry.SkipTLS(true))
+	}
+	return opts, nil
+}
+
+// ImageMirror replaces the Source registry or repository of image references with Mirror.
+type ImageMirror struct {
+	Source string
+	Mirror string
+}
+
+// ImageMirrors is a list of image mirrors, which can be set by a flag of the form source=mirror.
+type ImageMirrors []ImageMirror
+
+func (m *ImageMirrors) Set(str string) error {
+	split := strings.SplitN(str, "=", 2)
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return fmt.Errorf("image mirror %q must be of the form source=mirror", str)
+	}
+	*m = append(*m, ImageMirror{
+		Source: strings.TrimSuffix(split[0], "/"),
+		Mirror: strings.TrimSuffix(split[1], "/"),
+	})
+	return nil
+}
+
+func (m ImageMirrors) String() string {
+	strs := make([]string, len(m))
+	for i, mirror := range m {
+		strs[i] = mirror.Source + "=" + mirror.Mirror
+	}
+	return strings.Join(strs, ",")
+}
+
+func (ImageMirrors) Type() string {
+	return "stringArray"
+}
+
+// Apply returns image with the source of the mirror matching the longest part of image
+// replaced by that mirror, or image if no mirror matches.
+func (m ImageMirrors) Apply(image string) string {
+	match := -1
+	for i, mirror := range m {
+		if !hasRepoPrefix(image, mirror.Source) {
+			continue
+		}
+		if match == -1 || len(mirror.Source) > len(m[match].Source) {
+			match = i
+		}
+	}
+	if match == -1 {
+		return image
+	}
+	return m[match].Mirror + strings.TrimPrefix(image, m[match].Source)
+}
+
+// hasRepoPrefix returns true if image is source, or is in the registry or repository source.
+// Only a repository is followed by a tag, so ":" is only a separator if source contains "/",
+// and registry localhost does not match localhost:5000/image.
+func hasRepoPrefix(image, source string) bool {
+	if !strings.HasPrefix(image, source) {
+		return false
+	}
+	rest := image[len(source):]
+	switch {
+	case rest == "", strings.HasPrefix(rest, "/"), strings.HasPrefix(rest, "@"):
+		return true
+	case strings.HasPrefix(rest, ":"):
+		return strings.Contains(source, "/")
+	}
+	return false
+}
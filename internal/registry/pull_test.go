@@ -0,0 +1,94 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pull", func() {
+	Describe("ImageMirrors", func() {
+		var mirrors ImageMirrors
+
+		BeforeEach(func() {
+			mirrors = ImageMirrors{}
+		})
+
+		It("parses mirrors of the form source=mirror", func() {
+			Expect(mirrors.Set("quay.io/example/=mirror.example.com/example")).To(Succeed())
+			Expect(mirrors.Set("docker.io=localhost:5000")).To(Succeed())
+			Expect(mirrors).To(Equal(ImageMirrors{
+				{Source: "quay.io/example", Mirror: "mirror.example.com/example"},
+				{Source: "docker.io", Mirror: "localhost:5000"},
+			}))
+			Expect(mirrors.String()).To(Equal("quay.io/example=mirror.example.com/example,docker.io=localhost:5000"))
+		})
+
+		It("rejects mirrors of another form", func() {
+			Expect(mirrors.Set("quay.io/example")).NotTo(Succeed())
+			Expect(mirrors.Set("=mirror.example.com")).NotTo(Succeed())
+			Expect(mirrors.Set("quay.io=")).NotTo(Succeed())
+			Expect(mirrors).To(BeEmpty())
+		})
+
+		It("replaces the longest matching source of images", func() {
+			mirrors = ImageMirrors{
+				{Source: "quay.io", Mirror: "mirror.example.com"},
+				{Source: "quay.io/example", Mirror: "localhost:5000/example"},
+			}
+			Expect(mirrors.Apply("quay.io/example/memcached-operator-bundle:v0.0.1")).
+				To(Equal("localhost:5000/example/memcached-operator-bundle:v0.0.1"))
+			Expect(mirrors.Apply("quay.io/other/memcached-operator-bundle:v0.0.1")).
+				To(Equal("mirror.example.com/other/memcached-operator-bundle:v0.0.1"))
+			Expect(mirrors.Apply("quay.io/example@sha256:abcd")).To(Equal("localhost:5000/example@sha256:abcd"))
+		})
+
+		It("only replaces sources at repository boundaries", func() {
+			mirrors = ImageMirrors{{Source: "quay.io/example", Mirror: "localhost:5000/example"}}
+			Expect(mirrors.Apply("quay.io/examples/bundle:v0.0.1")).To(Equal("quay.io/examples/bundle:v0.0.1"))
+			Expect(mirrors.Apply("docker.io/example/bundle:v0.0.1")).To(Equal("docker.io/example/bundle:v0.0.1"))
+		})
+
+		It("does not match registries with a port by their host", func() {
+			mirrors = ImageMirrors{
+				{Source: "localhost", Mirror: "mirror.example.com"},
+				{Source: "localhost:5000/example/bundle", Mirror: "mirror.example.com/bundle"},
+			}
+			Expect(mirrors.Apply("localhost:5000/other/bundle:v0.0.1")).To(Equal("localhost:5000/other/bundle:v0.0.1"))
+			Expect(mirrors.Apply("localhost/other/bundle:v0.0.1")).To(Equal("mirror.example.com/other/bundle:v0.0.1"))
+			Expect(mirrors.Apply("localhost:5000/example/bundle:v0.0.1")).To(Equal("mirror.example.com/bundle:v0.0.1"))
+		})
+	})
+
+	Describe("PullOptions", func() {
+		It("returns no registry options by default", func() {
+			opts, err := PullOptions{}.RegistryOptions()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts).To(BeEmpty())
+		})
+
+		It("skips TLS", func() {
+			opts, err := PullOptions{SkipTLS: true}.RegistryOptions()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts).To(HaveLen(1))
+		})
+
+		It("fails to load a CA file that does not exist", func() {
+			_, err := PullOptions{CAFile: "/does/not/exist.crt"}.RegistryOptions()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,205 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ARTIFACTS_LABEL set to "true" collects the files a test writes to
+	// STORAGE_DEFAULT_MOUNT from the log of its pod, without a PVC or exec.
+	ARTIFACTS_LABEL = "artifacts"
+
+	// DefaultArtifactsSizeLimit is the default size limit of the files
+	// collected from a test, uncompressed. Container logs are rotated by the
+	// kubelet at 10MiB by default, which bounds the compressed size.
+	DefaultArtifactsSizeLimit = 5 * 1024 * 1024
+
+	// The delimiters of the base64 encoded tar.gz stream of test files, written
+	// to the test container log after the test result.
+	artifactsBeginDelimiter = "--- scorecard artifacts begin ---"
+	artifactsEndDelimiter   = "--- scorecard artifacts end ---"
+	// artifactsTooLargePrefix precedes the size of test files over the size
+	// limit, written to the log in their place.
+	artifactsTooLargePrefix = "--- scorecard artifacts exceed the size limit: "
+
+	artifactsVolume = "scorecard-artifacts"
+	toolsVolume     = "scorecard-tools"
+	toolsMount      = "/scorecard-tools"
+)
+
+// artifactsScript returns a script that runs the test command given as
+// arguments, then writes the files in STORAGE_DEFAULT_MOUNT to stdout between
+// the artifacts delimiters, with the busybox binary copied by an init
+// container, so that test images do not need a shell or tar. Files over
+// limit bytes are not written, so that they do not fill the log, and the size
+// is written after artifactsTooLargePrefix instead. It exits with the status
+// of the test.
+func artifactsScript(limit int64) string {
+	return fmt.Sprintf(`"$@"
+rc=$?
+echo
+size=$(%[3]s/busybox du -sk %[4]s | %[3]s/busybox cut -f1)
+if [ "$size" -gt %[5]d ]; then
+  echo "%[6]s${size}KiB"
+  exit $rc
+fi
+echo "%[1]s"
+%[3]s/busybox tar czf - -C %[4]s . | %[3]s/busybox base64
+echo "%[2]s"
+exit $rc`, artifactsBeginDelimiter, artifactsEndDelimiter, toolsMount, STORAGE_DEFAULT_MOUNT,
+		(limit+1023)/1024, artifactsTooLargePrefix)
+}
+
+// addArtifactsToPod wraps the test command of podDef to write the files of
+// the test, up to limit bytes, to its log, using busybox from the untar image
+// of opts. The test must have an entrypoint, which is wrapped in place of the
+// command of its image. Tests that also have the storage label write their
+// files to the PVC mounted in STORAGE_DEFAULT_MOUNT, which are collected too.
+func addArtifactsToPod(podDef *v1.Pod, opts PodOptions, limit int64) error {
+	test := &podDef.Spec.Containers[0]
+	if len(test.Command) == 0 {
+		return fmt.Errorf("the %s label requires the test to have an entrypoint", ARTIFACTS_LABEL)
+	}
+	test.Command = append([]string{toolsMount + "/busybox", "sh", "-c", artifactsScript(limit), "scorecard-test"},
+		test.Command...)
+	if !hasVolumeMount(*test, STORAGE_DEFAULT_MOUNT) {
+		test.VolumeMounts = append(test.VolumeMounts, v1.VolumeMount{MountPath: STORAGE_DEFAULT_MOUNT, Name: artifactsVolume})
+		podDef.Spec.Volumes = append(podDef.Spec.Volumes,
+			v1.Volume{Name: artifactsVolume, VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}})
+	}
+	test.VolumeMounts = append(test.VolumeMounts, v1.VolumeMount{MountPath: toolsMount, Name: toolsVolume, ReadOnly: true})
+
+	podDef.Spec.InitContainers = append(podDef.Spec.InitContainers, v1.Container{
+		Name:            toolsVolume,
+		Image:           opts.untarImage(),
+		ImagePullPolicy: v1.PullIfNotPresent,
+		Args:            []string{"cp", "/bin/busybox", toolsMount + "/busybox"},
+		VolumeMounts:    []v1.VolumeMount{{MountPath: toolsMount, Name: toolsVolume}},
+		Resources:       test.Resources,
+		SecurityContext: opts.initSecurityContext(),
+	})
+	podDef.Spec.Volumes = append(podDef.Spec.Volumes,
+		v1.Volume{Name: toolsVolume, VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}})
+	return nil
+}
+
+func hasVolumeMount(c v1.Container, mountPath string) bool {
+	for _, m := range c.VolumeMounts {
+		if m.MountPath == mountPath {
+			return true
+		}
+	}
+	return false
+}
+
+// splitArtifacts splits a test pod log into the test result and the test files,
+// which are nil if the log has none.
+func splitArtifacts(log []byte) (result, artifacts []byte, err error) {
+	if i := bytes.Index(log, []byte(artifactsTooLargePrefix)); i >= 0 {
+		size := log[i+len(artifactsTooLargePrefix):]
+		if end := bytes.IndexByte(size, '\n'); end >= 0 {
+			size = size[:end]
+		}
+		return log[:i], nil, fmt.Errorf("test output of %s exceeds the size limit", bytes.TrimSpace(size))
+	}
+	begin := bytes.Index(log, []byte(artifactsBeginDelimiter))
+	if begin < 0 {
+		return log, nil, nil
+	}
+	result = log[:begin]
+	encoded := log[begin+len(artifactsBeginDelimiter):]
+	end := bytes.Index(encoded, []byte(artifactsEndDelimiter))
+	if end < 0 {
+		return result, nil, fmt.Errorf("test output is truncated, the end delimiter is missing")
+	}
+	encoded = bytes.Join(bytes.Fields(encoded[:end]), nil)
+	artifacts = make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+	n, err := base64.StdEncoding.Decode(artifacts, encoded)
+	if err != nil {
+		return result, nil, fmt.Errorf("error decoding test output: %w", err)
+	}
+	return result, artifacts[:n], nil
+}
+
+// extractArtifacts extracts the tar.gz stream of test files into dest, and
+// fails once more than limit bytes were extracted.
+func extractArtifacts(artifacts []byte, dest string, limit int64) error {
+	gz, err := gzip.NewReader(bytes.NewReader(artifacts))
+	if err != nil {
+		return fmt.Errorf("error reading test output: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading test output: %w", err)
+		}
+
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("invalid test output file path %q", hdr.Name)
+		}
+		target := filepath.Join(dest, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			total += hdr.Size
+			if total > limit {
+				return fmt.Errorf("test output exceeds the size limit of %d bytes", limit)
+			}
+			if err := writeArtifact(target, tr, hdr.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		}
+		// Links and special files are skipped, since they could point outside dest.
+	}
+}
+
+func writeArtifact(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+// artifactsLog returns a test log holding result and files as tar.gz, base64
+// encoded in 76 character lines like busybox does.
+func artifactsLog(result string, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		ExpectWithOffset(1, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)),
+			Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := tw.Write([]byte(content))
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	}
+	ExpectWithOffset(1, tw.Close()).To(Succeed())
+	ExpectWithOffset(1, gz.Close()).To(Succeed())
+
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	lines := []string{result, "", artifactsBeginDelimiter}
+	for len(encoded) > 76 {
+		lines = append(lines, encoded[:76])
+		encoded = encoded[76:]
+	}
+	lines = append(lines, encoded, artifactsEndDelimiter, "")
+	return []byte(strings.Join(lines, "\n"))
+}
+
+var _ = Describe("Test artifacts", func() {
+	var dest string
+
+	BeforeEach(func() {
+		var err error
+		dest, err = ioutil.TempDir("", "scorecard-artifacts")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dest)).To(Succeed())
+	})
+
+	It("splits the test result from the test files", func() {
+		log := artifactsLog(`{"results": []}`, map[string]string{
+			"./report.txt": "ok", "./logs/operator.log": strings.Repeat("line\n", 100),
+		})
+		result, artifacts, err := splitArtifacts(log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.TrimSpace(string(result))).To(Equal(`{"results": []}`))
+
+		Expect(extractArtifacts(artifacts, dest, 1024)).To(Succeed())
+		b, err := ioutil.ReadFile(filepath.Join(dest, "logs", "operator.log"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b).To(HaveLen(500))
+	})
+	It("leaves logs without test files unchanged", func() {
+		result, artifacts, err := splitArtifacts([]byte("{}"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal([]byte("{}")))
+		Expect(artifacts).To(BeNil())
+	})
+	It("reports truncated logs", func() {
+		log := artifactsLog("{}", map[string]string{"a": "b"})
+		_, _, err := splitArtifacts(log[:len(log)-len(artifactsEndDelimiter)-2])
+		Expect(err).To(MatchError(ContainSubstring("end delimiter is missing")))
+	})
+	It("reports test files over the size limit of the test pod", func() {
+		result, artifacts, err := splitArtifacts([]byte("{}\n\n" + artifactsTooLargePrefix + "6144KiB\n"))
+		Expect(err).To(MatchError("test output of 6144KiB exceeds the size limit"))
+		Expect(strings.TrimSpace(string(result))).To(Equal("{}"))
+		Expect(artifacts).To(BeNil())
+	})
+	It("enforces the size limit", func() {
+		_, artifacts, err := splitArtifacts(artifactsLog("{}", map[string]string{"big": strings.Repeat("x", 2048)}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(extractArtifacts(artifacts, dest, 1024)).To(MatchError(ContainSubstring("size limit of 1024 bytes")))
+	})
+	It("rejects paths outside of the output directory", func() {
+		_, artifacts, err := splitArtifacts(artifactsLog("{}", map[string]string{"../escape": "x"}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(extractArtifacts(artifacts, dest, 1024)).To(MatchError(ContainSubstring("invalid test output file path")))
+	})
+
+	Describe("addArtifactsToPod", func() {
+		It("wraps the test command to write its files to the log", func() {
+			test := v1alpha3.TestConfiguration{Image: "img", Entrypoint: []string{"run-test", "a"}}
+			pod := getPodDefinition("cm", test, PodTestRunner{})
+			Expect(addArtifactsToPod(pod, PodOptions{UntarImage: "busybox"}, 2048)).To(Succeed())
+
+			command := pod.Spec.Containers[0].Command
+			Expect(command[:3]).To(Equal([]string{"/scorecard-tools/busybox", "sh", "-c"}))
+			Expect(command[3]).To(ContainSubstring(`if [ "$size" -gt 2 ]; then`))
+			Expect(command[len(command)-2:]).To(Equal([]string{"run-test", "a"}))
+			Expect(pod.Spec.InitContainers).To(HaveLen(2))
+			Expect(pod.Spec.InitContainers[1].Image).To(Equal("busybox"))
+			Expect(pod.Spec.Volumes).To(HaveLen(4))
+		})
+		It("collects the files of the storage PVC without mounting over it", func() {
+			test := v1alpha3.TestConfiguration{Image: "img", Entrypoint: []string{"run-test"}}
+			pod := getPodDefinition("cm", test, PodTestRunner{})
+			addStorageToPod(pod, "pvc")
+			Expect(addArtifactsToPod(pod, PodOptions{}, 2048)).To(Succeed())
+
+			var mounts []string
+			for _, m := range pod.Spec.Containers[0].VolumeMounts {
+				mounts = append(mounts, m.MountPath)
+			}
+			Expect(mounts).To(ConsistOf(PodBundleRoot, STORAGE_DEFAULT_MOUNT, toolsMount))
+			Expect(pod.Spec.Volumes).To(HaveLen(4))
+		})
+		It("requires an entrypoint", func() {
+			pod := getPodDefinition("cm", v1alpha3.TestConfiguration{Image: "img"}, PodTestRunner{})
+			Expect(addArtifactsToPod(pod, PodOptions{}, 2048)).To(MatchError(ContainSubstring("requires the test to have an entrypoint")))
+		})
+	})
+})
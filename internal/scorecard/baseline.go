@@ -0,0 +1,213 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// LoadBaseline reads a TestList written by "scorecard --output json".
+func LoadBaseline(path string) (v1alpha3.TestList, error) {
+	list := v1alpha3.TestList{}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return list, err
+	}
+	if err := json.Unmarshal(b, &list); err != nil {
+		return list, fmt.Errorf("error parsing baseline %s: %w", path, err)
+	}
+	return list, nil
+}
+
+// ResultChange is a change of a test result between a baseline and a run.
+type ResultChange struct {
+	// Test is the "test" label of the test, or its image and entrypoint.
+	Test string `json:"test"`
+	// Result is the name of the result, which is empty for tests with a single result.
+	Result        string         `json:"result,omitempty"`
+	BaselineState v1alpha3.State `json:"baselineState,omitempty"`
+	State         v1alpha3.State `json:"state,omitempty"`
+	// Skipped and TimedOut are true if the test of the result was skipped or timed out in the run.
+	Skipped  bool `json:"skipped,omitempty"`
+	TimedOut bool `json:"timedOut,omitempty"`
+	// AddedSuggestions and RemovedSuggestions are the suggestions of the result
+	// that are not in the baseline, and those of the baseline that are gone.
+	AddedSuggestions   []string `json:"addedSuggestions,omitempty"`
+	RemovedSuggestions []string `json:"removedSuggestions,omitempty"`
+}
+
+// Comparison is the comparison of a run with a baseline.
+type Comparison struct {
+	// Regressions are the results that do not pass and passed in the baseline,
+	// or are not in it.
+	Regressions []ResultChange `json:"regressions,omitempty"`
+	// Fixed are the results that pass and did not pass in the baseline.
+	Fixed []ResultChange `json:"fixed,omitempty"`
+	// SuggestionChanges are the results whose suggestions changed.
+	SuggestionChanges []ResultChange `json:"suggestionChanges,omitempty"`
+	// Missing are the results of the baseline that are not in the run.
+	Missing []ResultChange `json:"missing,omitempty"`
+}
+
+// HasRegressions returns true if any result regressed.
+func (c Comparison) HasRegressions() bool {
+	return len(c.Regressions) > 0
+}
+
+type resultKey struct {
+	test, result string
+}
+
+// Compare compares the results of current with those of baseline. Results are
+// matched by the name of their test and their own name. timings are those of
+// current, and may be nil.
+func Compare(baseline, current v1alpha3.TestList, timings *Timings) Comparison {
+	baselineResults := indexResults(baseline)
+	currentResults := indexResults(current)
+	currentTimings := map[string]TestTiming{}
+	for i, test := range current.Items {
+		currentTimings[testName(test.Spec)] = timings.at(i)
+	}
+
+	c := Comparison{}
+	for _, key := range sortedKeys(currentResults) {
+		cur := currentResults[key]
+		timing := currentTimings[key.test]
+		change := ResultChange{Test: key.test, Result: key.result, State: cur.State,
+			Skipped: timing.Skipped, TimedOut: timing.TimedOut}
+		base, inBaseline := baselineResults[key]
+		if inBaseline {
+			change.BaselineState = base.State
+		}
+		switch {
+		case cur.State != v1alpha3.PassState && (!inBaseline || base.State == v1alpha3.PassState):
+			c.Regressions = append(c.Regressions, change)
+		case cur.State == v1alpha3.PassState && inBaseline && base.State != v1alpha3.PassState:
+			c.Fixed = append(c.Fixed, change)
+		}
+		if inBaseline {
+			curSuggestions, baseSuggestions := sets.NewString(cur.Suggestions...), sets.NewString(base.Suggestions...)
+			change.AddedSuggestions = curSuggestions.Difference(baseSuggestions).List()
+			change.RemovedSuggestions = baseSuggestions.Difference(curSuggestions).List()
+			if len(change.AddedSuggestions) != 0 || len(change.RemovedSuggestions) != 0 {
+				c.SuggestionChanges = append(c.SuggestionChanges, change)
+			}
+		}
+	}
+	for _, key := range sortedKeys(baselineResults) {
+		if _, ok := currentResults[key]; !ok {
+			c.Missing = append(c.Missing, ResultChange{Test: key.test, Result: key.result,
+				BaselineState: baselineResults[key].State})
+		}
+	}
+	return c
+}
+
+func indexResults(list v1alpha3.TestList) map[resultKey]v1alpha3.TestResult {
+	results := map[resultKey]v1alpha3.TestResult{}
+	for _, test := range list.Items {
+		name := testName(test.Spec)
+		if len(test.Status.Results) == 0 {
+			results[resultKey{test: name}] = v1alpha3.TestResult{State: v1alpha3.ErrorState}
+			continue
+		}
+		for _, r := range test.Status.Results {
+			key := resultKey{test: name}
+			if len(test.Status.Results) > 1 {
+				key.result = r.Name
+			}
+			results[key] = r
+		}
+	}
+	return results
+}
+
+func sortedKeys(results map[resultKey]v1alpha3.TestResult) []resultKey {
+	keys := make([]resultKey, 0, len(results))
+	for k := range results {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].test != keys[j].test {
+			return keys[i].test < keys[j].test
+		}
+		return keys[i].result < keys[j].result
+	})
+	return keys
+}
+
+// MarshalText returns a human-readable summary of the comparison.
+func (c Comparison) MarshalText() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("%s\n", strings.Repeat("-", 80)))
+	sb.WriteString(fmt.Sprintf("Baseline comparison: %d regressed, %d fixed, %d with changed suggestions, %d missing\n",
+		len(c.Regressions), len(c.Fixed), len(c.SuggestionChanges), len(c.Missing)))
+	writeChanges := func(title string, changes []ResultChange, details func(ResultChange)) {
+		if len(changes) == 0 {
+			return
+		}
+		sb.WriteString(title + ":\n")
+		for _, change := range changes {
+			sb.WriteString(fmt.Sprintf("\t%s\n", change.name()))
+			if details != nil {
+				details(change)
+			}
+		}
+	}
+	writeChanges("Regressions", c.Regressions, func(change ResultChange) {
+		baselineState := string(change.BaselineState)
+		if baselineState == "" {
+			baselineState = "not in baseline"
+		}
+		sb.WriteString(fmt.Sprintf("\t\t%s -> %s\n", baselineState, change.state()))
+	})
+	writeChanges("Fixed", c.Fixed, func(change ResultChange) {
+		sb.WriteString(fmt.Sprintf("\t\t%s -> %s\n", change.BaselineState, change.State))
+	})
+	writeChanges("Changed suggestions", c.SuggestionChanges, func(change ResultChange) {
+		for _, s := range change.AddedSuggestions {
+			sb.WriteString(fmt.Sprintf("\t\t+ %s\n", s))
+		}
+		for _, s := range change.RemovedSuggestions {
+			sb.WriteString(fmt.Sprintf("\t\t- %s\n", s))
+		}
+	})
+	writeChanges("Missing", c.Missing, nil)
+	return sb.String()
+}
+
+// state returns the state of the result in the run, along with whether its test
+// was skipped or timed out.
+func (c ResultChange) state() string {
+	outcome := TestTiming{Skipped: c.Skipped, TimedOut: c.TimedOut}.outcome()
+	if outcome == "" {
+		return string(c.State)
+	}
+	return fmt.Sprintf("%s (%s)", c.State, outcome)
+}
+
+func (c ResultChange) name() string {
+	if c.Result == "" {
+		return c.Test
+	}
+	return fmt.Sprintf("%s: %s", c.Test, c.Result)
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+func baselineTest(name string, results ...v1alpha3.TestResult) v1alpha3.Test {
+	test := v1alpha3.NewTest()
+	test.Spec = v1alpha3.TestConfiguration{Image: "img", Labels: map[string]string{"test": name}}
+	test.Status.Results = results
+	return test
+}
+
+func testList(tests ...v1alpha3.Test) v1alpha3.TestList {
+	list := v1alpha3.NewTestList()
+	list.Items = tests
+	return list
+}
+
+var _ = Describe("Comparing results with a baseline", func() {
+	pass := v1alpha3.TestResult{State: v1alpha3.PassState}
+	fail := v1alpha3.TestResult{State: v1alpha3.FailState}
+
+	It("reports regressions, fixed tests and missing tests", func() {
+		baseline := testList(baselineTest("a", pass), baselineTest("b", fail), baselineTest("c", fail),
+			baselineTest("gone", pass))
+		current := testList(baselineTest("a", fail), baselineTest("b", pass), baselineTest("c", fail),
+			baselineTest("new", fail))
+
+		c := Compare(baseline, current, nil)
+		Expect(c.Regressions).To(Equal([]ResultChange{
+			{Test: "a", BaselineState: v1alpha3.PassState, State: v1alpha3.FailState},
+			{Test: "new", State: v1alpha3.FailState},
+		}))
+		Expect(c.Fixed).To(Equal([]ResultChange{{Test: "b", BaselineState: v1alpha3.FailState, State: v1alpha3.PassState}}))
+		Expect(c.Missing).To(Equal([]ResultChange{{Test: "gone", BaselineState: v1alpha3.PassState}}))
+		Expect(c.HasRegressions()).To(BeTrue())
+	})
+	It("tolerates tests that already failed in the baseline", func() {
+		c := Compare(testList(baselineTest("a", fail)), testList(baselineTest("a", fail)), nil)
+		Expect(c.HasRegressions()).To(BeFalse())
+	})
+	It("matches results of tests with several results by name", func() {
+		baseline := testList(baselineTest("a",
+			v1alpha3.TestResult{Name: "x", State: v1alpha3.PassState},
+			v1alpha3.TestResult{Name: "y", State: v1alpha3.FailState}))
+		current := testList(baselineTest("a",
+			v1alpha3.TestResult{Name: "y", State: v1alpha3.PassState},
+			v1alpha3.TestResult{Name: "x", State: v1alpha3.PassState}))
+		c := Compare(baseline, current, nil)
+		Expect(c.Regressions).To(BeEmpty())
+		Expect(c.Fixed).To(Equal([]ResultChange{
+			{Test: "a", Result: "y", BaselineState: v1alpha3.FailState, State: v1alpha3.PassState},
+		}))
+	})
+	It("reports skipped and timed out regressions", func() {
+		baseline := testList(baselineTest("a", pass), baselineTest("b", pass))
+		errored := v1alpha3.TestResult{State: v1alpha3.ErrorState}
+		current := testList(baselineTest("a", errored), baselineTest("b", errored))
+		c := Compare(baseline, current, &Timings{Items: []TestTiming{{Skipped: true}, {TimedOut: true}}})
+		Expect(c.Regressions).To(Equal([]ResultChange{
+			{Test: "a", BaselineState: v1alpha3.PassState, State: v1alpha3.ErrorState, Skipped: true},
+			{Test: "b", BaselineState: v1alpha3.PassState, State: v1alpha3.ErrorState, TimedOut: true},
+		}))
+		Expect(c.MarshalText()).To(ContainSubstring("\ta\n\t\tpass -> error (skipped)\n"))
+		Expect(c.MarshalText()).To(ContainSubstring("\tb\n\t\tpass -> error (timed out)\n"))
+	})
+	It("reports changed suggestions", func() {
+		baseline := testList(baselineTest("a", v1alpha3.TestResult{State: v1alpha3.PassState,
+			Suggestions: []string{"add descriptors", "add validation"}}))
+		current := testList(baselineTest("a", v1alpha3.TestResult{State: v1alpha3.PassState,
+			Suggestions: []string{"add validation", "add resources"}}))
+		c := Compare(baseline, current, nil)
+		Expect(c.SuggestionChanges).To(HaveLen(1))
+		Expect(c.SuggestionChanges[0].AddedSuggestions).To(Equal([]string{"add resources"}))
+		Expect(c.SuggestionChanges[0].RemovedSuggestions).To(Equal([]string{"add descriptors"}))
+		Expect(c.MarshalText()).To(ContainSubstring("\t\t+ add resources\n\t\t- add descriptors\n"))
+	})
+	It("loads a baseline written as JSON", func() {
+		dir, err := ioutil.TempDir("", "scorecard-baseline-")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		list := testList(baselineTest("a", pass))
+		b, err := json.Marshal(list)
+		Expect(err).NotTo(HaveOccurred())
+		path := filepath.Join(dir, "baseline.json")
+		Expect(ioutil.WriteFile(path, b, 0644)).To(Succeed())
+
+		loaded, err := LoadBaseline(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(Compare(loaded, list, nil)).To(Equal(Comparison{}))
+	})
+})
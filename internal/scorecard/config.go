@@ -15,9 +15,12 @@
 package scorecard
 
 import (
+	"fmt"
 	"io/ioutil"
+	"time"
 
 	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
 
@@ -26,22 +29,177 @@ const (
 	ConfigFileName = "config.yaml"
 	// DefaultConfigDir is the default scorecard path within a bundle.
 	DefaultConfigDir = "tests/scorecard/"
+	// DefaultRetryBackoff is the delay before the first retry of a test, if
+	// none is configured. The delay doubles with each retry.
+	DefaultRetryBackoff = 5 * time.Second
 )
 
+// TestOptions are the scheduling options of a test. They are set in the
+// scorecard config next to the fields of the test configuration, and apply
+// to the test with the same "test" label, as timeout, retries, retryBackoff
+// and dependsOn.
+type TestOptions struct {
+	// Timeout is the time given to each attempt of the test. No timeout is set if zero.
+	Timeout time.Duration
+	// Retries is the number of times the test is run again if it does not pass.
+	Retries int
+	// RetryBackoff is the delay before the first retry, which doubles with each retry.
+	RetryBackoff time.Duration
+	// DependsOn are the names of tests that must pass before this test is run.
+	// The test is skipped if any of them does not pass.
+	DependsOn []string
+}
+
+// Config is the scorecard config, along with the options set in it next to
+// the fields of v1alpha3.
+type Config struct {
+	v1alpha3.Configuration
+	// TestOptions are the scheduling options of tests, keyed by "test" label.
+	TestOptions map[string]TestOptions
+	// PodOptions apply to the pods of all tests, and TestPodOptions to those of
+	// single tests, keyed by "test" label.
+	PodOptions     PodOptions
+	TestPodOptions map[string]PodOptions
+}
+
+// configOptions holds the fields of the scorecard config that are not part of v1alpha3.
+type configOptions struct {
+	Pod    PodOptions `json:"pod,omitempty"`
+	Stages []struct {
+		Parallel bool `json:"parallel,omitempty"`
+		Tests    []struct {
+			Labels       map[string]string `json:"labels,omitempty"`
+			Timeout      *metav1.Duration  `json:"timeout,omitempty"`
+			Retries      int               `json:"retries,omitempty"`
+			RetryBackoff *metav1.Duration  `json:"retryBackoff,omitempty"`
+			DependsOn    []string          `json:"dependsOn,omitempty"`
+			Pod          *PodOptions       `json:"pod,omitempty"`
+		} `json:"tests"`
+	} `json:"stages"`
+}
+
 // LoadConfig will find and return the scorecard config, the config file
 // is found from a bundle location (TODO bundle image)
 // scorecard config.yaml is expected to be in the bundle at the following
 // location:  tests/scorecard/config.yaml
 // the user can override this location using the --config CLI flag
 // TODO: version this.
-func LoadConfig(configFilePath string) (v1alpha3.Configuration, error) {
-	c := v1alpha3.Configuration{}
+func LoadConfig(configFilePath string) (Config, error) {
+	c := Config{}
 
 	yamlFile, err := ioutil.ReadFile(configFilePath)
 	if err != nil {
 		return c, err
 	}
 
-	err = yaml.Unmarshal(yamlFile, &c)
-	return c, err
+	if err := yaml.Unmarshal(yamlFile, &c.Configuration); err != nil {
+		return c, err
+	}
+	opts := configOptions{}
+	if err := yaml.Unmarshal(yamlFile, &opts); err != nil {
+		return c, err
+	}
+	if c.TestOptions, err = testOptions(opts); err != nil {
+		return c, fmt.Errorf("invalid test options: %w", err)
+	}
+	if c.PodOptions, c.TestPodOptions, err = podOptions(opts); err != nil {
+		return c, fmt.Errorf("invalid pod options: %w", err)
+	}
+	return c, nil
+}
+
+// testOptions returns the scheduling options of the tests in cfg, keyed by the
+// "test" label of each test. Tests depending on other tests must come after
+// them, either in a later stage or later in a sequential stage.
+func testOptions(cfg configOptions) (map[string]TestOptions, error) {
+	type position struct{ stage, index int }
+	positions := map[string]position{}
+	options := map[string]TestOptions{}
+	for i, stage := range cfg.Stages {
+		for j, test := range stage.Tests {
+			name := test.Labels["test"]
+			opts := TestOptions{
+				Retries:      test.Retries,
+				RetryBackoff: DefaultRetryBackoff,
+				DependsOn:    test.DependsOn,
+			}
+			if test.Timeout != nil {
+				opts.Timeout = test.Timeout.Duration
+			}
+			if test.RetryBackoff != nil {
+				opts.RetryBackoff = test.RetryBackoff.Duration
+			}
+			hasOptions := test.Timeout != nil || test.Retries != 0 || test.RetryBackoff != nil || len(test.DependsOn) != 0
+			if name == "" {
+				if hasOptions {
+					return nil, fmt.Errorf("stage %d test %d: timeout, retries, retryBackoff and dependsOn "+
+						"require a \"test\" label", i, j)
+				}
+				continue
+			}
+			if _, dup := positions[name]; dup {
+				return nil, fmt.Errorf("duplicate test label %q", name)
+			}
+			if opts.Timeout < 0 || opts.Retries < 0 || opts.RetryBackoff < 0 {
+				return nil, fmt.Errorf("test %q: timeout, retries and retryBackoff must not be negative", name)
+			}
+			positions[name] = position{i, j}
+			options[name] = opts
+		}
+	}
+
+	// Validate dependencies once all tests are known.
+	for name, opts := range options {
+		pos := positions[name]
+		for _, dep := range opts.DependsOn {
+			depPos, ok := positions[dep]
+			switch {
+			case !ok:
+				return nil, fmt.Errorf("test %q depends on unknown test %q", name, dep)
+			case dep == name:
+				return nil, fmt.Errorf("test %q depends on itself", name)
+			case depPos.stage > pos.stage:
+				return nil, fmt.Errorf("test %q depends on test %q of a later stage", name, dep)
+			case depPos.stage == pos.stage && !cfg.Stages[pos.stage].Parallel && depPos.index > pos.index:
+				return nil, fmt.Errorf("test %q depends on test %q, which runs after it", name, dep)
+			}
+		}
+	}
+	if err := checkDependencyCycles(options); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// checkDependencyCycles returns an error if tests depend on each other, which
+// can only happen between tests of the same parallel stage.
+func checkDependencyCycles(options map[string]TestOptions) error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle between tests: %v", append(path, name))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range options[name].DependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for name := range options {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -23,18 +23,20 @@ import (
 )
 
 // getTestResult fetches the test pod log and converts it into
-// Test format
-func (r PodTestRunner) getTestStatus(ctx context.Context, p *v1.Pod) (output *v1alpha3.TestStatus) {
+// Test format, along with the test files written to the log, if any
+func (r PodTestRunner) getTestStatus(ctx context.Context, p *v1.Pod) (output *v1alpha3.TestStatus,
+	artifacts []byte, artifactsErr error) {
 	logBytes, err := getPodLog(ctx, r.Client, p)
 	if err != nil {
-		return convertErrorToStatus(err, string(logBytes))
+		return convertErrorToStatus(err, string(logBytes)), nil, nil
 	}
+	logBytes, artifacts, artifactsErr = splitArtifacts(logBytes)
 	// marshal pod log into TestResult
 	err = json.Unmarshal(logBytes, &output)
 	if err != nil {
-		return convertErrorToStatus(err, string(logBytes))
+		return convertErrorToStatus(err, string(logBytes)), artifacts, artifactsErr
 	}
-	return output
+	return output, artifacts, artifactsErr
 }
 
 // List lists the scorecard tests as configured that would be
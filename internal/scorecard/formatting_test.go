@@ -38,10 +38,11 @@ func TestList(t *testing.T) {
 			var err error
 			configPath := filepath.Join(c.bundlePathValue, "tests", "scorecard", "config.yaml")
 
-			o.Config, err = LoadConfig(configPath)
+			config, err := LoadConfig(configPath)
 			if err != nil {
 				t.Fatalf("Unexpected error %v", err)
 			}
+			o.Config = config.Configuration
 			o.Selector, err = labels.Parse(c.selector)
 			if err != nil {
 				t.Fatalf("Unexpected error %v", err)
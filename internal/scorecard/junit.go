@@ -0,0 +1,161 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+
+	"github.com/operator-framework/operator-sdk/internal/junit"
+)
+
+// JUnitReport converts test results to a JUnit report, in which each test is a
+// testsuite and each of its results a testcase. timings may be nil.
+func JUnitReport(list v1alpha3.TestList, timings *Timings) *junit.TestSuites {
+	report := &junit.TestSuites{Name: "scorecard"}
+	for i, test := range list.Items {
+		report.AddSuite(junitSuite(test, timings.at(i)))
+	}
+	return report
+}
+
+func junitSuite(test v1alpha3.Test, timing TestTiming) junit.TestSuite {
+	name := testName(test.Spec)
+	suite := junit.TestSuite{
+		Name:       name,
+		Time:       junit.Duration(timing.Duration),
+		Properties: junitProperties(test.Spec),
+	}
+	if !timing.Start.IsZero() {
+		suite.Timestamp = timing.Start.UTC().Format(time.RFC3339)
+	}
+
+	classname := "scorecard"
+	if s := test.Spec.Labels["suite"]; s != "" {
+		classname += "." + s
+	}
+	if len(test.Status.Results) == 0 {
+		suite.TestCases = []junit.TestCase{{
+			Name:      name,
+			Classname: classname,
+			Time:      suite.Time,
+			Error:     &junit.Result{Message: "test produced no results", Type: "error"},
+		}}
+		return suite
+	}
+
+	for _, r := range test.Status.Results {
+		tc := junit.TestCase{
+			Name:      r.Name,
+			Classname: classname,
+			SystemOut: r.Log,
+		}
+		if tc.Name == "" {
+			tc.Name = name
+		}
+		// A test reports a single duration for all of its results.
+		if len(test.Status.Results) == 1 {
+			tc.Time = suite.Time
+		}
+		switch {
+		case timing.Skipped:
+			tc.Skipped = &junit.Result{Message: junitMessage(r, "test skipped")}
+		case timing.TimedOut:
+			tc.Error = &junit.Result{
+				Message:  junitMessage(r, "test timed out"),
+				Type:     "timeout",
+				Contents: junitDetails(r.Errors, r.Suggestions),
+			}
+		case r.State == v1alpha3.PassState:
+			if len(r.Suggestions) > 0 {
+				tc.SystemOut = strings.TrimLeft(tc.SystemOut+"\n"+junitDetails(nil, r.Suggestions), "\n")
+			}
+		case r.State == v1alpha3.FailState:
+			tc.Failure = &junit.Result{
+				Message:  junitMessage(r, "test failed"),
+				Type:     string(r.State),
+				Contents: junitDetails(r.Errors, r.Suggestions),
+			}
+		case r.State == v1alpha3.ErrorState:
+			tc.Error = &junit.Result{
+				Message:  junitMessage(r, "test errored"),
+				Type:     string(r.State),
+				Contents: junitDetails(r.Errors, r.Suggestions),
+			}
+		default:
+			tc.Error = &junit.Result{
+				Message:  fmt.Sprintf("unknown test state %q", r.State),
+				Type:     "error",
+				Contents: junitDetails(r.Errors, r.Suggestions),
+			}
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	return suite
+}
+
+// testName returns the name of a test from its "test" label, or from its image
+// and entrypoint if it has none.
+func testName(spec v1alpha3.TestConfiguration) string {
+	if name := spec.Labels["test"]; name != "" {
+		return name
+	}
+	return strings.TrimSpace(spec.Image + " " + strings.Join(spec.Entrypoint, " "))
+}
+
+func junitProperties(spec v1alpha3.TestConfiguration) *junit.Properties {
+	props := &junit.Properties{}
+	props.Items = append(props.Items, junit.Property{Name: "image", Value: spec.Image})
+	if len(spec.Entrypoint) > 0 {
+		props.Items = append(props.Items, junit.Property{Name: "entrypoint", Value: strings.Join(spec.Entrypoint, " ")})
+	}
+	keys := make([]string, 0, len(spec.Labels))
+	for k := range spec.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		props.Items = append(props.Items, junit.Property{Name: "label." + k, Value: spec.Labels[k]})
+	}
+	return props
+}
+
+func junitMessage(r v1alpha3.TestResult, defaultMessage string) string {
+	if len(r.Errors) > 0 {
+		return r.Errors[0]
+	}
+	return defaultMessage
+}
+
+func junitDetails(errs, suggestions []string) string {
+	sb := strings.Builder{}
+	if len(errs) > 0 {
+		sb.WriteString("Errors:\n")
+		for _, e := range errs {
+			sb.WriteString("\t" + e + "\n")
+		}
+	}
+	if len(suggestions) > 0 {
+		sb.WriteString("Suggestions:\n")
+		for _, s := range suggestions {
+			sb.WriteString("\t" + s + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+
+	"github.com/operator-framework/operator-sdk/internal/junit"
+)
+
+var _ = Describe("JUnit reports", func() {
+	newTest := func(labels map[string]string, results ...v1alpha3.TestResult) v1alpha3.Test {
+		t := v1alpha3.NewTest()
+		t.Spec = v1alpha3.TestConfiguration{Image: "quay.io/example/test:v1", Entrypoint: []string{"run", "it"},
+			Labels: labels}
+		t.Status.Results = results
+		return t
+	}
+
+	It("maps tests to suites and results to cases", func() {
+		list := v1alpha3.NewTestList()
+		list.Items = []v1alpha3.Test{
+			newTest(map[string]string{"suite": "basic", "test": "basic-check-spec-test"},
+				v1alpha3.TestResult{Name: "basic-check-spec", State: v1alpha3.PassState, Log: "log line",
+					Suggestions: []string{"add a spec"}}),
+			newTest(map[string]string{"suite": "olm", "test": "olm-bundle-validation-test"},
+				v1alpha3.TestResult{Name: "olm-bundle-validation", State: v1alpha3.FailState,
+					Errors: []string{"invalid bundle"}, Suggestions: []string{"fix it"}}),
+			newTest(nil, v1alpha3.TestResult{State: v1alpha3.ErrorState}),
+			newTest(nil),
+		}
+		start := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+		timings := &Timings{Items: []TestTiming{
+			{Start: start, Duration: 2 * time.Second},
+			{Start: start, Duration: time.Second},
+		}}
+
+		report := JUnitReport(list, timings)
+		Expect(report.Tests).To(Equal(4))
+		Expect(report.Failures).To(Equal(1))
+		Expect(report.Errors).To(Equal(2))
+		Expect(report.Time).To(Equal(junit.Duration(3 * time.Second)))
+		Expect(report.Suites).To(HaveLen(4))
+
+		pass := report.Suites[0]
+		Expect(pass.Name).To(Equal("basic-check-spec-test"))
+		Expect(pass.Timestamp).To(Equal("2021-03-01T12:00:00Z"))
+		Expect(pass.Properties.Items).To(ContainElement(junit.Property{Name: "label.suite", Value: "basic"}))
+		Expect(pass.TestCases).To(HaveLen(1))
+		Expect(pass.TestCases[0].Name).To(Equal("basic-check-spec"))
+		Expect(pass.TestCases[0].Classname).To(Equal("scorecard.basic"))
+		Expect(pass.TestCases[0].Time).To(Equal(junit.Duration(2 * time.Second)))
+		Expect(pass.TestCases[0].Failure).To(BeNil())
+		Expect(pass.TestCases[0].SystemOut).To(Equal("log line\nSuggestions:\n\tadd a spec\n"))
+
+		fail := report.Suites[1].TestCases[0]
+		Expect(fail.Failure).NotTo(BeNil())
+		Expect(fail.Failure.Message).To(Equal("invalid bundle"))
+		Expect(fail.Failure.Contents).To(Equal("Errors:\n\tinvalid bundle\nSuggestions:\n\tfix it\n"))
+
+		errored := report.Suites[2]
+		Expect(errored.Name).To(Equal("quay.io/example/test:v1 run it"))
+		Expect(errored.Timestamp).To(BeEmpty())
+		Expect(errored.TestCases[0].Name).To(Equal(errored.Name))
+		Expect(errored.TestCases[0].Error).NotTo(BeNil())
+		Expect(errored.TestCases[0].Error.Message).To(Equal("test errored"))
+
+		Expect(report.Suites[3].TestCases[0].Error.Message).To(Equal("test produced no results"))
+	})
+
+	It("records the timing of each test run", func() {
+		o := getFakeScorecard(false)
+		o.Timings = &Timings{}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		list, err := o.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(o.Timings.Items).To(HaveLen(len(list.Items)))
+		for _, timing := range o.Timings.Items {
+			Expect(timing.Start.IsZero()).To(BeFalse())
+			Expect(timing.Duration).To(BeNumerically(">=", 50*time.Millisecond))
+		}
+	})
+})
@@ -0,0 +1,126 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/sirupsen/logrus"
+
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+	"github.com/operator-framework/operator-sdk/internal/scorecard/tests"
+)
+
+const (
+	// BuiltinTestImage is the repository of the image running the built-in tests.
+	BuiltinTestImage = "quay.io/operator-framework/scorecard-test"
+	// builtinTestImageName is the name of BuiltinTestImage, which is kept by
+	// mirrors and copies of the image in other registries or organizations.
+	builtinTestImageName = "scorecard-test"
+	// builtinTestEntrypoint is the binary of BuiltinTestImage running the tests.
+	builtinTestEntrypoint = "scorecard-test"
+)
+
+// LocalTestRunner runs the built-in basic and olm tests in-process against
+// an on-disk bundle, without a cluster. Other tests, including the built-in
+// functional tests, are reported as errored.
+type LocalTestRunner struct {
+	BundlePath     string
+	BundleMetadata registryutil.Labels
+
+	bundle *apimanifests.Bundle
+	// mu serializes tests, since some of them reconfigure the global logger.
+	mu sync.Mutex
+}
+
+// Initialize reads the bundle under test.
+func (r *LocalTestRunner) Initialize(ctx context.Context) (err error) {
+	if r.bundle, err = apimanifests.GetBundleFromDir(r.BundlePath); err != nil {
+		return fmt.Errorf("error reading bundle %s: %w", r.BundlePath, err)
+	}
+	if r.BundleMetadata == nil {
+		if r.BundleMetadata, _, err = registryutil.FindBundleMetadata(r.BundlePath); err != nil {
+			return fmt.Errorf("error reading bundle metadata: %w", err)
+		}
+	}
+	return ctx.Err()
+}
+
+// RunTest runs a built-in test in-process.
+func (r *LocalTestRunner) RunTest(ctx context.Context, test v1alpha3.TestConfiguration) (*v1alpha3.TestStatus, error) {
+	name, ok := builtinTestName(test)
+	if !ok {
+		status := convertErrorToStatus(fmt.Errorf("test %q is not a built-in test and cannot be run by the local runner",
+			testName(test)), "")
+		status.Results[0].State = v1alpha3.ErrorState
+		return status, nil
+	}
+
+	if tests.IsFunctional(name) {
+		status := convertErrorToStatus(fmt.Errorf("test %q needs a cluster and cannot be run by the local runner",
+			name), "")
+		status.Results[0].State = v1alpha3.ErrorState
+		return status, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	defer logrus.SetLevel(logrus.GetLevel())
+	status, ok := tests.Run(name, r.BundlePath, r.bundle, r.BundleMetadata)
+	if !ok {
+		status := convertErrorToStatus(fmt.Errorf("unknown built-in test %q, valid tests are: %s", name,
+			strings.Join(tests.Names(), ", ")), "")
+		status.Results[0].State = v1alpha3.ErrorState
+		return status, nil
+	}
+	return &status, nil
+}
+
+// Cleanup does nothing, since no resources are created.
+func (r *LocalTestRunner) Cleanup(ctx context.Context) error {
+	return nil
+}
+
+// builtinTestName returns the name of the built-in test run by test, if test
+// runs BuiltinTestImage, or a copy of it in any registry or organization.
+func builtinTestName(test v1alpha3.TestConfiguration) (string, bool) {
+	if imageName(test.Image) != builtinTestImageName {
+		return "", false
+	}
+	if len(test.Entrypoint) != 2 || test.Entrypoint[0] != builtinTestEntrypoint {
+		return "", false
+	}
+	return test.Entrypoint[1], true
+}
+
+// imageName returns the last path component of image, without its registry,
+// organization, tag or digest.
+func imageName(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image[strings.LastIndex(image, "/")+1:]
+}
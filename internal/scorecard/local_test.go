@@ -0,0 +1,96 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var _ = Describe("Running tests locally", func() {
+	bundlePath := filepath.Join("testdata", "bundle")
+
+	It("runs the built-in tests of the bundle config in-process", func() {
+		config, err := LoadConfig(filepath.Join(bundlePath, "tests", "scorecard", "config.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		o := Scorecard{
+			Config:     config.Configuration,
+			Selector:   labels.Everything(),
+			TestRunner: &LocalTestRunner{BundlePath: bundlePath},
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		list, err := o.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(HaveLen(6))
+		for _, test := range list.Items {
+			Expect(test.Status.Results).To(HaveLen(1))
+			Expect(test.Status.Results[0].Name).To(Equal(test.Spec.Entrypoint[1]))
+			Expect(test.Status.Results[0].State).NotTo(Equal(v1alpha3.ErrorState))
+		}
+	})
+
+	It("reports tests that are not built-in as errored", func() {
+		r := &LocalTestRunner{BundlePath: bundlePath}
+		Expect(r.Initialize(context.TODO())).To(Succeed())
+
+		status, err := r.RunTest(context.TODO(), v1alpha3.TestConfiguration{
+			Image:      "quay.io/example/custom-test:v1",
+			Entrypoint: []string{"custom-test"},
+			Labels:     map[string]string{"test": "customtest1"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Results[0].State).To(Equal(v1alpha3.ErrorState))
+		Expect(status.Results[0].Errors[0]).To(ContainSubstring(`"customtest1" is not a built-in test`))
+
+		status, err = r.RunTest(context.TODO(), v1alpha3.TestConfiguration{
+			Image:      BuiltinTestImage + ":v1.5.0",
+			Entrypoint: []string{"scorecard-test", "no-such-test"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Results[0].State).To(Equal(v1alpha3.ErrorState))
+		Expect(status.Results[0].Errors[0]).To(ContainSubstring("unknown built-in test"))
+	})
+
+	It("recognizes built-in test images by name", func() {
+		for _, image := range []string{
+			BuiltinTestImage,
+			BuiltinTestImage + ":latest",
+			BuiltinTestImage + "@sha256:0123456789abcdef",
+			"localhost:5000/operator-framework/scorecard-test:latest",
+			"mirror.example.com/mirrors/scorecard-test:v1.5.0",
+			"scorecard-test",
+		} {
+			name, ok := builtinTestName(v1alpha3.TestConfiguration{
+				Image:      image,
+				Entrypoint: []string{"scorecard-test", "basic-check-spec"},
+			})
+			Expect(ok).To(BeTrue(), image)
+			Expect(name).To(Equal("basic-check-spec"))
+		}
+		_, ok := builtinTestName(v1alpha3.TestConfiguration{
+			Image:      "quay.io/example/scorecard-test-custom:latest",
+			Entrypoint: []string{"scorecard-test", "basic-check-spec"},
+		})
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,253 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// RestrictedSecurityContext is the name of the security context preset that
+// complies with the "restricted" Pod Security Standard.
+const RestrictedSecurityContext = "restricted"
+
+// restrictedUser is the user running the untar and bundle puller init
+// containers with the restricted preset, since their images run as root by
+// default. Test containers run as the user of their image.
+const restrictedUser = int64(65534)
+
+// PodOptions customize the pods running tests. They are set in the scorecard
+// config, either for all tests as the top-level "pod" field or for a single
+// test as the "pod" field of the test.
+type PodOptions struct {
+	// Resources are the resources of the test and untar containers.
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// NodeSelector selects the nodes test pods are scheduled on.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations are the tolerations of test pods.
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// ImagePullSecrets are the names of the secrets used to pull test images.
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+	// PodSecurityContext is the security context of test pods.
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// SecurityContext is the security context of the test and untar containers.
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// UntarImage is the image of the init container unpacking the bundle.
+	UntarImage string `json:"untarImage,omitempty"`
+	// BundlePullerImage is the image of the init container pulling the bundle
+	// image, with the image bundle transfer mode. It must have a shell and crane.
+	BundlePullerImage string `json:"bundlePullerImage,omitempty"`
+}
+
+// Merge returns o with the fields set in override replaced. Resource requests
+// and limits are merged per resource, and security contexts per field.
+func (o PodOptions) Merge(override PodOptions) PodOptions {
+	o.Resources = mergeResources(o.Resources, override.Resources)
+	if override.NodeSelector != nil {
+		o.NodeSelector = override.NodeSelector
+	}
+	if override.Tolerations != nil {
+		o.Tolerations = override.Tolerations
+	}
+	if override.ImagePullSecrets != nil {
+		o.ImagePullSecrets = override.ImagePullSecrets
+	}
+	o.PodSecurityContext = mergePodSecurityContexts(o.PodSecurityContext, override.PodSecurityContext)
+	o.SecurityContext = mergeSecurityContexts(o.SecurityContext, override.SecurityContext)
+	if override.UntarImage != "" {
+		o.UntarImage = override.UntarImage
+	}
+	if override.BundlePullerImage != "" {
+		o.BundlePullerImage = override.BundlePullerImage
+	}
+	return o
+}
+
+func mergeResources(base, override *v1.ResourceRequirements) *v1.ResourceRequirements {
+	if base == nil || override == nil {
+		if override != nil {
+			return override.DeepCopy()
+		}
+		return base
+	}
+	merged := base.DeepCopy()
+	merged.Requests = mergeResourceLists(merged.Requests, override.Requests)
+	merged.Limits = mergeResourceLists(merged.Limits, override.Limits)
+	return merged
+}
+
+func mergeResourceLists(base, override v1.ResourceList) v1.ResourceList {
+	if len(override) == 0 {
+		return base
+	}
+	if base == nil {
+		base = v1.ResourceList{}
+	}
+	for name, quantity := range override {
+		base[name] = quantity.DeepCopy()
+	}
+	return base
+}
+
+func mergePodSecurityContexts(base, override *v1.PodSecurityContext) *v1.PodSecurityContext {
+	if base == nil || override == nil {
+		if override != nil {
+			return override.DeepCopy()
+		}
+		return base
+	}
+	merged, o := base.DeepCopy(), override.DeepCopy()
+	if o.SELinuxOptions != nil {
+		merged.SELinuxOptions = o.SELinuxOptions
+	}
+	if o.WindowsOptions != nil {
+		merged.WindowsOptions = o.WindowsOptions
+	}
+	if o.RunAsUser != nil {
+		merged.RunAsUser = o.RunAsUser
+	}
+	if o.RunAsGroup != nil {
+		merged.RunAsGroup = o.RunAsGroup
+	}
+	if o.RunAsNonRoot != nil {
+		merged.RunAsNonRoot = o.RunAsNonRoot
+	}
+	if o.SupplementalGroups != nil {
+		merged.SupplementalGroups = o.SupplementalGroups
+	}
+	if o.FSGroup != nil {
+		merged.FSGroup = o.FSGroup
+	}
+	if o.Sysctls != nil {
+		merged.Sysctls = o.Sysctls
+	}
+	if o.FSGroupChangePolicy != nil {
+		merged.FSGroupChangePolicy = o.FSGroupChangePolicy
+	}
+	if o.SeccompProfile != nil {
+		merged.SeccompProfile = o.SeccompProfile
+	}
+	return merged
+}
+
+func mergeSecurityContexts(base, override *v1.SecurityContext) *v1.SecurityContext {
+	if base == nil || override == nil {
+		if override != nil {
+			return override.DeepCopy()
+		}
+		return base
+	}
+	merged, o := base.DeepCopy(), override.DeepCopy()
+	if o.Capabilities != nil {
+		merged.Capabilities = o.Capabilities
+	}
+	if o.Privileged != nil {
+		merged.Privileged = o.Privileged
+	}
+	if o.SELinuxOptions != nil {
+		merged.SELinuxOptions = o.SELinuxOptions
+	}
+	if o.WindowsOptions != nil {
+		merged.WindowsOptions = o.WindowsOptions
+	}
+	if o.RunAsUser != nil {
+		merged.RunAsUser = o.RunAsUser
+	}
+	if o.RunAsGroup != nil {
+		merged.RunAsGroup = o.RunAsGroup
+	}
+	if o.RunAsNonRoot != nil {
+		merged.RunAsNonRoot = o.RunAsNonRoot
+	}
+	if o.ReadOnlyRootFilesystem != nil {
+		merged.ReadOnlyRootFilesystem = o.ReadOnlyRootFilesystem
+	}
+	if o.AllowPrivilegeEscalation != nil {
+		merged.AllowPrivilegeEscalation = o.AllowPrivilegeEscalation
+	}
+	if o.ProcMount != nil {
+		merged.ProcMount = o.ProcMount
+	}
+	if o.SeccompProfile != nil {
+		merged.SeccompProfile = o.SeccompProfile
+	}
+	return merged
+}
+
+// initSecurityContext returns the security context of the init containers
+// scorecard adds to test pods. Their images run as root, so they run as
+// restrictedUser if the pod must run as non-root without a user being set.
+func (o PodOptions) initSecurityContext() *v1.SecurityContext {
+	sc := o.SecurityContext.DeepCopy()
+	psc := o.PodSecurityContext
+	nonRoot := (psc != nil && psc.RunAsNonRoot != nil && *psc.RunAsNonRoot) ||
+		(sc != nil && sc.RunAsNonRoot != nil && *sc.RunAsNonRoot)
+	if !nonRoot || (psc != nil && psc.RunAsUser != nil) || (sc != nil && sc.RunAsUser != nil) {
+		return sc
+	}
+	if sc == nil {
+		sc = &v1.SecurityContext{}
+	}
+	user := restrictedUser
+	sc.RunAsUser = &user
+	return sc
+}
+
+// untarImage returns the image of the init container unpacking the bundle.
+func (o PodOptions) untarImage() string {
+	if o.UntarImage != "" {
+		return o.UntarImage
+	}
+	return scorecardUntarImage
+}
+
+// podOptions returns the pod options of all tests in cfg, and those of single
+// tests keyed by their "test" label.
+func podOptions(cfg configOptions) (PodOptions, map[string]PodOptions, error) {
+	perTest := map[string]PodOptions{}
+	for i, stage := range cfg.Stages {
+		for j, test := range stage.Tests {
+			if test.Pod == nil {
+				continue
+			}
+			name := test.Labels["test"]
+			if name == "" {
+				return PodOptions{}, nil, fmt.Errorf("stage %d test %d: pod requires a \"test\" label", i, j)
+			}
+			perTest[name] = *test.Pod
+		}
+	}
+	return cfg.Pod, perTest, nil
+}
+
+// RestrictedPodOptions returns pod options whose security contexts comply with
+// the "restricted" Pod Security Standard. Test images must set a non-root user.
+func RestrictedPodOptions() PodOptions {
+	nonRoot, noEscalation := true, false
+	// The group lets restrictedUser write to volumes such as the bundle PVC.
+	group := restrictedUser
+	return PodOptions{
+		PodSecurityContext: &v1.PodSecurityContext{
+			RunAsNonRoot:   &nonRoot,
+			FSGroup:        &group,
+			SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+		},
+		SecurityContext: &v1.SecurityContext{
+			AllowPrivilegeEscalation: &noEscalation,
+			Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+		},
+	}
+}
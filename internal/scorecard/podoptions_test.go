@@ -0,0 +1,147 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("Test pod options", func() {
+	Describe("Loading pod options", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "scorecard-config-")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		load := func(config string) (PodOptions, map[string]PodOptions, error) {
+			path := filepath.Join(dir, ConfigFileName)
+			Expect(ioutil.WriteFile(path, []byte(config), 0644)).To(Succeed())
+			cfg, err := LoadConfig(path)
+			return cfg.PodOptions, cfg.TestPodOptions, err
+		}
+
+		It("reads global and per-test pod options", func() {
+			global, perTest, err := load(`pod:
+  nodeSelector:
+    kubernetes.io/os: linux
+  imagePullSecrets: [registry]
+  untarImage: registry.example.com/busybox:1.33.0
+stages:
+- tests:
+  - image: img
+    labels: {test: a}
+    pod:
+      resources:
+        limits:
+          memory: 1Gi
+  - image: img
+    labels: {test: b}
+`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(global.NodeSelector).To(Equal(map[string]string{"kubernetes.io/os": "linux"}))
+			Expect(global.ImagePullSecrets).To(Equal([]string{"registry"}))
+			Expect(global.UntarImage).To(Equal("registry.example.com/busybox:1.33.0"))
+			Expect(perTest).To(HaveLen(1))
+			Expect(perTest["a"].Resources.Limits.Memory().String()).To(Equal("1Gi"))
+		})
+		It("returns an error for pod options of a test without a test label", func() {
+			_, _, err := load("stages:\n- tests:\n  - image: img\n    pod: {untarImage: x}\n")
+			Expect(err).To(MatchError(ContainSubstring(`pod requires a "test" label`)))
+		})
+	})
+
+	Describe("Merge", func() {
+		It("merges resources per resource and security contexts per field", func() {
+			nonRoot, readOnly := true, true
+			user := int64(1000)
+			base := PodOptions{
+				Resources: &v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+					Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+				PodSecurityContext: &v1.PodSecurityContext{RunAsNonRoot: &nonRoot},
+				SecurityContext:    &v1.SecurityContext{ReadOnlyRootFilesystem: &readOnly},
+			}
+			merged := base.Merge(PodOptions{
+				Resources: &v1.ResourceRequirements{
+					Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("2Gi")},
+				},
+				PodSecurityContext: &v1.PodSecurityContext{RunAsUser: &user},
+				SecurityContext:    &v1.SecurityContext{RunAsUser: &user},
+			})
+			Expect(merged.Resources.Requests.Cpu().String()).To(Equal("100m"))
+			Expect(merged.Resources.Limits.Memory().String()).To(Equal("2Gi"))
+			Expect(*merged.PodSecurityContext.RunAsNonRoot).To(BeTrue())
+			Expect(*merged.PodSecurityContext.RunAsUser).To(Equal(user))
+			Expect(*merged.SecurityContext.ReadOnlyRootFilesystem).To(BeTrue())
+			Expect(*merged.SecurityContext.RunAsUser).To(Equal(user))
+
+			Expect(base.Resources.Limits.Memory().String()).To(Equal("1Gi"))
+			Expect(base.PodSecurityContext.RunAsUser).To(BeNil())
+		})
+	})
+
+	Describe("getPodDefinition", func() {
+		test := v1alpha3.TestConfiguration{Image: "img", Labels: map[string]string{"test": "a"}}
+
+		It("uses the default untar image and no customization by default", func() {
+			pod := getPodDefinition("cm", test, PodTestRunner{})
+			Expect(pod.Spec.InitContainers[0].Image).To(Equal(scorecardUntarImage))
+			Expect(pod.Spec.SecurityContext).To(BeNil())
+			Expect(pod.Spec.ImagePullSecrets).To(BeEmpty())
+		})
+		It("applies global options overridden by test options", func() {
+			runner := PodTestRunner{
+				PodOptions: RestrictedPodOptions().Merge(PodOptions{
+					NodeSelector:     map[string]string{"pool": "ci"},
+					ImagePullSecrets: []string{"registry"},
+					UntarImage:       "mirror/busybox",
+				}),
+				TestPodOptions: map[string]PodOptions{"a": {Resources: &v1.ResourceRequirements{
+					Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+				}}},
+			}
+			pod := getPodDefinition("cm", test, runner)
+			Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{"pool": "ci"}))
+			Expect(pod.Spec.ImagePullSecrets).To(Equal([]v1.LocalObjectReference{{Name: "registry"}}))
+			Expect(*pod.Spec.SecurityContext.RunAsNonRoot).To(BeTrue())
+			Expect(pod.Spec.SecurityContext.RunAsUser).To(BeNil())
+			Expect(*pod.Spec.InitContainers[0].SecurityContext.RunAsUser).To(Equal(restrictedUser))
+			Expect(pod.Spec.Containers[0].SecurityContext.RunAsUser).To(BeNil())
+			Expect(pod.Spec.InitContainers[0].Image).To(Equal("mirror/busybox"))
+			for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+				Expect(*c.SecurityContext.AllowPrivilegeEscalation).To(BeFalse())
+				Expect(c.Resources.Limits.Memory().String()).To(Equal("1Gi"))
+			}
+
+			other := getPodDefinition("cm", v1alpha3.TestConfiguration{Image: "img"}, runner)
+			Expect(other.Spec.Containers[0].Resources.Limits).To(BeEmpty())
+		})
+	})
+})
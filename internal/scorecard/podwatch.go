@@ -0,0 +1,266 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// podWatcher - notifies tests of changes to their pods, using a single informer
+// on the pods of a test run.
+type podWatcher struct {
+	lister listersv1.PodLister
+	stop   chan struct{}
+
+	mu          sync.Mutex
+	subscribers map[string]chan struct{}
+}
+
+// newPodWatcher starts watching the pods labelled with testrun in namespace,
+// and returns once the watch is synced.
+func newPodWatcher(ctx context.Context, client kubernetes.Interface, namespace, testrun string) (*podWatcher, error) {
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = fmt.Sprintf("testrun=%s", testrun)
+		}))
+	informer := factory.Core().V1().Pods()
+	w := &podWatcher{
+		lister:      informer.Lister(),
+		stop:        make(chan struct{}),
+		subscribers: map[string]chan struct{}{},
+	}
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    w.notify,
+		UpdateFunc: func(_, obj interface{}) { w.notify(obj) },
+		DeleteFunc: w.notify,
+	})
+	factory.Start(w.stop)
+
+	for _, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			w.Stop()
+			return nil, fmt.Errorf("error watching test pods: %w", ctx.Err())
+		}
+	}
+	return w, nil
+}
+
+// Stop stops the watch.
+func (w *podWatcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	select {
+	case <-w.stop:
+	default:
+		close(w.stop)
+	}
+}
+
+func (w *podWatcher) notify(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if ch, ok := w.subscribers[pod.Name]; ok {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// waitForCompletion waits for the pod named name to complete. onChange is
+// called with the pod each time it changes. An error is returned if the pod
+// cannot complete, or if ctx is done before it completes.
+func (w *podWatcher) waitForCompletion(ctx context.Context, namespace, name string, onChange func(*v1.Pod)) error {
+	ch := make(chan struct{}, 1)
+	w.mu.Lock()
+	w.subscribers[name] = ch
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		delete(w.subscribers, name)
+		w.mu.Unlock()
+	}()
+
+	// The pod may have changed before subscribing.
+	ch <- struct{}{}
+	problem := ""
+	for {
+		select {
+		case <-ctx.Done():
+			if problem != "" {
+				return fmt.Errorf("test pod %s %s: %w", name, problem, ctx.Err())
+			}
+			return ctx.Err()
+		case <-w.stop:
+			return fmt.Errorf("stopped watching test pod %s", name)
+		case <-ch:
+		}
+
+		pod, err := w.lister.Pods(namespace).Get(name)
+		if err != nil {
+			// The pod is not in the cache yet, or was deleted.
+			continue
+		}
+		if onChange != nil {
+			onChange(pod)
+		}
+		if err := podFailure(pod); err != nil {
+			return err
+		}
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			return nil
+		}
+		problem = podPendingProblem(pod)
+	}
+}
+
+// PodFailedError is returned when a test pod cannot run its test, for example
+// because its image cannot be pulled.
+type PodFailedError struct {
+	Pod    string
+	Reason string
+}
+
+func (e *PodFailedError) Error() string {
+	return fmt.Sprintf("test pod %s failed: %s", e.Pod, e.Reason)
+}
+
+// waitingFailureReasons are the reasons of waiting containers that will not
+// start without changes to the test or the cluster.
+var waitingFailureReasons = sets.NewString(
+	"ImagePullBackOff",
+	"InvalidImageName",
+	"ErrImageNeverPull",
+	"CreateContainerConfigError",
+	"CreateContainerError",
+)
+
+// podFailure returns an error if pod cannot run its test, or ran out of memory.
+func podFailure(pod *v1.Pod) error {
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...),
+		pod.Status.ContainerStatuses...)
+	for _, s := range statuses {
+		if w := s.State.Waiting; w != nil && waitingFailureReasons.Has(w.Reason) {
+			return &PodFailedError{Pod: pod.Name,
+				Reason: fmt.Sprintf("container %s cannot start (%s): %s", s.Name, w.Reason, w.Message)}
+		}
+		if t := s.State.Terminated; t != nil && t.Reason == "OOMKilled" {
+			return &PodFailedError{Pod: pod.Name,
+				Reason: fmt.Sprintf("container %s ran out of memory (OOMKilled)", s.Name)}
+		}
+	}
+	for _, s := range pod.Status.InitContainerStatuses {
+		if t := s.State.Terminated; t != nil && t.ExitCode != 0 {
+			return &PodFailedError{Pod: pod.Name,
+				Reason: fmt.Sprintf("init container %s exited with code %d: %s", s.Name, t.ExitCode,
+					strings.TrimSpace(t.Reason+" "+t.Message))}
+		}
+	}
+	if pod.Status.Phase == v1.PodFailed && pod.Status.Reason != "" {
+		return &PodFailedError{Pod: pod.Name, Reason: fmt.Sprintf("%s: %s", pod.Status.Reason, pod.Status.Message)}
+	}
+	return nil
+}
+
+// podPendingProblem returns why pod is not running yet, if it is unlikely to
+// be transient. It is reported if the test times out.
+func podPendingProblem(pod *v1.Pod) string {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodScheduled && c.Status == v1.ConditionFalse && c.Reason == v1.PodReasonUnschedulable {
+			return fmt.Sprintf("could not be scheduled: %s", c.Message)
+		}
+	}
+	for _, s := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...),
+		pod.Status.ContainerStatuses...) {
+		if w := s.State.Waiting; w != nil && w.Reason == "ErrImagePull" {
+			return fmt.Sprintf("could not pull image %s: %s", s.Image, w.Message)
+		}
+	}
+	return ""
+}
+
+// containerStarted returns true if the named container of pod is running or has run.
+func containerStarted(pod *v1.Pod, container string) bool {
+	for _, s := range pod.Status.ContainerStatuses {
+		if s.Name == container {
+			return s.State.Running != nil || s.State.Terminated != nil
+		}
+	}
+	return false
+}
+
+// prefixWriter - writes whole lines to a shared writer, prefixed with the test name.
+type prefixWriter struct {
+	mu  *sync.Mutex
+	out io.Writer
+}
+
+// streamPodLogs copies the logs of container of pod to w as they are written,
+// until the container exits or ctx is done.
+func streamPodLogs(ctx context.Context, client kubernetes.Interface, pod *v1.Pod, container, prefix string,
+	w prefixWriter) {
+	req := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+		Container: container,
+		Follow:    true,
+	})
+	logs, err := req.Stream(ctx)
+	if err != nil {
+		w.writeLine(prefix, fmt.Sprintf("error streaming logs: %v", err))
+		return
+	}
+	defer logs.Close()
+
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	inArtifacts := false
+	for scanner.Scan() {
+		// The test files written to the log are of no use to readers.
+		switch line := scanner.Text(); {
+		case line == artifactsBeginDelimiter:
+			inArtifacts = true
+		case line == artifactsEndDelimiter:
+			inArtifacts = false
+		case !inArtifacts:
+			w.writeLine(prefix, line)
+		}
+	}
+}
+
+func (w prefixWriter) writeLine(prefix, line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(w.out, "[%s] %s\n", prefix, line)
+}
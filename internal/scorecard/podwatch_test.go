@@ -0,0 +1,137 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Watching test pods", func() {
+	const (
+		namespace = "scorecard"
+		testrun   = "scorecard-test-abcd"
+	)
+
+	Describe("podFailure", func() {
+		pod := func(status v1.PodStatus) *v1.Pod {
+			return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p"}, Status: status}
+		}
+
+		It("returns no error for running and completed pods", func() {
+			Expect(podFailure(pod(v1.PodStatus{Phase: v1.PodRunning}))).To(Succeed())
+			Expect(podFailure(pod(v1.PodStatus{Phase: v1.PodFailed}))).To(Succeed())
+		})
+		It("returns an error for images that cannot be pulled", func() {
+			err := podFailure(pod(v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+				Name:  "scorecard-test",
+				State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "nope"}},
+			}}}))
+			Expect(err).To(MatchError("test pod p failed: container scorecard-test cannot start (ImagePullBackOff): nope"))
+		})
+		It("returns an error for containers killed for running out of memory", func() {
+			err := podFailure(pod(v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+				Name:  "scorecard-test",
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+			}}}))
+			Expect(err).To(MatchError(ContainSubstring("ran out of memory (OOMKilled)")))
+		})
+		It("returns an error for failed init containers", func() {
+			err := podFailure(pod(v1.PodStatus{InitContainerStatuses: []v1.ContainerStatus{{
+				Name:  "scorecard-untar",
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}},
+			}}}))
+			Expect(err).To(MatchError(ContainSubstring("init container scorecard-untar exited with code 1")))
+		})
+	})
+
+	Context("with a cluster", func() {
+		var (
+			client *fake.Clientset
+			runner PodTestRunner
+			pod    *v1.Pod
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+
+		BeforeEach(func() {
+			client = fake.NewSimpleClientset()
+			ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+			watcher, err := newPodWatcher(ctx, client, namespace, testrun)
+			Expect(err).NotTo(HaveOccurred())
+			runner = PodTestRunner{Client: client, Namespace: namespace, watcher: watcher, logMu: &sync.Mutex{}}
+
+			pod = &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name: "scorecard-test-1234", Namespace: namespace, Labels: map[string]string{"testrun": testrun},
+			}}
+			pod, err = client.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			runner.watcher.Stop()
+			cancel()
+		})
+
+		setStatus := func(status v1.PodStatus) {
+			go func() {
+				defer GinkgoRecover()
+				time.Sleep(50 * time.Millisecond)
+				updated := pod.DeepCopy()
+				updated.Status = status
+				_, err := client.CoreV1().Pods(namespace).UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}()
+		}
+
+		It("waits for the pod to complete and streams its logs", func() {
+			out := &bytes.Buffer{}
+			runner.LogOutput = out
+			setStatus(v1.PodStatus{Phase: v1.PodSucceeded, ContainerStatuses: []v1.ContainerStatus{{
+				Name:  testContainerName,
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{}},
+			}}})
+			Expect(runner.waitForTestToComplete(ctx, pod, "my-test")).To(Succeed())
+			Expect(out.String()).To(Equal("[my-test] fake logs\n"))
+		})
+		It("returns an error as soon as the test image cannot be pulled", func() {
+			setStatus(v1.PodStatus{Phase: v1.PodPending, ContainerStatuses: []v1.ContainerStatus{{
+				Name:  testContainerName,
+				State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "InvalidImageName"}},
+			}}})
+			var failed *PodFailedError
+			Expect(errors.As(runner.waitForTestToComplete(ctx, pod, "my-test"), &failed)).To(BeTrue())
+		})
+		It("reports why an unschedulable pod timed out", func() {
+			setStatus(v1.PodStatus{Phase: v1.PodPending, Conditions: []v1.PodCondition{{
+				Type: v1.PodScheduled, Status: v1.ConditionFalse, Reason: v1.PodReasonUnschedulable,
+				Message: "0/1 nodes are available",
+			}}})
+			waitCtx, waitCancel := context.WithTimeout(ctx, 500*time.Millisecond)
+			defer waitCancel()
+			err := runner.waitForTestToComplete(waitCtx, pod, "my-test")
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("could not be scheduled: 0/1 nodes are available")))
+		})
+	})
+})
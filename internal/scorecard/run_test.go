@@ -60,10 +60,11 @@ func TestRun(t *testing.T) {
 			o := Scorecard{}
 			var err error
 			configPath := filepath.Join(c.configPathValue, "tests", "scorecard", "config.yaml")
-			o.Config, err = LoadConfig(configPath)
+			config, err := LoadConfig(configPath)
 			if err != nil {
 				t.Fatalf("Unexpected error loading config %v", err)
 			}
+			o.Config = config.Configuration
 			o.Selector, err = labels.Parse(c.selector)
 			if err != nil {
 				t.Fatalf("Unexpected error parsing selector %v", err)
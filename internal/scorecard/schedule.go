@@ -0,0 +1,183 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+// testOutcomes tracks whether the tests of a run passed, so that tests can
+// wait for the tests they depend on.
+type testOutcomes struct {
+	mu     sync.Mutex
+	done   map[string]chan struct{}
+	passed map[string]bool
+}
+
+// newTestOutcomes returns outcomes for the named tests, which are all expected to run.
+func newTestOutcomes(names []string) *testOutcomes {
+	t := &testOutcomes{
+		done:   map[string]chan struct{}{},
+		passed: map[string]bool{},
+	}
+	for _, name := range names {
+		if name != "" {
+			t.done[name] = make(chan struct{})
+		}
+	}
+	return t
+}
+
+// set records the outcome of the named test.
+func (t *testOutcomes) set(name string, passed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	done, ok := t.done[name]
+	if !ok {
+		return
+	}
+	t.passed[name] = passed
+	close(done)
+}
+
+// failedDependency waits for the tests in deps to complete and returns the
+// first one that did not pass, if any. Tests that are not part of the run,
+// because they were not selected, are ignored.
+func (t *testOutcomes) failedDependency(ctx context.Context, deps []string) (string, error) {
+	for _, dep := range deps {
+		done, ok := t.done[dep]
+		if !ok {
+			continue
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		t.mu.Lock()
+		passed := t.passed[dep]
+		t.mu.Unlock()
+		if !passed {
+			return dep, nil
+		}
+	}
+	return "", nil
+}
+
+// runTest runs test once its dependencies passed, retrying it as configured
+// in its options until it passes.
+func (o Scorecard) runTest(ctx context.Context, test v1alpha3.TestConfiguration, outcomes *testOutcomes) timedTest {
+	name := test.Labels["test"]
+	opts := o.TestOptions[name]
+	out := v1alpha3.NewTest()
+	out.Spec = test
+	timing := TestTiming{Start: time.Now()}
+	passed := false
+	defer func() { outcomes.set(name, passed) }()
+
+	failed, err := outcomes.failedDependency(ctx, opts.DependsOn)
+	switch {
+	case err != nil:
+		out.Status = *convertErrorToStatus(err, "")
+		timing.Duration = time.Since(timing.Start)
+		return timedTest{test: out, timing: timing}
+	case failed != "":
+		out.Status = *skippedStatus(test, failed)
+		timing.Skipped = true
+		timing.Duration = time.Since(timing.Start)
+		return timedTest{test: out, timing: timing}
+	}
+
+	backoff := opts.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		var status *v1alpha3.TestStatus
+		status, timing.TimedOut = o.runAttempt(ctx, test, opts.Timeout)
+		out.Status = *status
+		timing.Attempts = attempt
+		if passed = statusPassed(status); passed || attempt > opts.Retries {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		backoff *= 2
+	}
+	if !passed && timing.Attempts > 1 && len(out.Status.Results) > 0 {
+		out.Status.Results[0].Errors = append(out.Status.Results[0].Errors,
+			fmt.Sprintf("test did not pass after %d attempts", timing.Attempts))
+	}
+	timing.Duration = time.Since(timing.Start)
+	return timedTest{test: out, timing: timing}
+}
+
+// runAttempt runs test once, with a deadline if timeout is set. It returns
+// true if the test was stopped by that deadline.
+func (o Scorecard) runAttempt(ctx context.Context, test v1alpha3.TestConfiguration,
+	timeout time.Duration) (*v1alpha3.TestStatus, bool) {
+	attemptCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result, err := o.TestRunner.RunTest(attemptCtx, test)
+	if err != nil {
+		if timeout > 0 && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+			return timedOutStatus(test, timeout), true
+		}
+		return convertErrorToStatus(err, ""), false
+	}
+	return result, false
+}
+
+// statusPassed returns true if status has results, all of which passed.
+func statusPassed(status *v1alpha3.TestStatus) bool {
+	if status == nil || len(status.Results) == 0 {
+		return false
+	}
+	for _, r := range status.Results {
+		if r.State != v1alpha3.PassState {
+			return false
+		}
+	}
+	return true
+}
+
+func timedOutStatus(test v1alpha3.TestConfiguration, timeout time.Duration) *v1alpha3.TestStatus {
+	return &v1alpha3.TestStatus{Results: []v1alpha3.TestResult{{
+		Name:   testName(test),
+		State:  v1alpha3.ErrorState,
+		Errors: []string{fmt.Sprintf("test did not finish within %s", timeout)},
+	}}}
+}
+
+func skippedStatus(test v1alpha3.TestConfiguration, dependency string) *v1alpha3.TestStatus {
+	return &v1alpha3.TestStatus{Results: []v1alpha3.TestResult{{
+		Name:   testName(test),
+		State:  v1alpha3.ErrorState,
+		Errors: []string{fmt.Sprintf("test not run because test %q did not pass", dependency)},
+	}}}
+}
@@ -0,0 +1,270 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// scriptedTestRunner returns the states of scripted for each attempt of a test,
+// keyed by "test" label, and blocks until ctx is done for tests in hang.
+type scriptedTestRunner struct {
+	mu       sync.Mutex
+	scripted map[string][]v1alpha3.State
+	hang     map[string]bool
+	attempts map[string]int
+}
+
+func (r *scriptedTestRunner) Initialize(context.Context) error { return nil }
+func (r *scriptedTestRunner) Cleanup(context.Context) error    { return nil }
+
+func (r *scriptedTestRunner) RunTest(ctx context.Context, test v1alpha3.TestConfiguration) (*v1alpha3.TestStatus, error) {
+	name := test.Labels["test"]
+	r.mu.Lock()
+	attempt := r.attempts[name]
+	r.attempts[name]++
+	r.mu.Unlock()
+	if r.hang[name] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	state := v1alpha3.PassState
+	if states := r.scripted[name]; len(states) > 0 {
+		state = states[len(states)-1]
+		if attempt < len(states) {
+			state = states[attempt]
+		}
+	}
+	return &v1alpha3.TestStatus{Results: []v1alpha3.TestResult{{Name: name, State: state}}}, nil
+}
+
+func stagesConfig(stages ...v1alpha3.StageConfiguration) v1alpha3.Configuration {
+	return v1alpha3.Configuration{Stages: stages}
+}
+
+func labelledTest(name string) v1alpha3.TestConfiguration {
+	return v1alpha3.TestConfiguration{Image: "img", Labels: map[string]string{"test": name}}
+}
+
+var _ = Describe("Test scheduling options", func() {
+	Describe("Loading test options", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "scorecard-config-")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		load := func(config string) (map[string]TestOptions, error) {
+			path := filepath.Join(dir, ConfigFileName)
+			Expect(ioutil.WriteFile(path, []byte(config), 0644)).To(Succeed())
+			cfg, err := LoadConfig(path)
+			return cfg.TestOptions, err
+		}
+
+		It("parses timeouts, retries and dependencies", func() {
+			opts, err := load(`stages:
+- tests:
+  - image: img
+    labels: {test: a}
+    timeout: 1m
+    retries: 2
+  - image: img
+    labels: {test: b}
+    retryBackoff: 1s
+    dependsOn: [a]
+`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts).To(HaveKeyWithValue("a", TestOptions{Timeout: time.Minute, Retries: 2,
+				RetryBackoff: DefaultRetryBackoff}))
+			Expect(opts).To(HaveKeyWithValue("b", TestOptions{RetryBackoff: time.Second, DependsOn: []string{"a"}}))
+		})
+		It("returns an error for options of a test without a test label", func() {
+			_, err := load("stages:\n- tests:\n  - image: img\n    retries: 1\n")
+			Expect(err).To(MatchError(ContainSubstring(`require a "test" label`)))
+		})
+		It("returns an error for unknown dependencies", func() {
+			_, err := load("stages:\n- tests:\n  - image: img\n    labels: {test: a}\n    dependsOn: [b]\n")
+			Expect(err).To(MatchError(ContainSubstring(`depends on unknown test "b"`)))
+		})
+		It("returns an error for dependencies that run later in a sequential stage", func() {
+			_, err := load(`stages:
+- tests:
+  - image: img
+    labels: {test: a}
+    dependsOn: [b]
+  - image: img
+    labels: {test: b}
+`)
+			Expect(err).To(MatchError(ContainSubstring("runs after it")))
+		})
+		It("returns an error for dependency cycles", func() {
+			_, err := load(`stages:
+- parallel: true
+  tests:
+  - image: img
+    labels: {test: a}
+    dependsOn: [b]
+  - image: img
+    labels: {test: b}
+    dependsOn: [a]
+`)
+			Expect(err).To(MatchError(ContainSubstring("cycle")))
+		})
+	})
+
+	Describe("Run", func() {
+		var runner *scriptedTestRunner
+
+		BeforeEach(func() {
+			runner = &scriptedTestRunner{
+				scripted: map[string][]v1alpha3.State{},
+				hang:     map[string]bool{},
+				attempts: map[string]int{},
+			}
+		})
+
+		run := func(o Scorecard) v1alpha3.TestList {
+			o.Selector = labels.Everything()
+			o.TestRunner = runner
+			o.SkipCleanup = true
+			if o.Timings == nil {
+				o.Timings = &Timings{}
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			list, err := o.Run(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(o.Timings.Items).To(HaveLen(len(list.Items)))
+			return list
+		}
+
+		It("retries a test until it passes", func() {
+			runner.scripted["a"] = []v1alpha3.State{v1alpha3.FailState, v1alpha3.ErrorState, v1alpha3.PassState}
+			o := Scorecard{
+				Config:      stagesConfig(v1alpha3.StageConfiguration{Tests: []v1alpha3.TestConfiguration{labelledTest("a")}}),
+				TestOptions: map[string]TestOptions{"a": {Retries: 3, RetryBackoff: time.Millisecond}},
+			}
+			list := run(o)
+			Expect(list.Items[0].Status.Results[0].State).To(Equal(v1alpha3.PassState))
+			Expect(runner.attempts["a"]).To(Equal(3))
+		})
+		It("reports the number of attempts of a test that never passes", func() {
+			runner.scripted["a"] = []v1alpha3.State{v1alpha3.FailState}
+			o := Scorecard{
+				Config:      stagesConfig(v1alpha3.StageConfiguration{Tests: []v1alpha3.TestConfiguration{labelledTest("a")}}),
+				TestOptions: map[string]TestOptions{"a": {Retries: 1, RetryBackoff: time.Millisecond}},
+			}
+			list := run(o)
+			result := list.Items[0].Status.Results[0]
+			Expect(result.State).To(Equal(v1alpha3.FailState))
+			Expect(result.Errors).To(ContainElement("test did not pass after 2 attempts"))
+			Expect(runner.attempts["a"]).To(Equal(2))
+		})
+		It("marks a test that exceeds its timeout as timed out", func() {
+			runner.hang["a"] = true
+			o := Scorecard{
+				Config:      stagesConfig(v1alpha3.StageConfiguration{Tests: []v1alpha3.TestConfiguration{labelledTest("a")}}),
+				TestOptions: map[string]TestOptions{"a": {Timeout: 10 * time.Millisecond}},
+				Timings:     &Timings{},
+			}
+			list := run(o)
+			result := list.Items[0].Status.Results[0]
+			Expect(result.State).To(Equal(v1alpha3.ErrorState))
+			Expect(result.Errors).To(ConsistOf("test did not finish within 10ms"))
+			Expect(o.Timings.Items[0].TimedOut).To(BeTrue())
+			Expect(o.Timings.Items[0].Skipped).To(BeFalse())
+		})
+		It("skips tests whose dependencies did not pass", func() {
+			runner.scripted["a"] = []v1alpha3.State{v1alpha3.FailState}
+			o := Scorecard{
+				Config: stagesConfig(
+					v1alpha3.StageConfiguration{Tests: []v1alpha3.TestConfiguration{labelledTest("a")}},
+					v1alpha3.StageConfiguration{Parallel: true, Tests: []v1alpha3.TestConfiguration{
+						labelledTest("b"), labelledTest("c"),
+					}},
+				),
+				TestOptions: map[string]TestOptions{
+					"b": {DependsOn: []string{"a"}},
+					"c": {DependsOn: []string{"b"}},
+				},
+				Timings: &Timings{},
+			}
+			list := run(o)
+			Expect(list.Items).To(HaveLen(3))
+			Expect(o.Timings.Items[0].Skipped).To(BeFalse())
+			for i, test := range list.Items[1:] {
+				Expect(test.Status.Results[0].State).To(Equal(v1alpha3.ErrorState))
+				Expect(test.Status.Results[0].Errors[0]).To(ContainSubstring("did not pass"))
+				Expect(o.Timings.Items[i+1].Skipped).To(BeTrue())
+			}
+			Expect(runner.attempts).NotTo(HaveKey("b"))
+			Expect(runner.attempts).NotTo(HaveKey("c"))
+		})
+		It("ignores dependencies that are not selected", func() {
+			o := Scorecard{
+				Config: stagesConfig(v1alpha3.StageConfiguration{Tests: []v1alpha3.TestConfiguration{
+					labelledTest("a"), labelledTest("b"),
+				}}),
+				TestOptions: map[string]TestOptions{"b": {DependsOn: []string{"a"}}},
+			}
+			var err error
+			o.Selector, err = labels.Parse("test=b")
+			Expect(err).NotTo(HaveOccurred())
+			o.TestRunner = runner
+			o.SkipCleanup = true
+			list, err := o.Run(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list.Items).To(HaveLen(1))
+			Expect(list.Items[0].Status.Results[0].State).To(Equal(v1alpha3.PassState))
+		})
+	})
+
+	It("reports skipped tests as skipped in JUnit", func() {
+		test := v1alpha3.NewTest()
+		test.Spec = labelledTest("b")
+		test.Status = *skippedStatus(test.Spec, "a")
+		list := v1alpha3.NewTestList()
+		list.Items = append(list.Items, test)
+		report := JUnitReport(list, &Timings{Items: []TestTiming{{Skipped: true}}})
+		Expect(report.Skipped).To(Equal(1))
+		Expect(report.Errors).To(Equal(0))
+	})
+
+	It("reports timed out tests as timeout errors in JUnit", func() {
+		test := v1alpha3.NewTest()
+		test.Spec = labelledTest("a")
+		test.Status = *timedOutStatus(test.Spec, time.Second)
+		list := v1alpha3.NewTestList()
+		list.Items = append(list.Items, test)
+		report := JUnitReport(list, &Timings{Items: []TestTiming{{TimedOut: true}}})
+		Expect(report.Errors).To(Equal(1))
+		Expect(report.Suites[0].TestCases[0].Error.Type).To(Equal("timeout"))
+	})
+})
@@ -16,7 +16,9 @@ package scorecard
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -24,7 +26,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
@@ -42,6 +44,55 @@ type Scorecard struct {
 	Selector    labels.Selector
 	TestRunner  TestRunner
 	SkipCleanup bool
+	// TestOptions are the scheduling options of tests, keyed by "test" label.
+	TestOptions map[string]TestOptions
+	// Timings, if set, is filled in by Run.
+	Timings *Timings
+}
+
+// Timings holds when each test run by Scorecard.Run started, how long it
+// took and how it was scheduled, in the order of the items of the returned
+// TestList.
+type Timings struct {
+	Items []TestTiming
+}
+
+// at returns the timing of the i-th test, which is empty if t is nil or has no such item.
+func (t *Timings) at(i int) TestTiming {
+	if t == nil || i >= len(t.Items) {
+		return TestTiming{}
+	}
+	return t.Items[i]
+}
+
+// TestTiming is the start time and duration of a single test.
+type TestTiming struct {
+	Start    time.Time
+	Duration time.Duration
+	// Attempts is the number of times the test was run, including retries.
+	Attempts int
+	// TimedOut is true if the last attempt exceeded the test timeout.
+	TimedOut bool
+	// Skipped is true if the test was not run because a dependency did not pass.
+	Skipped bool
+}
+
+// outcome returns "skipped" or "timed out" for a test that was skipped or timed
+// out, and an empty string otherwise.
+func (t TestTiming) outcome() string {
+	switch {
+	case t.Skipped:
+		return "skipped"
+	case t.TimedOut:
+		return "timed out"
+	}
+	return ""
+}
+
+// timedTest is a test result along with its timing.
+type timedTest struct {
+	test   v1alpha3.Test
+	timing TestTiming
 }
 
 type PodTestRunner struct {
@@ -52,8 +103,29 @@ type PodTestRunner struct {
 	BundleMetadata registryutil.Labels
 	Client         kubernetes.Interface
 	RESTConfig     *rest.Config
-
+	// PodOptions customize the pods of all tests.
+	PodOptions PodOptions
+	// TestPodOptions customize the pods of single tests, keyed by "test" label,
+	// overriding PodOptions.
+	TestPodOptions map[string]PodOptions
+	// LogOutput, if set, receives the logs of test pods as they are written,
+	// each line prefixed with the name of the test.
+	LogOutput io.Writer
+	// BundleTransfer is how the bundle is made available to test pods, chosen
+	// by the size of the bundle if unset.
+	BundleTransfer BundleTransfer
+	// BundleImage is the image the bundle was pulled from, if any.
+	BundleImage string
+	// ArtifactsSizeLimit is the size limit of the files collected from tests
+	// with the artifacts label, DefaultArtifactsSizeLimit if unset.
+	ArtifactsSizeLimit int64
+
+	// configMapName identifies the resources of a test run, and names the
+	// bundle ConfigMap, or the first of them.
 	configMapName string
+	transfer      bundleTransferState
+	watcher       *podWatcher
+	logMu         *sync.Mutex
 }
 
 type FakeTestRunner struct {
@@ -73,21 +145,32 @@ func (o Scorecard) Run(ctx context.Context) (testOutput v1alpha3.TestList, err e
 		return testOutput, err
 	}
 
+	var names []string
+	for _, stage := range o.Config.Stages {
+		for _, test := range o.selectTests(stage) {
+			names = append(names, test.Labels["test"])
+		}
+	}
+	outcomes := newTestOutcomes(names)
+
 	for _, stage := range o.Config.Stages {
 		tests := o.selectTests(stage)
 		if len(tests) == 0 {
 			continue
 		}
 
-		output := make(chan v1alpha3.Test, len(tests))
+		output := make(chan timedTest, len(tests))
 		if stage.Parallel {
-			o.runStageParallel(ctx, tests, output)
+			o.runStageParallel(ctx, tests, output, outcomes)
 		} else {
-			o.runStageSequential(ctx, tests, output)
+			o.runStageSequential(ctx, tests, output, outcomes)
 		}
 		close(output)
-		for o := range output {
-			testOutput.Items = append(testOutput.Items, o)
+		for t := range output {
+			testOutput.Items = append(testOutput.Items, t.test)
+			if o.Timings != nil {
+				o.Timings.Items = append(o.Timings.Items, t.timing)
+			}
 		}
 	}
 
@@ -110,36 +193,26 @@ func (o Scorecard) Run(ctx context.Context) (testOutput v1alpha3.TestList, err e
 	return testOutput, err
 }
 
-func (o Scorecard) runStageParallel(ctx context.Context, tests []v1alpha3.TestConfiguration, results chan<- v1alpha3.Test) {
+func (o Scorecard) runStageParallel(ctx context.Context, tests []v1alpha3.TestConfiguration, results chan<- timedTest,
+	outcomes *testOutcomes) {
 	var wg sync.WaitGroup
 	for _, t := range tests {
 		wg.Add(1)
 		go func(test v1alpha3.TestConfiguration) {
-			results <- o.runTest(ctx, test)
+			results <- o.runTest(ctx, test, outcomes)
 			wg.Done()
 		}(t)
 	}
 	wg.Wait()
 }
 
-func (o Scorecard) runStageSequential(ctx context.Context, tests []v1alpha3.TestConfiguration, results chan<- v1alpha3.Test) {
+func (o Scorecard) runStageSequential(ctx context.Context, tests []v1alpha3.TestConfiguration, results chan<- timedTest,
+	outcomes *testOutcomes) {
 	for _, test := range tests {
-		results <- o.runTest(ctx, test)
+		results <- o.runTest(ctx, test, outcomes)
 	}
 }
 
-func (o Scorecard) runTest(ctx context.Context, test v1alpha3.TestConfiguration) v1alpha3.Test {
-	result, err := o.TestRunner.RunTest(ctx, test)
-	if err != nil {
-		result = convertErrorToStatus(err, "")
-	}
-
-	out := v1alpha3.NewTest()
-	out.Spec = test
-	out.Status = *result
-	return out
-}
-
 // selectTests applies an optionally passed selector expression
 // against the configured set of tests, returning the selected tests
 func (o *Scorecard) selectTests(stage v1alpha3.StageConfiguration) []v1alpha3.TestConfiguration {
@@ -162,17 +235,23 @@ func (r FakeTestRunner) Initialize(ctx context.Context) error {
 	}
 }
 
-// Initialize sets up the bundle configmap for tests
+// Initialize makes the bundle available to tests
 func (r *PodTestRunner) Initialize(ctx context.Context) error {
 	bundleData, err := r.getBundleData()
 	if err != nil {
 		return fmt.Errorf("error getting bundle data %w", err)
 	}
 
-	r.configMapName, err = r.CreateConfigMap(ctx, bundleData)
+	r.configMapName = fmt.Sprintf("scorecard-test-%s", rand.String(4))
+	if err := r.transferBundle(ctx, bundleData); err != nil {
+		return err
+	}
+
+	r.watcher, err = newPodWatcher(ctx, r.Client, r.Namespace, r.configMapName)
 	if err != nil {
-		return fmt.Errorf("error creating ConfigMap %w", err)
+		return err
 	}
+	r.logMu = &sync.Mutex{}
 
 	return nil
 
@@ -187,14 +266,17 @@ func (r FakeTestRunner) Cleanup(ctx context.Context) error {
 	}
 }
 
-// Cleanup deletes pods and configmap resources from this test run
+// Cleanup deletes pods, configmaps and PVCs from this test run
 func (r PodTestRunner) Cleanup(ctx context.Context) (err error) {
+	if r.watcher != nil {
+		r.watcher.Stop()
+	}
 
 	err = r.deletePods(ctx, r.configMapName)
 	if err != nil {
 		return err
 	}
-	err = r.deleteConfigMap(ctx, r.configMapName)
+	err = r.deleteConfigMaps(ctx)
 	if err != nil {
 		return err
 	}
@@ -222,12 +304,27 @@ func (r PodTestRunner) RunTest(ctx context.Context, test v1alpha3.TestConfigurat
 		addStorageToPod(podDef, pvcName)
 	}
 
+	if test.Labels[ARTIFACTS_LABEL] == "true" {
+		opts := r.PodOptions.Merge(r.TestPodOptions[test.Labels["test"]])
+		if err := addArtifactsToPod(podDef, opts, r.artifactsSizeLimit()); err != nil {
+			return nil, err
+		}
+	}
+
 	pod, err := r.Client.CoreV1().Pods(r.Namespace).Create(ctx, podDef, metav1.CreateOptions{})
 	if err != nil {
 		return nil, err
 	}
 
-	err = r.waitForTestToComplete(ctx, pod)
+	err = r.waitForTestToComplete(ctx, pod, testName(test))
+	var failed *PodFailedError
+	if errors.As(err, &failed) {
+		// The test could not run, so it has no results to read.
+		status := convertErrorToStatus(err, "")
+		status.Results[0].Name = testName(test)
+		status.Results[0].State = v1alpha3.ErrorState
+		return status, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -240,7 +337,28 @@ func (r PodTestRunner) RunTest(ctx context.Context, test v1alpha3.TestConfigurat
 		}
 	}
 
-	return r.getTestStatus(ctx, pod), nil
+	status, artifacts, err := r.getTestStatus(ctx, pod)
+	if test.Labels[ARTIFACTS_LABEL] == "true" {
+		if err == nil && artifacts == nil {
+			err = errors.New("the test log has no test output")
+		}
+		if err == nil {
+			destPath := getDestPath(r.TestOutput, test.Labels["suite"], test.Labels["test"])
+			err = extractArtifacts(artifacts, destPath, r.artifactsSizeLimit())
+		}
+		if err != nil && status != nil && len(status.Results) > 0 {
+			// The test result stands, but is missing its output.
+			status.Results[0].Log += fmt.Sprintf("error collecting test output: %v\n", err)
+		}
+	}
+	return status, nil
+}
+
+func (r PodTestRunner) artifactsSizeLimit() int64 {
+	if r.ArtifactsSizeLimit > 0 {
+		return r.ArtifactsSizeLimit
+	}
+	return DefaultArtifactsSizeLimit
 }
 
 // RunTest executes a single test
@@ -257,25 +375,35 @@ func ConfigDocLink() string {
 	return "https://sdk.operatorframework.io/docs/scorecard/"
 }
 
-// waitForTestToComplete waits for a fixed amount of time while
-// checking for a test pod to complete
-func (r PodTestRunner) waitForTestToComplete(ctx context.Context, p *v1.Pod) (err error) {
+// waitForTestToComplete waits for a test pod to complete, streaming its logs to
+// LogOutput once its test container starts if LogOutput is set.
+func (r PodTestRunner) waitForTestToComplete(ctx context.Context, p *v1.Pod, name string) error {
+	if r.watcher == nil {
+		return errors.New("test runner is not initialized")
+	}
 
-	podCheck := wait.ConditionFunc(func() (done bool, err error) {
-		var tmp *v1.Pod
-		tmp, err = r.Client.CoreV1().Pods(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
-		if err != nil {
-			return true, fmt.Errorf("error getting pod %s %w", p.Name, err)
+	var streaming sync.WaitGroup
+	defer streaming.Wait()
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	started := false
+	onChange := func(pod *v1.Pod) {
+		if r.LogOutput == nil || started || !containerStarted(pod, testContainerName) {
+			return
 		}
-		if tmp.Status.Phase == v1.PodSucceeded || tmp.Status.Phase == v1.PodFailed {
-			return true, nil
-		}
-		return false, nil
-	})
+		started = true
+		streaming.Add(1)
+		go func() {
+			defer streaming.Done()
+			streamPodLogs(streamCtx, r.Client, pod, testContainerName, name, prefixWriter{mu: r.logMu, out: r.LogOutput})
+		}()
+	}
 
-	err = wait.PollImmediateUntil(1*time.Second, podCheck, ctx.Done())
+	err := r.watcher.waitForCompletion(ctx, p.Namespace, p.Name, onChange)
+	if err != nil {
+		cancel()
+	}
 	return err
-
 }
 
 func convertErrorToStatus(err error, log string) *v1alpha3.TestStatus {
@@ -54,10 +54,7 @@ func (r PodTestRunner) createStorage(ctx context.Context, labels map[string]stri
 	pvcName = fmt.Sprintf("scorecard-pvc-%s", rand.String(4))
 	accessModeEntered := labels[STORAGE_ACCESSMODE_LABEL]
 
-	pvcSize := STORAGE_SIZE_DEFAULT
-	if pvcSize != "" {
-		pvcSize = labels[STORAGE_SIZE_LABEL]
-	}
+	pvcSize := labels[STORAGE_SIZE_LABEL]
 	storageClassName, err := r.findDefaultStorageClassName()
 	if err != nil {
 		return "", err
@@ -242,10 +239,12 @@ func (r PodTestRunner) getPVCDefinition(configMapName, pvcName, pvcSize, storage
 		return nil, errors.New("invalid storage accessmode, valid values are: ReadOnlyMany, ReadWriteMany, ReadWriteOnce")
 	}
 
-	qtyString := STORAGE_SIZE_DEFAULT
-	q, err := resource.ParseQuantity(qtyString)
+	if pvcSize == "" {
+		pvcSize = STORAGE_SIZE_DEFAULT
+	}
+	q, err := resource.ParseQuantity(pvcSize)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid storage size %q: %w", pvcSize, err)
 	}
 
 	resources := v1.ResourceRequirements{}
@@ -20,45 +20,54 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/rand"
 )
 
-// CreateConfigMap creates a ConfigMap that will hold the bundle
-// contents to be mounted into the test Pods
-func (r PodTestRunner) CreateConfigMap(ctx context.Context, bundleData []byte) (configMapName string, err error) {
-	cfg := getConfigMapDefinition(r.Namespace, bundleData)
-	configMap, err := r.Client.CoreV1().ConfigMaps(r.Namespace).Create(ctx, cfg, metav1.CreateOptions{})
-	if err != nil {
-		return configMapName, err
+// createConfigMaps creates the ConfigMaps that will hold the bundle
+// contents to be mounted into the test Pods, one per chunk of bundleData.
+// The first one is named prefix, and the others prefix-<index>
+func (r PodTestRunner) createConfigMaps(ctx context.Context, prefix string, chunks [][]byte) (configMapNames []string, err error) {
+	for i, chunk := range chunks {
+		name := prefix
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d", prefix, i)
+		}
+		cfg := getConfigMapDefinition(r.Namespace, name, r.configMapName, chunkKey(i, len(chunks)), chunk)
+		configMap, err := r.Client.CoreV1().ConfigMaps(r.Namespace).Create(ctx, cfg, metav1.CreateOptions{})
+		if err != nil {
+			return configMapNames, err
+		}
+		configMapNames = append(configMapNames, configMap.Name)
 	}
-	return configMap.Name, nil
+	return configMapNames, nil
 }
 
 // getConfigMapDefinition returns a ConfigMap definition that
-// will hold the bundle contents and eventually will be mounted
-// into each test Pod
-func getConfigMapDefinition(namespace string, bundleData []byte) *v1.ConfigMap {
-	configMapName := fmt.Sprintf("scorecard-test-%s", rand.String(4))
+// will hold the bundle contents, or a part of them, and eventually
+// will be mounted into each test Pod
+func getConfigMapDefinition(namespace, name, testrun, key string, bundleData []byte) *v1.ConfigMap {
 	data := make(map[string][]byte)
-	data["bundle.tar.gz"] = bundleData
+	data[key] = bundleData
 	return &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      configMapName,
+			Name:      name,
 			Namespace: namespace,
 			Labels: map[string]string{
-				"app": "scorecard-test",
+				"app":     "scorecard-test",
+				"testrun": testrun,
 			},
 		},
 		BinaryData: data,
 	}
 }
 
-// deleteConfigMap deletes the test bundle ConfigMap and is called
+// deleteConfigMaps deletes the test bundle ConfigMaps and is called
 // as part of the test run cleanup
-func (r PodTestRunner) deleteConfigMap(ctx context.Context, configMapName string) error {
-	err := r.Client.CoreV1().ConfigMaps(r.Namespace).Delete(ctx, configMapName, metav1.DeleteOptions{})
+func (r PodTestRunner) deleteConfigMaps(ctx context.Context) error {
+	selector := fmt.Sprintf("testrun=%s", r.configMapName)
+	lo := metav1.ListOptions{LabelSelector: selector}
+	err := r.Client.CoreV1().ConfigMaps(r.Namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, lo)
 	if err != nil {
-		return fmt.Errorf("error deleting configMap %s %w", configMapName, err)
+		return fmt.Errorf("error deleting configMaps (label selector %q): %w", selector, err)
 	}
 	return nil
 }
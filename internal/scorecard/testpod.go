@@ -34,13 +34,16 @@ const (
 	// The image used to untar bundles prior to running tests within a runner Pod.
 	// This image tag should always be pinned to a specific version.
 	scorecardUntarImage = "docker.io/busybox:1.33.0"
+
+	// testContainerName is the name of the container running the test in a test pod.
+	testContainerName = "scorecard-test"
 )
 
 // getPodDefinition fills out a Pod definition based on
 // information from the test
 func getPodDefinition(configMapName string, test v1alpha3.TestConfiguration, r PodTestRunner) *v1.Pod {
-
-	return &v1.Pod{
+	opts := r.PodOptions.Merge(r.TestPodOptions[test.Labels["test"]])
+	pod := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("scorecard-test-%s", rand.String(4)),
 			Namespace: r.Namespace,
@@ -54,7 +57,7 @@ func getPodDefinition(configMapName string, test v1alpha3.TestConfiguration, r P
 			RestartPolicy:      v1.RestartPolicyNever,
 			Containers: []v1.Container{
 				{
-					Name:            "scorecard-test",
+					Name:            testContainerName,
 					Image:           test.Image,
 					ImagePullPolicy: v1.PullIfNotPresent,
 					Command:         test.Entrypoint,
@@ -80,7 +83,7 @@ func getPodDefinition(configMapName string, test v1alpha3.TestConfiguration, r P
 			InitContainers: []v1.Container{
 				{
 					Name:            "scorecard-untar",
-					Image:           scorecardUntarImage,
+					Image:           opts.untarImage(),
 					ImagePullPolicy: v1.PullIfNotPresent,
 					Args: []string{
 						"tar",
@@ -123,6 +126,35 @@ func getPodDefinition(configMapName string, test v1alpha3.TestConfiguration, r P
 			},
 		},
 	}
+	r.transfer.applyBundleTransfer(pod, opts)
+	applyPodOptions(pod, opts)
+	return pod
+}
+
+// applyPodOptions sets the scheduling, security and resource fields of pod from opts.
+func applyPodOptions(pod *v1.Pod, opts PodOptions) {
+	pod.Spec.NodeSelector = opts.NodeSelector
+	pod.Spec.Tolerations = opts.Tolerations
+	for _, name := range opts.ImagePullSecrets {
+		pod.Spec.ImagePullSecrets = append(pod.Spec.ImagePullSecrets, v1.LocalObjectReference{Name: name})
+	}
+	if opts.PodSecurityContext != nil {
+		pod.Spec.SecurityContext = opts.PodSecurityContext.DeepCopy()
+	}
+	for i := range pod.Spec.InitContainers {
+		if opts.Resources != nil {
+			pod.Spec.InitContainers[i].Resources = *opts.Resources.DeepCopy()
+		}
+		pod.Spec.InitContainers[i].SecurityContext = opts.initSecurityContext()
+	}
+	for i := range pod.Spec.Containers {
+		if opts.Resources != nil {
+			pod.Spec.Containers[i].Resources = *opts.Resources.DeepCopy()
+		}
+		if opts.SecurityContext != nil {
+			pod.Spec.Containers[i].SecurityContext = opts.SecurityContext.DeepCopy()
+		}
+	}
 }
 
 // getPodLog fetches the test results which are found in the pod log
@@ -0,0 +1,577 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	BasicCRReconcileTest     = "basic-cr-reconcile"
+	BasicCRCleanupTest       = "basic-cr-cleanup"
+	BasicOperatorRestartTest = "basic-operator-restart"
+
+	// conditionsXDescriptor marks a status descriptor holding the conditions of a CR.
+	conditionsXDescriptor = "urn:alm:descriptor:io.kubernetes.conditions"
+	// DefaultFunctionalTimeout is the time the functional tests wait for each step,
+	// such as a CR being reconciled or deleted, if no timeout is set.
+	DefaultFunctionalTimeout = 2 * time.Minute
+)
+
+// readyConditionTypes are the condition types that mark a CR as reconciled
+// when they have status True.
+var readyConditionTypes = []string{"Ready", "Available", "Successful", "Running"}
+
+// pollInterval is the interval between checks of the cluster while waiting.
+var pollInterval = 2 * time.Second
+
+// Cluster is the cluster and namespace the functional tests run in.
+type Cluster struct {
+	Client    client.Client
+	Discovery discovery.DiscoveryInterface
+	// Namespace is where the CRs are created and the operator is deployed.
+	Namespace string
+	// Timeout is the time waited for each step of a test.
+	Timeout time.Duration
+}
+
+// NewCluster returns a Cluster for cfg. Tests create their CRs in namespace.
+func NewCluster(cfg *rest.Config, namespace string) (*Cluster, error) {
+	c, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return nil, err
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Cluster{Client: c, Discovery: dc, Namespace: namespace, Timeout: DefaultFunctionalTimeout}, nil
+}
+
+// FunctionalNames returns the names of the built-in tests that need a cluster.
+func FunctionalNames() []string {
+	return []string{
+		BasicCRReconcileTest,
+		BasicCRCleanupTest,
+		BasicOperatorRestartTest,
+	}
+}
+
+// IsFunctional returns true if name is a built-in test that needs a cluster.
+func IsFunctional(name string) bool {
+	return sets.NewString(FunctionalNames()...).Has(name)
+}
+
+// RunFunctional runs the built-in functional test named name against the
+// operator of bundle deployed in cluster. It returns false if name is not a
+// functional test.
+func RunFunctional(ctx context.Context, name string, cluster *Cluster,
+	bundle *apimanifests.Bundle) (scapiv1alpha3.TestStatus, bool) {
+	switch name {
+	case BasicCRReconcileTest:
+		return CRReconcileTest(ctx, cluster, bundle), true
+	case BasicCRCleanupTest:
+		return CRCleanupTest(ctx, cluster, bundle), true
+	case BasicOperatorRestartTest:
+		return OperatorRestartTest(ctx, cluster, bundle), true
+	}
+	return scapiv1alpha3.TestStatus{}, false
+}
+
+// CRReconcileTest creates each CR of alm-examples and verifies that the
+// operator reconciles it, as told by its status.
+func CRReconcileTest(ctx context.Context, cluster *Cluster, bundle *apimanifests.Bundle) scapiv1alpha3.TestStatus {
+	r := newFunctionalResult(BasicCRReconcileTest)
+	forEachCR(ctx, cluster, bundle, &r, func(cr *unstructured.Unstructured) error {
+		return cluster.waitForReconcile(ctx, cr, statusDescriptorsFor(bundle.CSV, cr), &r)
+	})
+	return wrapResult(r)
+}
+
+// CRCleanupTest creates each CR of alm-examples and verifies that the resources
+// it owns are deleted with it once it has been reconciled.
+func CRCleanupTest(ctx context.Context, cluster *Cluster, bundle *apimanifests.Bundle) scapiv1alpha3.TestStatus {
+	r := newFunctionalResult(BasicCRCleanupTest)
+	forEachCR(ctx, cluster, bundle, &r, func(cr *unstructured.Unstructured) error {
+		if err := cluster.waitForReconcile(ctx, cr, statusDescriptorsFor(bundle.CSV, cr), &r); err != nil {
+			return err
+		}
+		owned, unlisted, err := cluster.ownedResources(ctx, cr)
+		if err != nil {
+			return err
+		}
+		if len(unlisted) != 0 {
+			r.Suggestions = append(r.Suggestions, fmt.Sprintf("Grant the scorecard service account list "+
+				"permissions on %s to verify the cleanup of all resources owned by %s",
+				strings.Join(unlisted, ", "), crString(cr)))
+		}
+		if len(owned) == 0 {
+			r.Suggestions = append(r.Suggestions, fmt.Sprintf("%s owns no resources with an owner reference to it, "+
+				"its cleanup could not be verified", crString(cr)))
+		}
+		r.Log += fmt.Sprintf("%s owns %d resources\n", crString(cr), len(owned))
+		if err := cluster.deleteCR(ctx, cr); err != nil {
+			return err
+		}
+		return cluster.waitForDeletion(ctx, owned)
+	})
+	return wrapResult(r)
+}
+
+// OperatorRestartTest creates each CR of alm-examples, deletes the operator's
+// pods while the CR is being reconciled, and verifies that the restarted
+// operator completes the reconcile.
+func OperatorRestartTest(ctx context.Context, cluster *Cluster, bundle *apimanifests.Bundle) scapiv1alpha3.TestStatus {
+	r := newFunctionalResult(BasicOperatorRestartTest)
+	deployments := operatorDeployments(bundle.CSV)
+	if len(deployments) == 0 {
+		r.State = scapiv1alpha3.FailState
+		r.Errors = append(r.Errors, "ClusterServiceVersion does not have any deployments")
+		return wrapResult(r)
+	}
+	forEachCR(ctx, cluster, bundle, &r, func(cr *unstructured.Unstructured) error {
+		descriptors := statusDescriptorsFor(bundle.CSV, cr)
+		reconciled, err := cluster.waitForReconcileStart(ctx, cr, descriptors)
+		if err != nil {
+			return err
+		}
+		deleted, err := cluster.deleteOperatorPods(ctx, deployments)
+		if err != nil {
+			return err
+		}
+		if reconciled {
+			r.Suggestions = append(r.Suggestions, fmt.Sprintf("%s was reconciled before the operator pods were "+
+				"deleted, so recovery from an interrupted reconcile was not verified", crString(cr)))
+			r.Log += fmt.Sprintf("Deleted %d operator pods after reconciling %s\n", len(deleted), crString(cr))
+		} else {
+			r.Log += fmt.Sprintf("Deleted %d operator pods while reconciling %s\n", len(deleted), crString(cr))
+		}
+		if err := cluster.waitForRollout(ctx, deployments, deleted); err != nil {
+			return err
+		}
+		return cluster.waitForReconcile(ctx, cr, descriptors, &r)
+	})
+	return wrapResult(r)
+}
+
+func newFunctionalResult(name string) scapiv1alpha3.TestResult {
+	return scapiv1alpha3.TestResult{
+		Name:        name,
+		State:       scapiv1alpha3.PassState,
+		Errors:      make([]string, 0),
+		Suggestions: make([]string, 0),
+	}
+}
+
+// forEachCR creates each CR of alm-examples in cluster, runs check against it
+// and deletes it. The result fails for each CR whose creation or check fails.
+func forEachCR(ctx context.Context, cluster *Cluster, bundle *apimanifests.Bundle, r *scapiv1alpha3.TestResult,
+	check func(cr *unstructured.Unstructured) error) {
+	crs, err := GetCRs(bundle)
+	if err != nil {
+		r.State = scapiv1alpha3.ErrorState
+		r.Errors = append(r.Errors, err.Error())
+		return
+	}
+	if len(crs) == 0 {
+		r.Suggestions = append(r.Suggestions, "Add example CRs to the alm-examples annotation of the "+
+			"ClusterServiceVersion to test that they are reconciled")
+		return
+	}
+
+	for i := range crs {
+		cr := &crs[i]
+		if err := cluster.createCR(ctx, cr); err != nil {
+			r.State = scapiv1alpha3.FailState
+			r.Errors = append(r.Errors, fmt.Sprintf("error creating %s: %v", crString(cr), err))
+			continue
+		}
+		r.Log += fmt.Sprintf("Created %s\n", crString(cr))
+		if err := check(cr); err != nil {
+			r.State = scapiv1alpha3.FailState
+			r.Errors = append(r.Errors, err.Error())
+		}
+		// Clean up even if the test ran out of time.
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), cluster.timeout())
+		if err := cluster.deleteCR(cleanupCtx, cr); err != nil {
+			r.Log += fmt.Sprintf("Error deleting %s: %v\n", crString(cr), err)
+		}
+		cancel()
+	}
+}
+
+func (c *Cluster) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultFunctionalTimeout
+	}
+	return c.Timeout
+}
+
+// poll calls condition until it returns true, an error, or the timeout of c or
+// ctx expire.
+func (c *Cluster) poll(ctx context.Context, condition wait.ConditionFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+	return wait.PollImmediateUntil(pollInterval, condition, ctx.Done())
+}
+
+// createCR creates cr without its status in the namespace of c, unless its kind
+// is cluster-scoped.
+func (c *Cluster) createCR(ctx context.Context, cr *unstructured.Unstructured) error {
+	gvk := cr.GroupVersionKind()
+	mapping, err := c.Client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		cr.SetNamespace(c.Namespace)
+	} else {
+		cr.SetNamespace("")
+	}
+	cr.SetResourceVersion("")
+	cr.SetUID("")
+	// A status in alm-examples must not be mistaken for the operator's.
+	unstructured.RemoveNestedField(cr.Object, "status")
+	return c.Client.Create(ctx, cr)
+}
+
+// deleteCR deletes cr and waits for it to be gone, including its finalizers.
+func (c *Cluster) deleteCR(ctx context.Context, cr *unstructured.Unstructured) error {
+	err := c.Client.Delete(ctx, cr, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	err = c.poll(ctx, func() (bool, error) {
+		err := c.Client.Get(ctx, client.ObjectKeyFromObject(cr), cr.DeepCopy())
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("%s was not deleted, check its finalizers", crString(cr))
+	}
+	return err
+}
+
+// waitForReconcile waits until the status of cr shows that it has been reconciled.
+func (c *Cluster) waitForReconcile(ctx context.Context, cr *unstructured.Unstructured,
+	descriptors []operatorsv1alpha1.StatusDescriptor, r *scapiv1alpha3.TestResult) error {
+	reason := ""
+	err := c.poll(ctx, func() (bool, error) {
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(cr.GroupVersionKind())
+		if err := c.Client.Get(ctx, client.ObjectKeyFromObject(cr), current); err != nil {
+			return false, err
+		}
+		var done bool
+		done, reason = crReconciled(current, descriptors)
+		return done, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("%s was not reconciled: %s", crString(cr), reason)
+	}
+	if err == nil {
+		r.Log += fmt.Sprintf("%s was reconciled\n", crString(cr))
+	}
+	return err
+}
+
+// waitForReconcileStart waits until the operator has started to reconcile cr,
+// as told by a status or finalizers being set, and returns true if cr is
+// already reconciled by then.
+func (c *Cluster) waitForReconcileStart(ctx context.Context, cr *unstructured.Unstructured,
+	descriptors []operatorsv1alpha1.StatusDescriptor) (bool, error) {
+	reconciled := false
+	err := c.poll(ctx, func() (bool, error) {
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(cr.GroupVersionKind())
+		if err := c.Client.Get(ctx, client.ObjectKeyFromObject(cr), current); err != nil {
+			return false, err
+		}
+		reconciled, _ = crReconciled(current, descriptors)
+		status, _ := current.Object["status"].(map[string]interface{})
+		return reconciled || len(status) != 0 || len(current.GetFinalizers()) != 0, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return false, fmt.Errorf("operator did not start to reconcile %s: neither a status nor finalizers were set",
+			crString(cr))
+	}
+	return reconciled, err
+}
+
+// crReconciled returns true if the status of cr shows that it was reconciled,
+// or why not. A status descriptor of conditions requires a ready condition,
+// other status descriptors require their fields to be set, and CRs without
+// status descriptors only require a status.
+func crReconciled(cr *unstructured.Unstructured, descriptors []operatorsv1alpha1.StatusDescriptor) (bool, string) {
+	status, ok := cr.Object["status"].(map[string]interface{})
+	if !ok || len(status) == 0 {
+		return false, "status is not set"
+	}
+	for _, d := range descriptors {
+		if !hasXDescriptor(d, conditionsXDescriptor) {
+			continue
+		}
+		conditions, _, _ := unstructured.NestedSlice(status, descriptorPath(d.Path)...)
+		if hasReadyCondition(conditions) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("status.%s does not have a condition of type %s with status True",
+			d.Path, strings.Join(readyConditionTypes, ", "))
+	}
+	for _, d := range descriptors {
+		if v, found, _ := unstructured.NestedFieldNoCopy(status, descriptorPath(d.Path)...); !found || v == nil {
+			return false, fmt.Sprintf("status.%s is not set", d.Path)
+		}
+	}
+	return true, ""
+}
+
+func hasXDescriptor(d operatorsv1alpha1.StatusDescriptor, xDescriptor string) bool {
+	for _, x := range d.XDescriptors {
+		if x == xDescriptor {
+			return true
+		}
+	}
+	return false
+}
+
+func hasReadyCondition(conditions []interface{}) bool {
+	ready := sets.NewString(readyConditionTypes...)
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if ready.Has(fmt.Sprint(condition["type"])) && fmt.Sprint(condition["status"]) == string(metav1.ConditionTrue) {
+			return true
+		}
+	}
+	return false
+}
+
+// descriptorPath splits the path of a descriptor into fields, ignoring indexes.
+func descriptorPath(path string) []string {
+	fields := []string{}
+	for _, f := range strings.Split(path, ".") {
+		if i := strings.Index(f, "["); i >= 0 {
+			f = f[:i]
+		}
+		if f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// statusDescriptorsFor returns the status descriptors of the owned CRD of cr's kind.
+func statusDescriptorsFor(csv *operatorsv1alpha1.ClusterServiceVersion,
+	cr *unstructured.Unstructured) []operatorsv1alpha1.StatusDescriptor {
+	if csv == nil {
+		return nil
+	}
+	for _, owned := range csv.Spec.CustomResourceDefinitions.Owned {
+		if owned.Kind == cr.GetKind() && owned.Version == cr.GroupVersionKind().Version {
+			return owned.StatusDescriptors
+		}
+	}
+	return nil
+}
+
+// ownedResources returns the namespaced resources of c's namespace with an
+// owner reference to cr, and the resources the test is not allowed to list.
+func (c *Cluster) ownedResources(ctx context.Context,
+	cr *unstructured.Unstructured) (owned []unstructured.Unstructured, unlisted []string, err error) {
+	resourceLists, err := c.Discovery.ServerPreferredNamespacedResources()
+	if err != nil && len(resourceLists) == 0 {
+		return nil, nil, fmt.Errorf("error discovering resources: %v", err)
+	}
+
+	for _, list := range resourceLists {
+		for _, resource := range list.APIResources {
+			if !sets.NewString(resource.Verbs...).Has("list") || strings.Contains(resource.Name, "/") {
+				continue
+			}
+			objs := &unstructured.UnstructuredList{}
+			objs.SetAPIVersion(list.GroupVersion)
+			objs.SetKind(resource.Kind + "List")
+			if err := c.Client.List(ctx, objs, client.InNamespace(c.Namespace)); err != nil {
+				if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+					unlisted = append(unlisted, resource.Name)
+					continue
+				}
+				return nil, nil, fmt.Errorf("error listing %s: %v", resource.Name, err)
+			}
+			for _, obj := range objs.Items {
+				if ownedBy(obj, cr.GetUID()) {
+					owned = append(owned, obj)
+				}
+			}
+		}
+	}
+	sort.Strings(unlisted)
+	return owned, unlisted, nil
+}
+
+func ownedBy(obj unstructured.Unstructured, uid types.UID) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForDeletion waits until each of objs is deleted.
+func (c *Cluster) waitForDeletion(ctx context.Context, objs []unstructured.Unstructured) error {
+	var remaining []string
+	err := c.poll(ctx, func() (bool, error) {
+		remaining = nil
+		for _, obj := range objs {
+			current := &unstructured.Unstructured{}
+			current.SetGroupVersionKind(obj.GroupVersionKind())
+			err := c.Client.Get(ctx, client.ObjectKeyFromObject(&obj), current)
+			switch {
+			case apierrors.IsNotFound(err):
+			case err != nil:
+				return false, err
+			case current.GetUID() == obj.GetUID():
+				remaining = append(remaining, fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName()))
+			}
+		}
+		return len(remaining) == 0, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		sort.Strings(remaining)
+		return fmt.Errorf("owned resources were not deleted with their owner: %s", strings.Join(remaining, ", "))
+	}
+	return err
+}
+
+// operatorDeployments returns the names of the deployments of csv.
+func operatorDeployments(csv *operatorsv1alpha1.ClusterServiceVersion) []string {
+	if csv == nil {
+		return nil
+	}
+	var names []string
+	for _, d := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+// deleteOperatorPods deletes the pods of the named deployments, and returns their UIDs.
+func (c *Cluster) deleteOperatorPods(ctx context.Context, deployments []string) (sets.String, error) {
+	deleted := sets.NewString()
+	for _, name := range deployments {
+		dep := &appsv1.Deployment{}
+		if err := c.Client.Get(ctx, types.NamespacedName{Namespace: c.Namespace, Name: name}, dep); err != nil {
+			return nil, fmt.Errorf("error getting operator deployment %s: %v", name, err)
+		}
+		selector, err := metav1.LabelSelectorAsSelector(dep.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector of operator deployment %s: %v", name, err)
+		}
+		pods := &corev1.PodList{}
+		if err := c.Client.List(ctx, pods, client.InNamespace(c.Namespace),
+			client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, fmt.Errorf("error listing pods of operator deployment %s: %v", name, err)
+		}
+		for i := range pods.Items {
+			if err := c.Client.Delete(ctx, &pods.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("error deleting operator pod %s: %v", pods.Items[i].Name, err)
+			}
+			deleted.Insert(string(pods.Items[i].UID))
+		}
+	}
+	return deleted, nil
+}
+
+// waitForRollout waits until the named deployments are available again, with
+// none of the deleted pods left.
+func (c *Cluster) waitForRollout(ctx context.Context, deployments []string, deleted sets.String) error {
+	reason := ""
+	err := c.poll(ctx, func() (bool, error) {
+		for _, name := range deployments {
+			dep := &appsv1.Deployment{}
+			if err := c.Client.Get(ctx, types.NamespacedName{Namespace: c.Namespace, Name: name}, dep); err != nil {
+				return false, err
+			}
+			replicas := int32(1)
+			if dep.Spec.Replicas != nil {
+				replicas = *dep.Spec.Replicas
+			}
+			if dep.Status.AvailableReplicas < replicas {
+				reason = fmt.Sprintf("deployment %s has %d of %d available replicas", name,
+					dep.Status.AvailableReplicas, replicas)
+				return false, nil
+			}
+		}
+		pods := &corev1.PodList{}
+		if err := c.Client.List(ctx, pods, client.InNamespace(c.Namespace)); err != nil {
+			return false, err
+		}
+		for _, pod := range pods.Items {
+			if deleted.Has(string(pod.UID)) {
+				reason = fmt.Sprintf("deleted operator pod %s is still terminating", pod.Name)
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("operator did not restart: %s", reason)
+	}
+	return err
+}
+
+// WithRESTMapper returns c with the REST mapper mapper, for clients that lack
+// one such as the fake client.
+func WithRESTMapper(c client.Client, mapper meta.RESTMapper) client.Client {
+	return mappedClient{Client: c, mapper: mapper}
+}
+
+type mappedClient struct {
+	client.Client
+	mapper meta.RESTMapper
+}
+
+func (c mappedClient) RESTMapper() meta.RESTMapper { return c.mapper }
+
+func crString(cr *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s %s", cr.GetKind(), cr.GetName())
+}
@@ -0,0 +1,209 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Functional tests", func() {
+	const namespace = "scorecard"
+	memcachedGVK := schema.GroupVersionKind{Group: "cache.example.com", Version: "v1alpha1", Kind: "Memcached"}
+
+	Describe("crReconciled", func() {
+		var cr *unstructured.Unstructured
+
+		BeforeEach(func() {
+			cr = &unstructured.Unstructured{Object: map[string]interface{}{}}
+		})
+
+		It("requires a status", func() {
+			done, reason := crReconciled(cr, nil)
+			Expect(done).To(BeFalse())
+			Expect(reason).To(Equal("status is not set"))
+			cr.Object["status"] = map[string]interface{}{"phase": "Done"}
+			done, _ = crReconciled(cr, nil)
+			Expect(done).To(BeTrue())
+		})
+		It("requires the fields of status descriptors to be set", func() {
+			descriptors := []operatorsv1alpha1.StatusDescriptor{{Path: "nodes"}, {Path: "db.endpoint"}}
+			cr.Object["status"] = map[string]interface{}{"nodes": []interface{}{"a"}}
+			done, reason := crReconciled(cr, descriptors)
+			Expect(done).To(BeFalse())
+			Expect(reason).To(Equal("status.db.endpoint is not set"))
+			cr.Object["status"].(map[string]interface{})["db"] = map[string]interface{}{"endpoint": "x"}
+			done, _ = crReconciled(cr, descriptors)
+			Expect(done).To(BeTrue())
+		})
+		It("requires a ready condition if a status descriptor holds conditions", func() {
+			descriptors := []operatorsv1alpha1.StatusDescriptor{
+				{Path: "nodes"},
+				{Path: "conditions", XDescriptors: []string{conditionsXDescriptor}},
+			}
+			cr.Object["status"] = map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False"},
+			}}
+			done, _ := crReconciled(cr, descriptors)
+			Expect(done).To(BeFalse())
+			cr.Object["status"] = map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "True"},
+			}}
+			done, _ = crReconciled(cr, descriptors)
+			Expect(done).To(BeTrue())
+		})
+	})
+
+	Describe("descriptorPath", func() {
+		It("splits paths and ignores indexes", func() {
+			Expect(descriptorPath("a.b[0].c")).To(Equal([]string{"a", "b", "c"}))
+		})
+	})
+
+	Context("with a cluster", func() {
+		var (
+			bundle  *apimanifests.Bundle
+			cluster *Cluster
+			c       client.Client
+			cancel  context.CancelFunc
+			ctx     context.Context
+
+			defaultPollInterval = pollInterval
+		)
+
+		BeforeEach(func() {
+			var err error
+			bundle, err = apimanifests.GetBundleFromDir(filepath.Join("..", "testdata", "bundle"))
+			Expect(err).NotTo(HaveOccurred())
+
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			scheme.AddKnownTypeWithName(memcachedGVK, &unstructured.Unstructured{})
+			scheme.AddKnownTypeWithName(memcachedGVK.GroupVersion().WithKind("MemcachedList"),
+				&unstructured.UnstructuredList{})
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(memcachedGVK, meta.RESTScopeNamespace)
+
+			replicas := int32(1)
+			c = WithRESTMapper(fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "memcached-operator", Namespace: namespace},
+					Spec: appsv1.DeploymentSpec{
+						Replicas: &replicas,
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "memcached-operator"}},
+					},
+					Status: appsv1.DeploymentStatus{AvailableReplicas: 1},
+				},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+					Name: "memcached-operator-1", Namespace: namespace, UID: "pod-uid",
+					Labels: map[string]string{"name": "memcached-operator"},
+				}},
+			).Build(), mapper)
+			cluster = &Cluster{Client: c, Namespace: namespace, Timeout: time.Second}
+			pollInterval = 10 * time.Millisecond
+			ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		})
+		AfterEach(func() {
+			cancel()
+			pollInterval = defaultPollInterval
+		})
+
+		// reconcile sets the status of the example CR once it exists, like the operator would.
+		reconcile := func() {
+			go func() {
+				defer GinkgoRecover()
+				for ctx.Err() == nil {
+					cr := &unstructured.Unstructured{}
+					cr.SetGroupVersionKind(memcachedGVK)
+					key := client.ObjectKey{Namespace: namespace, Name: "example-memcached"}
+					if err := c.Get(ctx, key, cr); err == nil {
+						Expect(unstructured.SetNestedStringSlice(cr.Object, []string{"a"}, "status", "nodes")).To(Succeed())
+						if err := c.Update(ctx, cr); err == nil {
+							return
+						}
+					}
+					time.Sleep(5 * time.Millisecond)
+				}
+			}()
+		}
+
+		It("passes when the example CRs are reconciled", func() {
+			reconcile()
+			status := CRReconcileTest(ctx, cluster, bundle)
+			Expect(status.Results).To(HaveLen(1))
+			Expect(status.Results[0].Errors).To(BeEmpty())
+			Expect(status.Results[0].State).To(Equal(scapiv1alpha3.PassState))
+
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(memcachedGVK.GroupVersion().WithKind("MemcachedList"))
+			Expect(c.List(ctx, list)).To(Succeed())
+			Expect(list.Items).To(BeEmpty())
+		})
+		It("fails when the example CRs are not reconciled", func() {
+			status := CRReconcileTest(ctx, cluster, bundle)
+			Expect(status.Results[0].State).To(Equal(scapiv1alpha3.FailState))
+			Expect(status.Results[0].Errors).To(ConsistOf(
+				"Memcached example-memcached was not reconciled: status is not set"))
+		})
+		It("restarts the operator and waits for the CRs to be reconciled", func() {
+			reconcile()
+			status := OperatorRestartTest(ctx, cluster, bundle)
+			Expect(status.Results[0].Errors).To(BeEmpty())
+			Expect(status.Results[0].State).To(Equal(scapiv1alpha3.PassState))
+			Expect(status.Results[0].Suggestions).To(ConsistOf(ContainSubstring(
+				"was reconciled before the operator pods were deleted")))
+
+			pods := &corev1.PodList{}
+			Expect(c.List(ctx, pods)).To(Succeed())
+			Expect(pods.Items).To(BeEmpty())
+		})
+		It("does not restart the operator before it starts to reconcile the CRs", func() {
+			status := OperatorRestartTest(ctx, cluster, bundle)
+			Expect(status.Results[0].State).To(Equal(scapiv1alpha3.FailState))
+			Expect(status.Results[0].Errors).To(ConsistOf(
+				"operator did not start to reconcile Memcached example-memcached: neither a status nor finalizers were set"))
+
+			pods := &corev1.PodList{}
+			Expect(c.List(ctx, pods)).To(Succeed())
+			Expect(pods.Items).To(HaveLen(1))
+		})
+	})
+
+	It("dispatches functional tests by name", func() {
+		for _, name := range FunctionalNames() {
+			Expect(IsFunctional(name)).To(BeTrue())
+			_, ok := Run(name, "", nil, nil)
+			Expect(ok).To(BeFalse())
+		}
+		Expect(IsFunctional(BasicCheckSpecTest)).To(BeFalse())
+	})
+})
@@ -0,0 +1,57 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+// Names returns the names of the built-in tests, as passed to the scorecard-test image,
+// including the functional tests.
+func Names() []string {
+	return append([]string{
+		OLMBundleValidationTest,
+		OLMCRDsHaveValidationTest,
+		OLMCRDsHaveResourcesTest,
+		OLMSpecDescriptorsTest,
+		OLMStatusDescriptorsTest,
+		BasicCheckSpecTest,
+	}, FunctionalNames()...)
+}
+
+// Run runs the built-in test named name against bundle, which was read from
+// bundleRoot. It returns false if name is not a built-in test, or is a
+// functional test, which is run by RunFunctional.
+func Run(name, bundleRoot string, bundle *apimanifests.Bundle,
+	metadata registryutil.Labels) (scapiv1alpha3.TestStatus, bool) {
+	switch name {
+	case OLMBundleValidationTest:
+		return BundleValidationTest(bundleRoot, metadata), true
+	case OLMCRDsHaveValidationTest:
+		return CRDsHaveValidationTest(bundle), true
+	case OLMCRDsHaveResourcesTest:
+		return CRDsHaveResourcesTest(bundle), true
+	case OLMSpecDescriptorsTest:
+		return SpecDescriptorsTest(bundle), true
+	case OLMStatusDescriptorsTest:
+		return StatusDescriptorsTest(bundle), true
+	case BasicCheckSpecTest:
+		return CheckSpecTest(bundle), true
+	}
+	return scapiv1alpha3.TestStatus{}, false
+}
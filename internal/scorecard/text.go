@@ -0,0 +1,39 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+// TextReport converts test results to the human-readable output of v1alpha3,
+// adding whether a test was skipped or timed out, since v1alpha3 has no states
+// for them. timings may be nil.
+func TextReport(list v1alpha3.TestList, timings *Timings) string {
+	sb := strings.Builder{}
+	for i, test := range list.Items {
+		text := test.MarshalText()
+		if outcome := timings.at(i).outcome(); outcome != "" {
+			// Add the outcome after the separator line starting the text of the test.
+			sep := strings.Index(text, "\n") + 1
+			text = text[:sep] + fmt.Sprintf("Outcome:    %s\n", outcome) + text[sep:]
+		}
+		sb.WriteString(text + "\n")
+	}
+	return sb.String()
+}
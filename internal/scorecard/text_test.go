@@ -0,0 +1,50 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+var _ = Describe("Text reports", func() {
+	It("adds the outcome of skipped and timed out tests", func() {
+		skipped := v1alpha3.NewTest()
+		skipped.Spec = labelledTest("b")
+		skipped.Status = *skippedStatus(skipped.Spec, "a")
+		timedOut := v1alpha3.NewTest()
+		timedOut.Spec = labelledTest("c")
+		timedOut.Status = *timedOutStatus(timedOut.Spec, time.Second)
+		passed := v1alpha3.NewTest()
+		passed.Spec = labelledTest("d")
+		passed.Status.Results = []v1alpha3.TestResult{{State: v1alpha3.PassState}}
+		list := testList(skipped, timedOut, passed)
+
+		text := TextReport(list, &Timings{Items: []TestTiming{{Skipped: true}, {TimedOut: true}, {}}})
+		separator := strings.Repeat("-", 80) + "\n"
+		Expect(text).To(Equal(
+			separator + "Outcome:    skipped\n" + strings.TrimPrefix(skipped.MarshalText(), separator) + "\n" +
+				separator + "Outcome:    timed out\n" + strings.TrimPrefix(timedOut.MarshalText(), separator) + "\n" +
+				passed.MarshalText() + "\n"))
+	})
+	It("prints the v1alpha3 text without timings", func() {
+		test := baselineTest("a", v1alpha3.TestResult{State: v1alpha3.FailState})
+		Expect(TextReport(testList(test), nil)).To(Equal(test.MarshalText() + "\n"))
+	})
+})
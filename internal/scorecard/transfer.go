@@ -0,0 +1,409 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// BundleTransfer is how the bundle under test is made available to test pods.
+type BundleTransfer string
+
+const (
+	// BundleTransferAuto chooses a transfer mode by the size of the bundle.
+	BundleTransferAuto BundleTransfer = "auto"
+	// BundleTransferConfigMap stores the bundle tarball in ConfigMaps, split
+	// across several ConfigMaps if it exceeds the size limit of one.
+	BundleTransferConfigMap BundleTransfer = "configmap"
+	// BundleTransferImage pulls the bundle image in an init container of each test pod.
+	BundleTransferImage BundleTransfer = "image"
+	// BundleTransferPVC unpacks the bundle once into a PVC mounted by all test pods.
+	BundleTransferPVC BundleTransfer = "pvc"
+)
+
+// BundleTransfers are the valid transfer modes.
+var BundleTransfers = []BundleTransfer{BundleTransferAuto, BundleTransferConfigMap, BundleTransferImage, BundleTransferPVC}
+
+// ParseBundleTransfer returns the transfer mode named s.
+func ParseBundleTransfer(s string) (BundleTransfer, error) {
+	for _, t := range BundleTransfers {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("invalid bundle transfer mode %q, valid values are %v", s, BundleTransfers)
+}
+
+const (
+	// The image used to pull bundle images in test pods. It must have a shell.
+	// This image tag should always be pinned to a specific version.
+	scorecardBundlePullerImage = "gcr.io/go-containerregistry/crane:debug"
+
+	// maxConfigMapChunks is the largest number of ConfigMaps the bundle is split
+	// into before auto mode uses another transfer mode.
+	maxConfigMapChunks = 8
+
+	bundleConfigMapKey  = "bundle.tar.gz"
+	bundlePVCSize       = "1Gi"
+	bundleImageEnvVar   = "BUNDLE_IMAGE"
+	dockerConfigMount   = "/scorecard-docker"
+	bundleVolume        = "scorecard-bundle"
+	untarVolume         = "scorecard-untar"
+	populatorContainer  = "scorecard-populate"
+	populatorPodTimeout = 2 * time.Minute
+)
+
+// maxConfigMapData is the size of the bundle data stored in a single ConfigMap,
+// which leaves room for the rest of the object below the 1MiB limit of etcd.
+var maxConfigMapData = 1000 * 1024
+
+// bundleTransferState is the transfer mode chosen by Initialize, and the
+// resources it created.
+type bundleTransferState struct {
+	mode       BundleTransfer
+	image      string
+	configMaps []string
+	pvcName    string
+	// nodeName is the node the PVC was populated on. Test pods run on it, so
+	// that the volume does not need to be mountable on several nodes.
+	nodeName string
+}
+
+// chooseBundleTransfer resolves mode for bundle data of size bytes. Auto mode
+// uses ConfigMaps unless the bundle needs too many of them, then pulls
+// bundleImage if the bundle is an image, or else uses a PVC.
+func chooseBundleTransfer(mode BundleTransfer, size int, bundleImage string) (BundleTransfer, error) {
+	switch mode {
+	case "", BundleTransferAuto:
+		switch {
+		case size <= maxConfigMapChunks*maxConfigMapData:
+			return BundleTransferConfigMap, nil
+		case bundleImage != "":
+			return BundleTransferImage, nil
+		default:
+			return BundleTransferPVC, nil
+		}
+	case BundleTransferImage:
+		if bundleImage == "" {
+			return "", errors.New("the image bundle transfer mode requires a bundle image argument")
+		}
+	}
+	return mode, nil
+}
+
+// splitBundleData splits data in chunks that fit in a ConfigMap.
+func splitBundleData(data []byte) [][]byte {
+	chunks := [][]byte{}
+	for len(data) > maxConfigMapData {
+		chunks = append(chunks, data[:maxConfigMapData])
+		data = data[maxConfigMapData:]
+	}
+	return append(chunks, data)
+}
+
+// chunkKey returns the ConfigMap key of chunk i of n. A single chunk keeps the
+// key of unsplit bundles, and the keys of split bundles sort in order.
+func chunkKey(i, n int) string {
+	if n == 1 {
+		return bundleConfigMapKey
+	}
+	return fmt.Sprintf("%s.%03d", bundleConfigMapKey, i)
+}
+
+// transferBundle makes bundleData available to test pods, as chosen by BundleTransfer.
+func (r *PodTestRunner) transferBundle(ctx context.Context, bundleData []byte) (err error) {
+	r.transfer.mode, err = chooseBundleTransfer(r.BundleTransfer, len(bundleData), r.BundleImage)
+	if err != nil {
+		return err
+	}
+	switch r.transfer.mode {
+	case BundleTransferImage:
+		r.transfer.image = r.BundleImage
+	case BundleTransferConfigMap:
+		r.transfer.configMaps, err = r.createConfigMaps(ctx, r.configMapName, splitBundleData(bundleData))
+		if err != nil {
+			return fmt.Errorf("error creating ConfigMap %w", err)
+		}
+	case BundleTransferPVC:
+		if err := r.populateBundlePVC(ctx, bundleData); err != nil {
+			return fmt.Errorf("error populating bundle PVC: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyBundleTransfer replaces the bundle ConfigMap volume of pod, which holds
+// a single ConfigMap, with the source of the transfer mode.
+func (s bundleTransferState) applyBundleTransfer(pod *v1.Pod, opts PodOptions) {
+	switch s.mode {
+	case BundleTransferConfigMap:
+		if len(s.configMaps) <= 1 {
+			return
+		}
+		setVolumeSource(pod, bundleVolume, chunksVolumeSource(s.configMaps))
+		pod.Spec.InitContainers[0].Args = untarChunksArgs
+	case BundleTransferImage:
+		puller := &pod.Spec.InitContainers[0]
+		puller.Image = scorecardBundlePullerImage
+		if opts.BundlePullerImage != "" {
+			puller.Image = opts.BundlePullerImage
+		}
+		// The image is passed in the environment so that the shell does not interpret it.
+		puller.Args = []string{"sh", "-c", fmt.Sprintf(`set -o pipefail; crane export "$%s" - | tar xvf - -C /scorecard-bundle`,
+			bundleImageEnvVar)}
+		puller.Env = []v1.EnvVar{{Name: bundleImageEnvVar, Value: s.image}}
+		puller.VolumeMounts = []v1.VolumeMount{{MountPath: "/scorecard-bundle", Name: untarVolume}}
+		removeVolume(pod, bundleVolume)
+		if len(opts.ImagePullSecrets) != 0 {
+			// The pull secret of test images authenticates the bundle image pull.
+			puller.Env = append(puller.Env, v1.EnvVar{Name: "DOCKER_CONFIG", Value: dockerConfigMount})
+			puller.VolumeMounts = append(puller.VolumeMounts, v1.VolumeMount{
+				MountPath: dockerConfigMount, Name: bundleVolume, ReadOnly: true})
+			pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{Name: bundleVolume, VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: opts.ImagePullSecrets[0],
+					Items:      []v1.KeyToPath{{Key: v1.DockerConfigJsonKey, Path: "config.json"}},
+				},
+			}})
+		}
+	case BundleTransferPVC:
+		pod.Spec.InitContainers = nil
+		removeVolume(pod, bundleVolume)
+		setVolumeSource(pod, untarVolume, v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+			ClaimName: s.pvcName,
+			ReadOnly:  true,
+		}})
+		if s.nodeName != "" {
+			pod.Spec.Affinity = nodeAffinity(s.nodeName)
+		}
+	}
+}
+
+// untarChunksArgs unpack the bundle held by the ConfigMaps of a
+// chunksVolumeSource mounted in /scorecard, whether it is split or not.
+var untarChunksArgs = []string{
+	"sh", "-c", fmt.Sprintf("set -o pipefail; cat /scorecard/%s* | tar xvzf - -C /scorecard-bundle", bundleConfigMapKey),
+}
+
+// chunksVolumeSource returns a volume projecting the bundle chunks held by configMaps.
+func chunksVolumeSource(configMaps []string) v1.VolumeSource {
+	sources := make([]v1.VolumeProjection, len(configMaps))
+	for i, name := range configMaps {
+		key := chunkKey(i, len(configMaps))
+		sources[i].ConfigMap = &v1.ConfigMapProjection{
+			LocalObjectReference: v1.LocalObjectReference{Name: name},
+			Items:                []v1.KeyToPath{{Key: key, Path: key}},
+		}
+	}
+	return v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{Sources: sources}}
+}
+
+func setVolumeSource(pod *v1.Pod, name string, source v1.VolumeSource) {
+	for i := range pod.Spec.Volumes {
+		if pod.Spec.Volumes[i].Name == name {
+			pod.Spec.Volumes[i].VolumeSource = source
+		}
+	}
+}
+
+func removeVolume(pod *v1.Pod, name string) {
+	volumes := pod.Spec.Volumes[:0]
+	for _, v := range pod.Spec.Volumes {
+		if v.Name != name {
+			volumes = append(volumes, v)
+		}
+	}
+	pod.Spec.Volumes = volumes
+}
+
+// nodeAffinity returns an affinity requiring the node named nodeName.
+func nodeAffinity(nodeName string) *v1.Affinity {
+	return &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{{
+				MatchFields: []v1.NodeSelectorRequirement{{
+					Key:      "metadata.name",
+					Operator: v1.NodeSelectorOpIn,
+					Values:   []string{nodeName},
+				}},
+			}},
+		},
+	}}
+}
+
+// populateBundlePVC creates a PVC and unpacks bundleData into it, with a pod
+// whose init container unpacks the bundle from ConfigMaps. The ConfigMaps are
+// deleted once the PVC is populated.
+func (r *PodTestRunner) populateBundlePVC(ctx context.Context, bundleData []byte) error {
+	storageClassName, err := r.findDefaultStorageClassName()
+	if err != nil {
+		return err
+	}
+	pvc, err := r.getPVCDefinition(r.configMapName, r.configMapName+"-bundle", bundlePVCSize, storageClassName, "")
+	if err != nil {
+		return err
+	}
+	if storageClassName == "" {
+		// Use the default storage class of the cluster, if any.
+		pvc.Spec.StorageClassName = nil
+	}
+	if _, err := r.Client.CoreV1().PersistentVolumeClaims(r.Namespace).Create(ctx, pvc, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	r.transfer.pvcName = pvc.Name
+
+	populatorName := r.configMapName + "-populate"
+	configMaps, err := r.createConfigMaps(ctx, populatorName, splitBundleData(bundleData))
+	defer func() {
+		for _, name := range configMaps {
+			if err := r.Client.CoreV1().ConfigMaps(r.Namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+				log.Error(err)
+			}
+		}
+	}()
+	if err != nil {
+		return fmt.Errorf("error creating ConfigMap %w", err)
+	}
+
+	pod, err := r.Client.CoreV1().Pods(r.Namespace).Create(ctx, r.getPopulatorPodDefinition(populatorName, configMaps),
+		metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.Client.CoreV1().Pods(r.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil {
+			log.Error(err)
+		}
+	}()
+	if pod, err = r.waitForPodSucceeded(ctx, pod.Name); err != nil {
+		return err
+	}
+	r.transfer.nodeName = pod.Spec.NodeName
+	return nil
+}
+
+// getPopulatorPodDefinition returns a pod named name whose init container
+// unpacks the bundle chunks of configMaps into the bundle PVC.
+func (r PodTestRunner) getPopulatorPodDefinition(name string, configMaps []string) *v1.Pod {
+	bundleMounts := []v1.VolumeMount{
+		{
+			MountPath: "/scorecard",
+			Name:      bundleVolume,
+			ReadOnly:  true,
+		},
+		{
+			MountPath: "/scorecard-bundle",
+			Name:      untarVolume,
+		},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: r.Namespace,
+			Labels: map[string]string{
+				"app":     "scorecard-test",
+				"testrun": r.configMapName,
+			},
+		},
+		Spec: v1.PodSpec{
+			ServiceAccountName: r.ServiceAccount,
+			RestartPolicy:      v1.RestartPolicyNever,
+			InitContainers: []v1.Container{
+				{
+					Name:            populatorContainer,
+					Image:           r.PodOptions.untarImage(),
+					ImagePullPolicy: v1.PullIfNotPresent,
+					Args:            untarChunksArgs,
+					VolumeMounts:    bundleMounts,
+				},
+			},
+			Containers: []v1.Container{
+				{
+					// Flush the unpacked bundle to the volume.
+					Name:            populatorContainer + "-sync",
+					Image:           r.PodOptions.untarImage(),
+					ImagePullPolicy: v1.PullIfNotPresent,
+					Args:            []string{"sync"},
+					VolumeMounts:    bundleMounts[1:],
+				},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name:         bundleVolume,
+					VolumeSource: chunksVolumeSource(configMaps),
+				},
+				{
+					Name: untarVolume,
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: r.transfer.pvcName},
+					},
+				},
+			},
+		},
+	}
+	applyPodOptions(pod, r.PodOptions)
+	// The sync container runs the untar image, like the init container.
+	pod.Spec.Containers[0].SecurityContext = r.PodOptions.initSecurityContext()
+	return pod
+}
+
+// waitForPodSucceeded waits for the pod named name to complete.
+func (r PodTestRunner) waitForPodSucceeded(ctx context.Context, name string) (pod *v1.Pod, err error) {
+	ctx, cancel := context.WithTimeout(ctx, populatorPodTimeout)
+	defer cancel()
+	err = wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		pod, err = r.Client.CoreV1().Pods(r.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if err := podFailure(pod); err != nil {
+			return false, err
+		}
+		if pod.Status.Phase == v1.PodFailed {
+			return false, fmt.Errorf("pod %s failed: %s", name, podTerminationMessage(pod))
+		}
+		return pod.Status.Phase == v1.PodSucceeded, nil
+	}, ctx.Done())
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return nil, fmt.Errorf("pod %s did not complete: %s", name, podPendingProblem(pod))
+	}
+	return pod, err
+}
+
+// podTerminationMessage returns the reasons the containers of pod terminated with.
+func podTerminationMessage(pod *v1.Pod) string {
+	var reasons []string
+	for _, statuses := range [][]v1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if t := status.State.Terminated; t != nil && t.ExitCode != 0 {
+				reasons = append(reasons, fmt.Sprintf("container %s exited with code %d: %s %s",
+					status.Name, t.ExitCode, t.Reason, strings.TrimSpace(t.Message)))
+			}
+		}
+	}
+	if len(reasons) == 0 {
+		return pod.Status.Message
+	}
+	return strings.Join(reasons, "; ")
+}
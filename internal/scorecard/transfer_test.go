@@ -0,0 +1,158 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Bundle transfer", func() {
+	var defaultMaxConfigMapData int
+
+	BeforeEach(func() {
+		defaultMaxConfigMapData = maxConfigMapData
+		maxConfigMapData = 10
+	})
+	AfterEach(func() {
+		maxConfigMapData = defaultMaxConfigMapData
+	})
+
+	Describe("chooseBundleTransfer", func() {
+		It("uses ConfigMaps for bundles that fit in them", func() {
+			Expect(chooseBundleTransfer(BundleTransferAuto, 10*maxConfigMapChunks, "")).To(Equal(BundleTransferConfigMap))
+		})
+		It("pulls large bundle images", func() {
+			Expect(chooseBundleTransfer(BundleTransferAuto, 10*maxConfigMapChunks+1, "quay.io/example/bundle:v0.1.0")).
+				To(Equal(BundleTransferImage))
+		})
+		It("uses a PVC for large bundle directories", func() {
+			Expect(chooseBundleTransfer("", 10*maxConfigMapChunks+1, "")).To(Equal(BundleTransferPVC))
+		})
+		It("keeps explicit modes", func() {
+			Expect(chooseBundleTransfer(BundleTransferPVC, 1, "")).To(Equal(BundleTransferPVC))
+			_, err := chooseBundleTransfer(BundleTransferImage, 1, "")
+			Expect(err).To(MatchError(ContainSubstring("requires a bundle image")))
+		})
+	})
+
+	Describe("splitBundleData", func() {
+		It("splits data in ConfigMap sized chunks", func() {
+			chunks := splitBundleData(bytes.Repeat([]byte("a"), 25))
+			Expect(chunks).To(HaveLen(3))
+			Expect(chunks[2]).To(HaveLen(5))
+			Expect(splitBundleData(nil)).To(HaveLen(1))
+		})
+	})
+
+	Describe("configmap mode", func() {
+		It("splits large bundles across ConfigMaps mounted by test pods", func() {
+			client := fake.NewSimpleClientset()
+			r := &PodTestRunner{
+				Namespace:      "ns",
+				BundlePath:     filepath.Join("testdata", "bundle"),
+				BundleTransfer: BundleTransferConfigMap,
+				Client:         client,
+			}
+			Expect(r.Initialize(context.TODO())).To(Succeed())
+			defer r.watcher.Stop()
+
+			configMaps, err := client.CoreV1().ConfigMaps("ns").List(context.TODO(), metav1.ListOptions{
+				LabelSelector: "testrun=" + r.configMapName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(configMaps.Items)).To(BeNumerically(">", 1))
+			Expect(r.transfer.configMaps[0]).To(Equal(r.configMapName))
+			Expect(configMaps.Items[0].BinaryData).To(HaveKey("bundle.tar.gz.000"))
+
+			pod := getPodDefinition(r.configMapName, v1alpha3.TestConfiguration{Image: "img"}, *r)
+			sources := pod.Spec.Volumes[0].Projected.Sources
+			Expect(sources).To(HaveLen(len(configMaps.Items)))
+			Expect(sources[1].ConfigMap.Name).To(Equal(r.configMapName + "-1"))
+			Expect(pod.Spec.InitContainers[0].Args[2]).To(ContainSubstring("cat /scorecard/bundle.tar.gz*"))
+		})
+		It("keeps a single ConfigMap for small bundles", func() {
+			pod := getPodDefinition("cm", v1alpha3.TestConfiguration{Image: "img"}, PodTestRunner{
+				transfer: bundleTransferState{mode: BundleTransferConfigMap, configMaps: []string{"cm"}},
+			})
+			Expect(pod.Spec.Volumes[0].ConfigMap.Name).To(Equal("cm"))
+			Expect(pod.Spec.InitContainers[0].Args).To(ContainElement("/scorecard/bundle.tar.gz"))
+		})
+	})
+
+	Describe("image mode", func() {
+		It("pulls the bundle image in an init container", func() {
+			r := PodTestRunner{
+				PodOptions: PodOptions{ImagePullSecrets: []string{"registry"}},
+				transfer:   bundleTransferState{mode: BundleTransferImage, image: "quay.io/example/bundle:v0.1.0"},
+			}
+			pod := getPodDefinition("cm", v1alpha3.TestConfiguration{Image: "img"}, r)
+			puller := pod.Spec.InitContainers[0]
+			Expect(puller.Image).To(Equal(scorecardBundlePullerImage))
+			Expect(puller.Env).To(ContainElement(v1.EnvVar{Name: bundleImageEnvVar, Value: "quay.io/example/bundle:v0.1.0"}))
+			Expect(puller.Args[2]).NotTo(ContainSubstring("quay.io"))
+			Expect(pod.Spec.Volumes).To(HaveLen(2))
+			Expect(pod.Spec.Volumes[1].Secret.SecretName).To(Equal("registry"))
+		})
+	})
+
+	Describe("pvc mode", func() {
+		It("mounts the bundle PVC on its node without an init container", func() {
+			r := PodTestRunner{
+				transfer: bundleTransferState{mode: BundleTransferPVC, pvcName: "bundle", nodeName: "node-1"},
+			}
+			pod := getPodDefinition("cm", v1alpha3.TestConfiguration{Image: "img"}, r)
+			Expect(pod.Spec.InitContainers).To(BeEmpty())
+			Expect(pod.Spec.Volumes).To(HaveLen(1))
+			Expect(pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("bundle"))
+			Expect(pod.Spec.Volumes[0].PersistentVolumeClaim.ReadOnly).To(BeTrue())
+			term := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0]
+			Expect(term.MatchFields[0].Values).To(Equal([]string{"node-1"}))
+		})
+		It("populates the PVC from ConfigMaps in an init container", func() {
+			r := PodTestRunner{
+				Namespace:     "ns",
+				PodOptions:    RestrictedPodOptions(),
+				configMapName: "cm",
+				transfer:      bundleTransferState{mode: BundleTransferPVC, pvcName: "cm-bundle"},
+			}
+			pod := r.getPopulatorPodDefinition("cm-populate", []string{"cm-populate", "cm-populate-1"})
+			Expect(pod.Spec.InitContainers).To(HaveLen(1))
+			Expect(pod.Spec.InitContainers[0].Args).To(Equal(untarChunksArgs))
+			Expect(*pod.Spec.InitContainers[0].SecurityContext.RunAsUser).To(Equal(restrictedUser))
+			Expect(*pod.Spec.SecurityContext.FSGroup).To(Equal(restrictedUser))
+			Expect(pod.Spec.Volumes[0].Projected.Sources).To(HaveLen(2))
+			Expect(pod.Spec.Volumes[0].Projected.Sources[1].ConfigMap.Items[0].Key).To(Equal("bundle.tar.gz.001"))
+			Expect(pod.Spec.Volumes[1].PersistentVolumeClaim.ClaimName).To(Equal("cm-bundle"))
+		})
+		It("sizes the bundle PVC", func() {
+			pvc, err := PodTestRunner{}.getPVCDefinition("cm", "cm-bundle", bundlePVCSize, "", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal(bundlePVCSize))
+
+			pvc, err = PodTestRunner{}.getPVCDefinition("cm", "cm-bundle", "", "", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal(STORAGE_SIZE_DEFAULT))
+		})
+	})
+})
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 
 	"github.com/operator-framework/operator-registry/pkg/registry"
 	log "github.com/sirupsen/logrus"
@@ -173,6 +174,14 @@ func GVKsForV1beta1CustomResourceDefinitions(crds ...apiextv1beta1.CustomResourc
 	return gvks
 }
 
+// CRDGroup returns the group of a CRD from its name, "<plural>.<group>".
+func CRDGroup(name string) string {
+	if i := strings.Index(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
+
 type CRDVersions []apiextv1beta1.CustomResourceDefinitionVersion
 
 func (vs CRDVersions) Len() int { return len(vs) }
@@ -72,3 +72,19 @@ func crdVersionsToStrings(cvs []apiextv1beta1.CustomResourceDefinitionVersion) (
 	}
 	return vs
 }
+
+func TestCRDGroup(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{"memcacheds.cache.example.com", "cache.example.com"},
+		{"memcacheds", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if group := CRDGroup(c.name); group != c.expected {
+			t.Errorf("CRDGroup(%q) = %q, expected %q", c.name, group, c.expected)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ParseToleration parses a toleration in the format of taints, key[=value][:effect].
+// A toleration without a value tolerates any value of key, without an effect
+// all effects, and with an empty key all taints.
+func ParseToleration(s string) (corev1.Toleration, error) {
+	t := corev1.Toleration{Operator: corev1.TolerationOpExists}
+	keyValue := s
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		keyValue = s[:i]
+		t.Effect = corev1.TaintEffect(s[i+1:])
+		switch t.Effect {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			return t, fmt.Errorf("invalid toleration %q: unknown effect %q", s, t.Effect)
+		}
+	}
+	if i := strings.Index(keyValue, "="); i >= 0 {
+		t.Key, t.Value = keyValue[:i], keyValue[i+1:]
+		t.Operator = corev1.TolerationOpEqual
+		if t.Key == "" {
+			return t, fmt.Errorf("invalid toleration %q: a value requires a key", s)
+		}
+	} else {
+		t.Key = keyValue
+	}
+	return t, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseToleration(t *testing.T) {
+	cases := []struct {
+		input   string
+		wanted  corev1.Toleration
+		wantErr string
+	}{
+		{"dedicated=ci:NoSchedule", corev1.Toleration{Key: "dedicated", Value: "ci",
+			Operator: corev1.TolerationOpEqual, Effect: corev1.TaintEffectNoSchedule}, ""},
+		{"dedicated", corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists}, ""},
+		{":NoExecute", corev1.Toleration{Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute}, ""},
+		{"dedicated:Sometimes", corev1.Toleration{}, `unknown effect "Sometimes"`},
+		{"=ci", corev1.Toleration{}, "a value requires a key"},
+	}
+	for _, c := range cases {
+		got, err := ParseToleration(c.input)
+		if c.wantErr != "" {
+			if assert.Error(t, err, c.input) {
+				assert.Contains(t, err.Error(), c.wantErr)
+			}
+			continue
+		}
+		assert.NoError(t, err, c.input)
+		assert.Equal(t, c.wanted, got, c.input)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ansibleoperator is the entrypoint of the ansible-operator binary. It
+// allows building a custom ansible-operator binary that runs Go hooks before
+// and after each Ansible run:
+//
+//	func main() {
+//		ansibleoperator.RegisterPreRunHook(gvk, ansibleoperator.PreRunHookFunc(
+//			func(ctx context.Context, c client.Client, u *unstructured.Unstructured) (ansibleoperator.PreRunResult, error) {
+//				return ansibleoperator.PreRunResult{ExtraVars: map[string]interface{}{"foo": "bar"}}, nil
+//			}))
+//		ansibleoperator.Main()
+//	}
+package ansibleoperator
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	"github.com/operator-framework/operator-sdk/internal/ansible/controller"
+	"github.com/operator-framework/operator-sdk/internal/ansible/runner/eventapi"
+	"github.com/operator-framework/operator-sdk/internal/cmd/ansible-operator/run"
+	"github.com/operator-framework/operator-sdk/internal/cmd/ansible-operator/version"
+)
+
+type (
+	// PreRunHook is run before ansible-runner for a CR. It can add extra vars or skip the run.
+	PreRunHook = controller.PreRunHook
+	// PreRunHookFunc adapts a function to a PreRunHook.
+	PreRunHookFunc = controller.PreRunHookFunc
+	// PreRunResult is returned by a PreRunHook.
+	PreRunResult = controller.PreRunResult
+	// PostRunHook is run after every completed ansible-runner run for a CR, with its result.
+	PostRunHook = controller.PostRunHook
+	// PostRunHookFunc adapts a function to a PostRunHook.
+	PostRunHookFunc = controller.PostRunHookFunc
+	// RunResult holds the final playbook stats of an Ansible run and whether it was successful.
+	RunResult = controller.RunResult
+	// StatusJobEvent holds the playbook stats of an Ansible run.
+	StatusJobEvent = eventapi.StatusJobEvent
+)
+
+// RegisterPreRunHook registers a hook run before ansible-runner for CRs of gvk,
+// or of every GVK if gvk is empty. It must be called before Main.
+func RegisterPreRunHook(gvk schema.GroupVersionKind, hook PreRunHook) {
+	controller.RegisterPreRunHook(gvk, hook)
+}
+
+// RegisterPostRunHook registers a hook run after ansible-runner for CRs of gvk,
+// or of every GVK if gvk is empty. It must be called before Main.
+func RegisterPostRunHook(gvk schema.GroupVersionKind, hook PostRunHook) {
+	controller.RegisterPostRunHook(gvk, hook)
+}
+
+// Main runs the ansible-operator command line.
+func Main() {
+	root := cobra.Command{
+		Short: "Reconcile an Ansible operator project using ansible-runner",
+		Long: `This binary runs an Ansible operator that reconciles Kubernetes resources
+managed by the ansible-runner program. It can be run either directly or from an Ansible
+operator project's image entrypoint
+`,
+		Use: "ansible-operator",
+	}
+
+	root.AddCommand(run.NewCmd())
+	root.AddCommand(version.NewCmd())
+
+	if err := root.Execute(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecardtest
+
+import (
+	"context"
+	"time"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/scorecard/tests"
+)
+
+// HarnessNamespace is the namespace tests run in with a Harness.
+const HarnessNamespace = "scorecard"
+
+// Harness runs tests locally against a bundle directory and a fake client, to
+// unit test them. The fake client knows the kinds of client-go and the kinds
+// of the CRDs of the bundle, as unstructured objects.
+type Harness struct {
+	// Registry holds the tests of the harness, DefaultRegistry by default.
+	Registry *Registry
+	// Env is what tests run against. Its client may be replaced, or used to
+	// set the status of objects while tests wait for them.
+	Env Env
+}
+
+// NewHarness returns a Harness for the bundle in bundleRoot, whose client
+// holds objs.
+func NewHarness(bundleRoot string, objs ...runtime.Object) (*Harness, error) {
+	bundle, err := LoadBundle(bundleRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	crdMapper := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range crdKinds(bundle) {
+		scheme.AddKnownTypeWithName(gvk.GroupVersionKind, &unstructured.Unstructured{})
+		listGVK := gvk.GroupVersionKind
+		listGVK.Kind += "List"
+		scheme.AddKnownTypeWithName(listGVK, &unstructured.UnstructuredList{})
+		crdMapper.Add(gvk.GroupVersionKind, gvk.scope)
+	}
+	mapper := meta.MultiRESTMapper{crdMapper, testrestmapper.TestOnlyStaticRESTMapper(scheme)}
+
+	c := tests.WithRESTMapper(fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(), mapper)
+	return &Harness{
+		Registry: DefaultRegistry,
+		Env: Env{
+			Bundle:       bundle,
+			BundleRoot:   bundleRoot,
+			Client:       client.NewNamespacedClient(c, HarnessNamespace),
+			Namespace:    HarnessNamespace,
+			PollInterval: 10 * time.Millisecond,
+		},
+	}, nil
+}
+
+// Run runs the test named name, and returns its result as WriteStatus writes it.
+func (h *Harness) Run(ctx context.Context, name string) scapiv1alpha3.TestStatus {
+	return normalizeStatus(h.Registry.Run(ctx, name, h.Env))
+}
+
+type crdKind struct {
+	schema.GroupVersionKind
+	scope meta.RESTScope
+}
+
+// crdKinds returns the kinds served by the CRDs of bundle.
+func crdKinds(bundle *apimanifests.Bundle) []crdKind {
+	scope := func(s string) meta.RESTScope {
+		if s == string(apiextv1.ClusterScoped) {
+			return meta.RESTScopeRoot
+		}
+		return meta.RESTScopeNamespace
+	}
+	var kinds []crdKind
+	for _, crd := range bundle.V1CRDs {
+		for _, v := range crd.Spec.Versions {
+			kinds = append(kinds, crdKind{
+				GroupVersionKind: schema.GroupVersionKind{Group: crd.Spec.Group, Version: v.Name, Kind: crd.Spec.Names.Kind},
+				scope:            scope(string(crd.Spec.Scope)),
+			})
+		}
+	}
+	for _, crd := range bundle.V1beta1CRDs {
+		versions := []string{crd.Spec.Version}
+		if len(crd.Spec.Versions) != 0 {
+			versions = versions[:0]
+			for _, v := range crd.Spec.Versions {
+				versions = append(versions, v.Name)
+			}
+		}
+		for _, v := range versions {
+			kinds = append(kinds, crdKind{
+				GroupVersionKind: schema.GroupVersionKind{Group: crd.Spec.Group, Version: v, Kind: crd.Spec.Names.Kind},
+				scope:            scope(string(crd.Spec.Scope)),
+			})
+		}
+	}
+	return kinds
+}
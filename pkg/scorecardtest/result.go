@@ -0,0 +1,71 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecardtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+// WriteStatus writes status to w as scorecard reads it from the logs of test
+// pods. A status without results is written as an errored result, and results
+// with an unknown state are written as errored.
+func WriteStatus(w io.Writer, status scapiv1alpha3.TestStatus) error {
+	status = normalizeStatus(status)
+	b, err := json.MarshalIndent(status, "", "    ")
+	if err != nil {
+		return fmt.Errorf("error encoding test result: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", b); err != nil {
+		return fmt.Errorf("error writing test result: %w", err)
+	}
+	return nil
+}
+
+func normalizeStatus(status scapiv1alpha3.TestStatus) scapiv1alpha3.TestStatus {
+	if len(status.Results) == 0 {
+		return errorStatus("", "the test returned no results")
+	}
+	results := make([]scapiv1alpha3.TestResult, len(status.Results))
+	for i, r := range status.Results {
+		if r.Errors == nil {
+			r.Errors = []string{}
+		}
+		if r.Suggestions == nil {
+			r.Suggestions = []string{}
+		}
+		switch r.State {
+		case scapiv1alpha3.PassState, scapiv1alpha3.FailState, scapiv1alpha3.ErrorState:
+		default:
+			r.Errors = append(r.Errors, fmt.Sprintf("invalid test state %q", r.State))
+			r.State = scapiv1alpha3.ErrorState
+		}
+		results[i] = r
+	}
+	return scapiv1alpha3.TestStatus{Results: results}
+}
+
+// errorStatus returns the status of a test named name that could not run.
+func errorStatus(name, msg string) scapiv1alpha3.TestStatus {
+	return scapiv1alpha3.TestStatus{Results: []scapiv1alpha3.TestResult{{
+		Name:        name,
+		State:       scapiv1alpha3.ErrorState,
+		Errors:      []string{msg},
+		Suggestions: []string{},
+	}}}
+}
@@ -0,0 +1,252 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scorecardtest is a framework for writing the binaries of custom
+// scorecard test images. Tests are registered by name, and Main runs the test
+// named by the first argument of the binary against the bundle mounted in the
+// test pod, printing its result as scorecard expects it:
+//
+//	func main() {
+//		scorecardtest.Register("has-examples", func(t *scorecardtest.T) {
+//			if len(t.CRs()) == 0 {
+//				t.Errorf("the CSV has no alm-examples")
+//			}
+//		})
+//		scorecardtest.Main()
+//	}
+//
+// Anything tests write to os.Stdout, os.Stderr, or with the standard or logrus
+// loggers, is added to the log of their result, since the logs of the test pod
+// must only hold the result. Tests can
+// be run locally, against a fake client, with a Harness.
+package scorecardtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"github.com/operator-framework/operator-sdk/internal/scorecard"
+)
+
+const (
+	// PodBundleRoot is the directory holding the bundle under test in test pods.
+	PodBundleRoot = scorecard.PodBundleRoot
+	// NamespaceEnvVar is the environment variable holding the namespace test pods run in.
+	NamespaceEnvVar = "SCORECARD_NAMESPACE"
+
+	// DefaultPollInterval is the interval at which T.WaitFor checks objects.
+	DefaultPollInterval = 2 * time.Second
+)
+
+// TestFunc is a custom scorecard test. It reports failures with the Errorf and
+// Fatalf methods of t, and passes if it reports none.
+type TestFunc func(t *T)
+
+// Registry holds tests by name.
+type Registry struct {
+	mu    sync.RWMutex
+	tests map[string]TestFunc
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{tests: map[string]TestFunc{}}
+}
+
+// DefaultRegistry is the registry of Register and Main.
+var DefaultRegistry = NewRegistry()
+
+// Register registers fn as the test named name in DefaultRegistry.
+func Register(name string, fn TestFunc) {
+	DefaultRegistry.Register(name, fn)
+}
+
+// Main runs the test of DefaultRegistry named by the first argument of the binary.
+func Main() {
+	DefaultRegistry.Main()
+}
+
+// Register registers fn as the test named name. It panics if name is empty or
+// already registered.
+func (r *Registry) Register(name string, fn TestFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if name == "" {
+		panic("scorecardtest: test name must not be empty")
+	}
+	if _, ok := r.tests[name]; ok {
+		panic(fmt.Sprintf("scorecardtest: test %q is already registered", name))
+	}
+	r.tests[name] = fn
+}
+
+// Names returns the names of the registered tests, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.tests))
+	for name := range r.tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Env is what tests run against.
+type Env struct {
+	// Bundle is the bundle under test, read from BundleRoot.
+	Bundle     *apimanifests.Bundle
+	BundleRoot string
+	// Client is a client of the cluster, which sets Namespace on namespaced
+	// objects and refuses to access other namespaces.
+	Client    client.Client
+	Namespace string
+	// ClientError is the reason Client is nil, reported to tests using it.
+	ClientError error
+	// PollInterval is the interval at which T.WaitFor checks objects.
+	PollInterval time.Duration
+}
+
+// Run runs the test named name against env, and returns its result.
+func (r *Registry) Run(ctx context.Context, name string, env Env) scapiv1alpha3.TestStatus {
+	r.mu.RLock()
+	fn, ok := r.tests[name]
+	r.mu.RUnlock()
+	if !ok {
+		return errorStatus(name, fmt.Sprintf("unknown test %q, valid tests are: %v", name, r.Names()))
+	}
+	t := newT(ctx, name, env)
+	t.run(fn)
+	return t.status()
+}
+
+// Main runs the test named by the first argument of the binary against the
+// bundle and namespace of the test pod, and prints its result to stdout. Setup
+// errors are reported as an errored result, so that the output is always valid.
+func (r *Registry) Main() {
+	if len(os.Args) < 2 {
+		writeStatusOrExit(os.Stdout, errorStatus("", fmt.Sprintf("a test name argument is required, "+
+			"valid tests are: %v", r.Names())))
+		return
+	}
+	name := os.Args[1]
+
+	env, err := PodEnv()
+	if err != nil {
+		writeStatusOrExit(os.Stdout, errorStatus(name, err.Error()))
+		return
+	}
+
+	// Whatever the test prints must not be mixed with the result.
+	stdout := os.Stdout
+	output, restore, err := captureOutput()
+	if err != nil {
+		writeStatusOrExit(stdout, errorStatus(name, err.Error()))
+		return
+	}
+	status := r.Run(context.Background(), name, env)
+	restore()
+	if captured := <-output; captured != "" && len(status.Results) > 0 {
+		status.Results[0].Log = captured + status.Results[0].Log
+	}
+	writeStatusOrExit(stdout, status)
+}
+
+// PodEnv returns the Env of a test pod: the bundle in PodBundleRoot and a
+// client of the namespace in NamespaceEnvVar. A client error is kept in the
+// Env, since only some tests need a client.
+func PodEnv() (Env, error) {
+	env := Env{BundleRoot: PodBundleRoot, PollInterval: DefaultPollInterval}
+	var err error
+	if env.Bundle, err = LoadBundle(PodBundleRoot); err != nil {
+		return env, err
+	}
+	env.Client, env.Namespace, env.ClientError = NewClient()
+	return env, nil
+}
+
+// LoadBundle reads the bundle in root.
+func LoadBundle(root string) (*apimanifests.Bundle, error) {
+	bundle, err := apimanifests.GetBundleFromDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("error reading bundle %s: %w", root, err)
+	}
+	return bundle, nil
+}
+
+// NewClient returns a client of the cluster the test pod runs in, scoped to
+// the namespace in NamespaceEnvVar, and that namespace.
+func NewClient() (client.Client, string, error) {
+	namespace := os.Getenv(NamespaceEnvVar)
+	if namespace == "" {
+		return nil, "", fmt.Errorf("%s is not set", NamespaceEnvVar)
+	}
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, namespace, fmt.Errorf("error getting cluster config: %w", err)
+	}
+	c, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return nil, namespace, fmt.Errorf("error creating client: %w", err)
+	}
+	return client.NewNamespacedClient(c, namespace), namespace, nil
+}
+
+// captureOutput redirects stdout and stderr to a pipe until restore is called,
+// then sends what was written on output.
+func captureOutput() (output <-chan string, restore func(), err error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error capturing test output: %w", err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = writer, writer
+	// The standard and logrus loggers keep the original stderr.
+	log.SetOutput(writer)
+	logrusOutput := logrus.StandardLogger().Out
+	logrus.SetOutput(writer)
+
+	ch := make(chan string, 1)
+	go func() {
+		b, _ := ioutil.ReadAll(reader)
+		reader.Close()
+		ch <- string(b)
+	}()
+	return ch, func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		log.SetOutput(stderr)
+		logrus.SetOutput(logrusOutput)
+		writer.Close()
+	}, nil
+}
+
+func writeStatusOrExit(w io.Writer, status scapiv1alpha3.TestStatus) {
+	if err := WriteStatus(w, status); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecardtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const testBundle = "../../internal/scorecard/testdata/bundle"
+
+func newTestHarness(t *testing.T) *Harness {
+	h, err := NewHarness(testBundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Registry = NewRegistry()
+	return h
+}
+
+func runOne(t *testing.T, h *Harness, name string) scapiv1alpha3.TestResult {
+	status := h.Run(context.Background(), name)
+	if len(status.Results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(status.Results))
+	}
+	return status.Results[0]
+}
+
+func TestRegistry(t *testing.T) {
+	h := newTestHarness(t)
+	h.Registry.Register("pass", func(t *T) { t.Logf("ok") })
+	h.Registry.Register("fail", func(t *T) {
+		t.Errorf("first")
+		t.Suggestf("try again")
+		t.Fatalf("second")
+		t.Errorf("unreachable")
+	})
+	h.Registry.Register("panic", func(t *T) { panic("boom") })
+
+	if names := h.Registry.Names(); strings.Join(names, ",") != "fail,panic,pass" {
+		t.Errorf("unexpected names %v", names)
+	}
+
+	r := runOne(t, h, "pass")
+	if r.Name != "pass" || r.State != scapiv1alpha3.PassState || r.Log != "ok\n" {
+		t.Errorf("unexpected result %+v", r)
+	}
+	r = runOne(t, h, "fail")
+	if r.State != scapiv1alpha3.FailState || strings.Join(r.Errors, ",") != "first,second" ||
+		len(r.Suggestions) != 1 {
+		t.Errorf("unexpected result %+v", r)
+	}
+	r = runOne(t, h, "panic")
+	if r.State != scapiv1alpha3.ErrorState || r.Errors[0] != "test panicked: boom" {
+		t.Errorf("unexpected result %+v", r)
+	}
+	r = runOne(t, h, "missing")
+	if r.State != scapiv1alpha3.ErrorState || !strings.Contains(r.Errors[0], "valid tests are: [fail panic pass]") {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a duplicate test to panic")
+		}
+	}()
+	h.Registry.Register("pass", func(t *T) {})
+}
+
+func TestCreateCRAndWait(t *testing.T) {
+	h := newTestHarness(t)
+	h.Registry.Register("reconcile", func(t *T) {
+		crs := t.CRs()
+		if len(crs) != 1 {
+			t.Fatalf("expected 1 CR, got %d", len(crs))
+		}
+		cr := &crs[0]
+		t.CreateCR(cr)
+		if cr.GetNamespace() != HarnessNamespace {
+			t.Errorf("expected namespace %s, got %q", HarnessNamespace, cr.GetNamespace())
+		}
+
+		// Play the operator.
+		ready := cr.DeepCopy()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = unstructured.SetNestedSlice(ready.Object, []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			}, "status", "conditions")
+			_ = t.Client().Update(context.Background(), ready)
+		}()
+		t.WaitForCondition(cr, "Ready", metav1.ConditionTrue, 5*time.Second)
+		t.WaitForCondition(cr, "Degraded", metav1.ConditionTrue, 50*time.Millisecond)
+	})
+
+	r := runOne(t, h, "reconcile")
+	if r.State != scapiv1alpha3.FailState || len(r.Errors) != 1 || !strings.HasPrefix(r.Errors[0], "timed out") {
+		t.Errorf("unexpected result %+v", r)
+	}
+	if !strings.Contains(r.Log, "created Memcached scorecard/example-memcached") {
+		t.Errorf("unexpected log %q", r.Log)
+	}
+
+	// The CR is deleted when the test returns.
+	cr := &unstructured.Unstructured{}
+	cr.SetAPIVersion("cache.example.com/v1alpha1")
+	cr.SetKind("Memcached")
+	err := h.Env.Client.Get(context.Background(), client.ObjectKey{Name: "example-memcached"}, cr)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the CR to be deleted, got %v", err)
+	}
+}
+
+func TestClientError(t *testing.T) {
+	h := newTestHarness(t)
+	h.Env.Client = nil
+	h.Env.ClientError = errNoCluster
+	h.Registry.Register("needs-client", func(t *T) { t.Client() })
+
+	r := runOne(t, h, "needs-client")
+	if r.State != scapiv1alpha3.FailState || r.Errors[0] != "error creating client: no cluster" {
+		t.Errorf("unexpected result %+v", r)
+	}
+}
+
+type clientError string
+
+func (e clientError) Error() string { return string(e) }
+
+const errNoCluster = clientError("no cluster")
+
+func TestWriteStatus(t *testing.T) {
+	cases := []struct {
+		name   string
+		status scapiv1alpha3.TestStatus
+		state  scapiv1alpha3.State
+		errors []string
+	}{
+		{
+			name:   "no results",
+			state:  scapiv1alpha3.ErrorState,
+			errors: []string{"the test returned no results"},
+		},
+		{
+			name:   "invalid state",
+			status: scapiv1alpha3.TestStatus{Results: []scapiv1alpha3.TestResult{{Name: "a", State: "done"}}},
+			state:  scapiv1alpha3.ErrorState,
+			errors: []string{`invalid test state "done"`},
+		},
+		{
+			name:   "nil lists",
+			status: scapiv1alpha3.TestStatus{Results: []scapiv1alpha3.TestResult{{Name: "a", State: scapiv1alpha3.PassState}}},
+			state:  scapiv1alpha3.PassState,
+			errors: nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := WriteStatus(buf, c.status); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(buf.String(), "}\n") {
+				t.Errorf("expected a trailing newline in %q", buf.String())
+			}
+			status := scapiv1alpha3.TestStatus{}
+			if err := json.Unmarshal(buf.Bytes(), &status); err != nil {
+				t.Fatal(err)
+			}
+			r := status.Results[0]
+			if r.State != c.state || strings.Join(r.Errors, ",") != strings.Join(c.errors, ",") {
+				t.Errorf("unexpected result %+v", r)
+			}
+		})
+	}
+}
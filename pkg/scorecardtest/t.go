@@ -0,0 +1,290 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecardtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-sdk/internal/scorecard/tests"
+)
+
+// T is the state of a running test, passed to its TestFunc.
+type T struct {
+	ctx  context.Context
+	name string
+	env  Env
+
+	mu       sync.Mutex
+	result   scapiv1alpha3.TestResult
+	log      strings.Builder
+	cleanups []func()
+}
+
+func newT(ctx context.Context, name string, env Env) *T {
+	if env.PollInterval <= 0 {
+		env.PollInterval = DefaultPollInterval
+	}
+	return &T{
+		ctx:  ctx,
+		name: name,
+		env:  env,
+		result: scapiv1alpha3.TestResult{
+			Name:        name,
+			State:       scapiv1alpha3.PassState,
+			Errors:      []string{},
+			Suggestions: []string{},
+		},
+	}
+}
+
+// Name returns the name of the test.
+func (t *T) Name() string {
+	return t.name
+}
+
+// Context returns the context of the test, which is done when the test must stop.
+func (t *T) Context() context.Context {
+	return t.ctx
+}
+
+// Bundle returns the bundle under test.
+func (t *T) Bundle() *apimanifests.Bundle {
+	return t.env.Bundle
+}
+
+// BundleRoot returns the directory holding the bundle under test.
+func (t *T) BundleRoot() string {
+	return t.env.BundleRoot
+}
+
+// Namespace returns the namespace the test runs in.
+func (t *T) Namespace() string {
+	return t.env.Namespace
+}
+
+// Client returns a client of the namespace the test runs in. The test stops
+// with an error if there is no client.
+func (t *T) Client() client.Client {
+	if t.env.Client == nil {
+		reason := "no client is configured"
+		if t.env.ClientError != nil {
+			reason = t.env.ClientError.Error()
+		}
+		t.Fatalf("error creating client: %s", reason)
+	}
+	return t.env.Client
+}
+
+// Logf adds a line to the log of the result.
+func (t *T) Logf(format string, args ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.log.WriteString(strings.TrimSuffix(fmt.Sprintf(format, args...), "\n") + "\n")
+}
+
+// Errorf adds an error to the result, which makes the test fail.
+func (t *T) Errorf(format string, args ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.result.Errors = append(t.result.Errors, fmt.Sprintf(format, args...))
+	if t.result.State == scapiv1alpha3.PassState {
+		t.result.State = scapiv1alpha3.FailState
+	}
+}
+
+// Fatalf adds an error to the result and stops the test. It must be called from
+// the goroutine running the test.
+func (t *T) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// Suggestf adds a suggestion to the result, which does not make the test fail.
+func (t *T) Suggestf(format string, args ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.result.Suggestions = append(t.result.Suggestions, fmt.Sprintf(format, args...))
+}
+
+// Failed returns true if the test reported an error.
+func (t *T) Failed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.result.State != scapiv1alpha3.PassState
+}
+
+// Cleanup registers fn to be called when the test returns or stops. Functions
+// are called in the reverse order they were registered in.
+func (t *T) Cleanup(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cleanups = append(t.cleanups, fn)
+}
+
+// CRs returns the CRs of the alm-examples annotation of the CSV. The test stops
+// with an error if they cannot be parsed.
+func (t *T) CRs() []unstructured.Unstructured {
+	if t.env.Bundle == nil || t.env.Bundle.CSV == nil {
+		t.Fatalf("the bundle has no CSV")
+	}
+	crs, err := tests.GetCRs(t.env.Bundle)
+	if err != nil {
+		t.Fatalf("error parsing alm-examples: %v", err)
+	}
+	return crs
+}
+
+// CreateCR creates cr in the namespace of the test, unless its kind is
+// cluster-scoped, and deletes it when the test returns. The test stops with
+// an error if cr cannot be created.
+func (t *T) CreateCR(cr *unstructured.Unstructured) {
+	c := t.Client()
+	cr.SetResourceVersion("")
+	cr.SetUID("")
+	if err := c.Create(t.ctx, cr); err != nil {
+		t.Fatalf("error creating %s: %v", objectString(cr), err)
+	}
+	t.Logf("created %s", objectString(cr))
+	t.Cleanup(func() {
+		// The test context may be done, but the CR must still be deleted.
+		err := c.Delete(context.Background(), cr, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Errorf("error deleting %s: %v", objectString(cr), err)
+		}
+	})
+}
+
+// WaitFor gets obj until cond returns true, and stops the test with an error
+// if it does not before timeout. obj holds the last state that was read.
+func (t *T) WaitFor(obj client.Object, timeout time.Duration, cond func() (bool, error)) {
+	c := t.Client()
+	ctx, cancel := context.WithTimeout(t.ctx, timeout)
+	defer cancel()
+	key := client.ObjectKeyFromObject(obj)
+	err := wait.PollImmediateUntil(t.env.PollInterval, func() (bool, error) {
+		if err := c.Get(ctx, key, obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return cond()
+	}, ctx.Done())
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		t.Fatalf("timed out after %s waiting for %s", timeout, objectString(obj))
+	}
+	if err != nil {
+		t.Fatalf("error waiting for %s: %v", objectString(obj), err)
+	}
+}
+
+// WaitForCondition waits until the condition of type condType in the status
+// of obj has status, and stops the test with an error if it does not before timeout.
+func (t *T) WaitForCondition(obj *unstructured.Unstructured, condType string, status metav1.ConditionStatus,
+	timeout time.Duration) {
+	t.WaitFor(obj, timeout, func() (bool, error) {
+		conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		if err != nil {
+			return false, nil
+		}
+		for _, c := range conditions {
+			cond, ok := c.(map[string]interface{})
+			if ok && cond["type"] == condType && cond["status"] == string(status) {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// run runs fn in its own goroutine, so that Fatalf can stop it, then runs the
+// cleanup functions. A panic is reported as an errored result.
+func (t *T) run(fn TestFunc) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer t.runCleanups()
+		defer func() {
+			if r := recover(); r != nil {
+				t.mu.Lock()
+				t.result.State = scapiv1alpha3.ErrorState
+				t.result.Errors = append(t.result.Errors, fmt.Sprintf("test panicked: %v", r))
+				t.mu.Unlock()
+			}
+		}()
+		fn(t)
+	}()
+	<-done
+}
+
+func (t *T) runCleanups() {
+	for {
+		t.mu.Lock()
+		if len(t.cleanups) == 0 {
+			t.mu.Unlock()
+			return
+		}
+		fn := t.cleanups[len(t.cleanups)-1]
+		t.cleanups = t.cleanups[:len(t.cleanups)-1]
+		t.mu.Unlock()
+
+		// A failing cleanup function must not prevent the others from running.
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("cleanup panicked: %v", r)
+				}
+			}()
+			fn()
+		}()
+		<-done
+	}
+}
+
+// status returns the result of the test.
+func (t *T) status() scapiv1alpha3.TestStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := t.result
+	r.Log = t.log.String()
+	return scapiv1alpha3.TestStatus{Results: []scapiv1alpha3.TestResult{r}}
+}
+
+func objectString(obj client.Object) string {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		kind = fmt.Sprintf("%T", obj)
+	}
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", kind, obj.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", kind, obj.GetNamespace(), obj.GetName())
+}